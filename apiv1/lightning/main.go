@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/util/mailbox"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/generated/proto/walletunlocker_pb"
 	"github.com/pkt-cash/pktd/lnd/chanbackup"
@@ -83,5 +84,6 @@ func Register(
 		Launch the Lightning daemon, requires unlocking the wallet indefinitely.
 		`,
 		r.start,
+		help_pb.F_PERM_ADMIN,
 	)
 }
@@ -17,7 +17,7 @@ func Register(
 	w *wallet.Wallet,
 	neutrinoCS *neutrino.ChainService,
 	startLightning *mailbox.Mailbox[*lightning.StartLightning],
-) {
+) *api_neutrino.Neutrino {
 	api_wallet.Register(
 		apiv1.DefineCategory(a, "wallet", "APIs for management of on-chain (non-Lightning) payments"),
 		w,
@@ -34,10 +34,11 @@ func Register(
 			"Stateless utility functions which do not affect, not query, the node in any way"),
 		w.ChainParams(),
 	)
-	api_neutrino.Register(
+	n := api_neutrino.Register(
 		apiv1.DefineCategory(a, "neutrino",
 			"The Neutrino interface which is used to communicate with the p2p nodes in the network"),
 		w,
+		neutrinoCS,
 	)
 	meta.Register(
 		apiv1.DefineCategory(a, "meta",
@@ -45,4 +46,5 @@ func Register(
 		neutrinoCS,
 		w,
 	)
+	return n
 }
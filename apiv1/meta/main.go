@@ -2,13 +2,13 @@ package meta
 
 import (
 	"os"
-	"strconv"
 
+	api_neutrino "github.com/pkt-cash/pktd/apiv1/neutrino"
 	"github.com/pkt-cash/pktd/btcjson"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/util"
-	"github.com/pkt-cash/pktd/connmgr/banmgr"
 	"github.com/pkt-cash/pktd/generated/proto/meta_pb"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/generated/proto/verrpc_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
@@ -27,55 +27,14 @@ type rpc struct {
 func (r *rpc) getinfo(m *rpc_pb.Null) (*meta_pb.GetInfo2Response, er.R) {
 	// Neutrino
 	ni := rpc_pb.NeutrinoInfo{}
-	neutrinoPeers := r.neutrinoCS.Peers()
-	for i := range neutrinoPeers {
-		var peerDesc rpc_pb.PeerDesc
-		neutrinoPeer := neutrinoPeers[i]
-
-		peerDesc.BytesReceived = neutrinoPeer.BytesReceived()
-		peerDesc.BytesSent = neutrinoPeer.BytesSent()
-		peerDesc.LastRecv = neutrinoPeer.LastRecv().String()
-		peerDesc.LastSend = neutrinoPeer.LastSend().String()
-		peerDesc.Connected = neutrinoPeer.Connected()
-		peerDesc.Addr = neutrinoPeer.Addr()
-		peerDesc.Inbound = neutrinoPeer.Inbound()
-		na := neutrinoPeer.NA()
-		if na != nil {
-			peerDesc.Na = na.IP.String() + ":" + strconv.Itoa(int(na.Port))
-		}
-		peerDesc.Id = neutrinoPeer.ID()
-		peerDesc.UserAgent = neutrinoPeer.UserAgent()
-		peerDesc.Services = neutrinoPeer.Services().String()
-		peerDesc.VersionKnown = neutrinoPeer.VersionKnown()
-		peerDesc.AdvertisedProtoVer = neutrinoPeer.Describe().AdvertisedProtoVer
-		peerDesc.ProtocolVersion = neutrinoPeer.ProtocolVersion()
-		peerDesc.SendHeadersPreferred = neutrinoPeer.Describe().SendHeadersPreferred
-		peerDesc.VerAckReceived = neutrinoPeer.VerAckReceived()
-		peerDesc.WitnessEnabled = neutrinoPeer.Describe().WitnessEnabled
-		peerDesc.WireEncoding = strconv.Itoa(int(neutrinoPeer.Describe().WireEncoding))
-		peerDesc.TimeOffset = neutrinoPeer.TimeOffset()
-		peerDesc.TimeConnected = neutrinoPeer.Describe().TimeConnected.String()
-		peerDesc.StartingHeight = neutrinoPeer.StartingHeight()
-		peerDesc.LastBlock = neutrinoPeer.LastBlock()
-		if neutrinoPeer.LastAnnouncedBlock() != nil {
-			peerDesc.LastAnnouncedBlock = neutrinoPeer.LastAnnouncedBlock().CloneBytes()
-		}
-		peerDesc.LastPingNonce = neutrinoPeer.LastPingNonce()
-		peerDesc.LastPingTime = neutrinoPeer.LastPingTime().String()
-		peerDesc.LastPingMicros = neutrinoPeer.LastPingMicros()
-
-		ni.Peers = append(ni.Peers, &peerDesc)
+	for _, sp := range r.neutrinoCS.Peers() {
+		ni.Peers = append(ni.Peers, api_neutrino.DescribePeer(sp))
+	}
+	if bans, err := api_neutrino.DescribeBans(r.neutrinoCS.BanMgr()); err != nil {
+		return nil, err
+	} else {
+		ni.Bans = bans
 	}
-	r.neutrinoCS.BanMgr().ForEachIp(func(bi banmgr.BanInfo) er.R {
-		ban := rpc_pb.NeutrinoBan{}
-		ban.Addr = bi.Addr
-		ban.Reason = bi.Reason
-		ban.EndTime = bi.BanExpiresTime.String()
-		ban.BanScore = bi.BanScore
-
-		ni.Bans = append(ni.Bans, &ban)
-		return nil
-	})
 
 	neutrionoQueries := r.neutrinoCS.GetActiveQueries()
 	for i := range neutrionoQueries {
@@ -197,6 +156,7 @@ func Register(
 		concerning the number of open+pending channels.
 		`,
 		r.getinfo,
+		help_pb.F_PERM_READ,
 	)
 
 	apiv1.Endpoint(
@@ -211,6 +171,7 @@ func Register(
 		sub-system.
 		`,
 		r.debuglevel,
+		help_pb.F_PERM_ADMIN,
 	)
 	apiv1.Endpoint(
 		a,
@@ -222,6 +183,7 @@ func Register(
 		a graceful shutdown of the daemon.
 		`,
 		r.stopdaemon,
+		help_pb.F_PERM_ADMIN,
 	)
 	apiv1.Endpoint(
 		a,
@@ -233,5 +195,6 @@ func Register(
 		daemon.
 		`,
 		r.version,
+		help_pb.F_PERM_READ,
 	)
 }
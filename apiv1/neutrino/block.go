@@ -0,0 +1,219 @@
+package neutrino
+
+import (
+	"bytes"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/describetxn"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/neutrino"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+type blocks struct {
+	cs          *neutrino.ChainService
+	chainParams *chaincfg.Params
+}
+
+// resolve finds the hash and height of the block which is requested by hash,
+// or by height if the hash is empty.
+func (b *blocks) resolve(hash string, height int32) (*chainhash.Hash, int32, er.R) {
+	if hash == "" {
+		if height < 0 {
+			return nil, 0, er.New("height must not be negative")
+		}
+		h, err := b.cs.GetBlockHash(int64(height))
+		if err != nil {
+			return nil, 0, err
+		}
+		return h, height, nil
+	}
+	h, err := chainhash.NewHashFromStr(hash)
+	if err != nil {
+		return nil, 0, err
+	}
+	height, err = b.cs.GetBlockHeight(h)
+	if err != nil {
+		return nil, 0, err
+	}
+	return h, height, nil
+}
+
+func (b *blocks) describeHeader(hash *chainhash.Hash, height int32) (*rpc_pb.NeutrinoBlockHeader, er.R) {
+	header, err := b.cs.GetBlockHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+	bb, err := b.cs.BestBlock()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return &rpc_pb.NeutrinoBlockHeader{
+		Hash:          hash.String(),
+		Height:        height,
+		Version:       header.Version,
+		PrevBlock:     header.PrevBlock.String(),
+		MerkleRoot:    header.MerkleRoot.String(),
+		Timestamp:     header.Timestamp.Unix(),
+		Bits:          header.Bits,
+		Nonce:         header.Nonce,
+		Confirmations: bb.Height - height + 1,
+		HeaderBin:     buf.Bytes(),
+	}, nil
+}
+
+func (b *blocks) hash(in *rpc_pb.NeutrinoBlockRequest) (*rpc_pb.NeutrinoBlockHashResponse, er.R) {
+	hash, height, err := b.resolve(in.Hash, in.Height)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.NeutrinoBlockHashResponse{
+		Hash:   hash.String(),
+		Height: height,
+	}, nil
+}
+
+func (b *blocks) header(in *rpc_pb.NeutrinoBlockRequest) (*rpc_pb.NeutrinoBlockHeader, er.R) {
+	hash, height, err := b.resolve(in.Hash, in.Height)
+	if err != nil {
+		return nil, err
+	}
+	return b.describeHeader(hash, height)
+}
+
+func (b *blocks) filter(in *rpc_pb.NeutrinoBlockRequest) (*rpc_pb.NeutrinoBlockFilterResponse, er.R) {
+	hash, height, err := b.resolve(in.Hash, in.Height)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := b.cs.GetCFilter(*hash)
+	if err != nil {
+		return nil, err
+	}
+	filterBin, err := filter.NBytes()
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.NeutrinoBlockFilterResponse{
+		Hash:      hash.String(),
+		Height:    height,
+		N:         filter.N(),
+		FilterBin: filterBin,
+	}, nil
+}
+
+func (b *blocks) block(in *rpc_pb.NeutrinoGetBlockRequest) (*rpc_pb.NeutrinoBlockResponse, er.R) {
+	hash, height, err := b.resolve(in.Hash, in.Height)
+	if err != nil {
+		return nil, err
+	}
+	header, err := b.describeHeader(hash, height)
+	if err != nil {
+		return nil, err
+	}
+	block, err := b.cs.GetBlock0(*hash)
+	if err != nil {
+		return nil, err
+	}
+	out := &rpc_pb.NeutrinoBlockResponse{Header: header}
+	if in.Raw {
+		blockBin, err := block.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		out.BlockBin = blockBin
+		return out, nil
+	}
+
+	// Only spends of outputs in the same block can be resolved, a light client
+	// does not have the rest of the chain.
+	txns := make(map[string]*wire.MsgTx, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		txns[tx.Hash().String()] = tx.MsgTx()
+	}
+	getTxns := func(want map[string]*wire.MsgTx) er.R {
+		for k := range want {
+			if tx, ok := txns[k]; ok {
+				want[k] = tx
+			}
+		}
+		return nil
+	}
+	for _, tx := range block.Transactions() {
+		txi, err := describetxn.Describe(getTxns, *tx.MsgTx(), b.chainParams, false)
+		if err != nil {
+			return nil, err
+		}
+		out.Transactions = append(out.Transactions, txi)
+	}
+	return out, nil
+}
+
+func registerBlock(a *apiv1.Apiv1, cs *neutrino.ChainService, chainParams *chaincfg.Params) {
+	b := &blocks{cs: cs, chainParams: chainParams}
+	blockCat := apiv1.DefineCategory(a, "block",
+		`
+		Blocks, headers and compact filters of the main chain
+
+		Blocks are given either by hash or by height. Headers are stored by
+		neutrino, filters and blocks are taken from the cache if possible and
+		otherwise downloaded from peers.
+		`,
+	)
+	apiv1.Endpoint(
+		blockCat,
+		"hash",
+		`
+		Get the hash of a block in the main chain by its height
+
+		If a hash is given instead, the height of the block is returned.
+		`,
+		b.hash,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		blockCat,
+		"header",
+		`
+		Get the header of a block
+
+		The header is returned decoded as well as in binary form.
+		`,
+		b.header,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		blockCat,
+		"filter",
+		`
+		Get the basic compact filter of a block
+
+		The filter is returned as it is sent on the p2p network, with the number
+		of items at the beginning.
+		`,
+		b.filter,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		blockCat,
+		"block",
+		`
+		Get a block
+
+		The transactions of the block are decoded unless raw is true, in which
+		case the serialized block is returned. Because neutrino does not have the
+		rest of the chain, the values of inputs are only known if they spend
+		outputs of the same block.
+		`,
+		b.block,
+		help_pb.F_PERM_READ,
+	)
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 
 	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/neutrino"
 	"github.com/pkt-cash/pktd/pktwallet/wallet"
 	"github.com/pkt-cash/pktd/wire"
 )
@@ -32,10 +34,23 @@ func (r *rpc) bcasttransaction(in *rpc_pb.BcastTransactionRequest) (*rpc_pb.Bcas
 	}, err
 }
 
+// Neutrino is the state of the neutrino endpoints which must be stopped before
+// the ChainService is.
+type Neutrino struct {
+	notify *notifier
+}
+
+// Stop ends every neutrino/notify stream and stops the chain notifier which
+// they use.
+func (n *Neutrino) Stop() {
+	n.notify.stop()
+}
+
 func Register(
 	a *apiv1.Apiv1,
 	w *wallet.Wallet,
-) {
+	cs *neutrino.ChainService,
+) *Neutrino {
 	r := rpc{w: w}
 	apiv1.Endpoint(
 		a,
@@ -46,5 +61,11 @@ func Register(
 		Broadcast a transaction to the network so it can be logged in the chain.
 		`,
 		r.bcasttransaction,
+		help_pb.F_PERM_WRITE,
 	)
+	n := &Neutrino{notify: registerNotify(a, cs)}
+	registerPeers(a, cs)
+	registerBlock(a, cs, w.ChainParams())
+	registerScan(a, cs, w.ChainParams())
+	return n
 }
@@ -0,0 +1,332 @@
+package neutrino
+
+import (
+	"bytes"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/lock"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/chainrpc_pb"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/lnd/chainntnfs"
+	"github.com/pkt-cash/pktd/lnd/chainntnfs/neutrinonotify"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/neutrino"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// noHintCache is a height hint cache which never has any hints, so every
+// request is scanned for starting at the height hint given by the client.
+type noHintCache struct{}
+
+var _ chainntnfs.SpendHintCache = noHintCache{}
+var _ chainntnfs.ConfirmHintCache = noHintCache{}
+
+func (noHintCache) CommitSpendHint(uint32, ...chainntnfs.SpendRequest) er.R { return nil }
+func (noHintCache) QuerySpendHint(chainntnfs.SpendRequest) (uint32, er.R) {
+	return 0, chainntnfs.ErrSpendHintNotFound.Default()
+}
+func (noHintCache) PurgeSpendHint(...chainntnfs.SpendRequest) er.R { return nil }
+
+func (noHintCache) CommitConfirmHint(uint32, ...chainntnfs.ConfRequest) er.R { return nil }
+func (noHintCache) QueryConfirmHint(chainntnfs.ConfRequest) (uint32, er.R) {
+	return 0, chainntnfs.ErrConfirmHintNotFound.Default()
+}
+func (noHintCache) PurgeConfirmHint(...chainntnfs.ConfRequest) er.R { return nil }
+
+type notifierMut struct {
+	cn      *neutrinonotify.NeutrinoNotifier
+	stopped bool
+}
+
+// notifier starts a chain notifier on top of the neutrino ChainService the
+// first time that somebody subscribes to a notification.
+type notifier struct {
+	cs *neutrino.ChainService
+	m  lock.GenMutex[notifierMut]
+}
+
+func (n *notifier) get() (chainntnfs.ChainNotifier, er.R) {
+	var out *neutrinonotify.NeutrinoNotifier
+	if err := n.m.In(func(m *notifierMut) er.R {
+		if m.stopped {
+			return er.New("pld is shutting down")
+		}
+		if m.cn == nil {
+			cn := neutrinonotify.New(n.cs, noHintCache{}, noHintCache{})
+			if err := cn.Start(); err != nil {
+				return err
+			}
+			m.cn = cn
+		}
+		out = m.cn
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// stop stops the chain notifier if it was started, which ends every stream,
+// and keeps it from being started again.
+func (n *notifier) stop() {
+	var cn *neutrinonotify.NeutrinoNotifier
+	n.m.In(func(m *notifierMut) er.R {
+		cn = m.cn
+		m.cn = nil
+		m.stopped = true
+		return nil
+	})
+	if cn == nil {
+		return
+	}
+	if err := cn.Stop(); err != nil {
+		log.Warnf("Unable to stop the chain notifier: [%s]", err)
+	}
+}
+
+func (n *notifier) confirmation(
+	req *chainrpc_pb.ConfRequest,
+	stop <-chan struct{},
+) (<-chan *chainrpc_pb.ConfEvent, er.R) {
+	cn, err := n.get()
+	if err != nil {
+		return nil, err
+	}
+	var txid chainhash.Hash
+	copy(txid[:], req.Txid)
+	confEvent, err := cn.RegisterConfirmationsNtfn(
+		&txid, req.Script, req.NumConfs, req.HeightHint,
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *chainrpc_pb.ConfEvent)
+	go func() {
+		defer close(out)
+		defer confEvent.Cancel()
+		for {
+			var ev *chainrpc_pb.ConfEvent
+			select {
+			case details, ok := <-confEvent.Confirmed:
+				if !ok {
+					return
+				}
+				var rawTx bytes.Buffer
+				if err := details.Tx.Serialize(&rawTx); err != nil {
+					log.Warnf("Unable to serialize confirmed transaction: [%s]", err)
+					return
+				}
+				ev = &chainrpc_pb.ConfEvent{
+					Event: &chainrpc_pb.ConfEvent_Conf{
+						Conf: &chainrpc_pb.ConfDetails{
+							RawTx:       rawTx.Bytes(),
+							BlockHash:   details.BlockHash[:],
+							BlockHeight: details.BlockHeight,
+							TxIndex:     details.TxIndex,
+						},
+					},
+				}
+			case _, ok := <-confEvent.NegativeConf:
+				if !ok {
+					return
+				}
+				ev = &chainrpc_pb.ConfEvent{
+					Event: &chainrpc_pb.ConfEvent_Reorg{Reorg: &chainrpc_pb.Reorg{}},
+				}
+			case <-confEvent.Done:
+				// Deep enough that it can no longer be reorged out
+				return
+			case <-stop:
+				return
+			}
+			select {
+			case out <- ev:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (n *notifier) spend(
+	req *chainrpc_pb.SpendRequest,
+	stop <-chan struct{},
+) (<-chan *chainrpc_pb.SpendEvent, er.R) {
+	cn, err := n.get()
+	if err != nil {
+		return nil, err
+	}
+	var op *wire.OutPoint
+	if req.Outpoint != nil {
+		var txid chainhash.Hash
+		copy(txid[:], req.Outpoint.Hash)
+		op = &wire.OutPoint{Hash: txid, Index: req.Outpoint.Index}
+	}
+	spendEvent, err := cn.RegisterSpendNtfn(op, req.Script, req.HeightHint)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *chainrpc_pb.SpendEvent)
+	go func() {
+		defer close(out)
+		defer spendEvent.Cancel()
+		for {
+			var ev *chainrpc_pb.SpendEvent
+			select {
+			case details, ok := <-spendEvent.Spend:
+				if !ok {
+					return
+				}
+				var rawTx bytes.Buffer
+				if err := details.SpendingTx.Serialize(&rawTx); err != nil {
+					log.Warnf("Unable to serialize spending transaction: [%s]", err)
+					return
+				}
+				ev = &chainrpc_pb.SpendEvent{
+					Event: &chainrpc_pb.SpendEvent_Spend{
+						Spend: &chainrpc_pb.SpendDetails{
+							SpendingOutpoint: &chainrpc_pb.Outpoint{
+								Hash:  details.SpentOutPoint.Hash[:],
+								Index: details.SpentOutPoint.Index,
+							},
+							RawSpendingTx:      rawTx.Bytes(),
+							SpendingTxHash:     details.SpenderTxHash[:],
+							SpendingInputIndex: details.SpenderInputIndex,
+							SpendingHeight:     uint32(details.SpendingHeight),
+						},
+					},
+				}
+			case _, ok := <-spendEvent.Reorg:
+				if !ok {
+					return
+				}
+				ev = &chainrpc_pb.SpendEvent{
+					Event: &chainrpc_pb.SpendEvent_Reorg{Reorg: &chainrpc_pb.Reorg{}},
+				}
+			case <-spendEvent.Done:
+				// Deep enough that it can no longer be reorged out
+				return
+			case <-stop:
+				return
+			}
+			select {
+			case out <- ev:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (n *notifier) blocks(
+	req *chainrpc_pb.BlockEpoch,
+	stop <-chan struct{},
+) (<-chan *chainrpc_pb.BlockEpoch, er.R) {
+	cn, err := n.get()
+	if err != nil {
+		return nil, err
+	}
+	// If a block is given then the blocks after it are sent first
+	var bestBlock *chainntnfs.BlockEpoch
+	var hash chainhash.Hash
+	copy(hash[:], req.Hash)
+	if hash != chainntnfs.ZeroHash && req.Height != 0 {
+		bestBlock = &chainntnfs.BlockEpoch{
+			Hash:   &hash,
+			Height: int32(req.Height),
+		}
+	}
+	epochEvent, err := cn.RegisterBlockEpochNtfn(bestBlock)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *chainrpc_pb.BlockEpoch)
+	go func() {
+		defer close(out)
+		defer epochEvent.Cancel()
+		for {
+			select {
+			case epoch, ok := <-epochEvent.Epochs:
+				if !ok {
+					return
+				}
+				select {
+				case out <- &chainrpc_pb.BlockEpoch{
+					Hash:   epoch.Hash[:],
+					Height: uint32(epoch.Height),
+				}:
+				case <-stop:
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func registerNotify(a *apiv1.Apiv1, cs *neutrino.ChainService) *notifier {
+	n := &notifier{
+		cs: cs,
+		m:  lock.NewGenMutex(notifierMut{}, "neutrino notifier"),
+	}
+	notifyCat := apiv1.DefineCategory(a, "notify",
+		`
+		Streams of events from the blockchain
+
+		These allow waiting for a transaction to confirm or an output to be
+		spent without polling. Hashes are binary, in the same byte order as
+		they appear in the block, which is the reverse of the usual hex form.
+		`,
+	)
+	apiv1.StreamSource(
+		notifyCat,
+		"confirmation",
+		`
+		Wait for a transaction or output script to be confirmed
+
+		If txid is all zeros then the first transaction which pays to the script
+		is watched for, otherwise script should be one of the output scripts of
+		the transaction so that it can be found using the block filters.
+		An event is sent when it has num_confs confirmations, and a reorg event
+		if it is reorged out of the chain afterward. The stream ends once it is
+		deep enough that it can no longer be reorged out.
+		`,
+		n.confirmation,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.StreamSource(
+		notifyCat,
+		"spend",
+		`
+		Wait for an outpoint or output script to be spent
+
+		If the outpoint is not given then the first spend of any output which pays
+		to the script is watched for. An event is sent when the spending
+		transaction confirms, and a reorg event if it is reorged out of the chain
+		afterward. The stream ends once the spend can no longer be reorged out.
+		`,
+		n.spend,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.StreamSource(
+		notifyCat,
+		"blocks",
+		`
+		Stream each block which is added to the chain
+
+		If a block hash and height are given, every block which was added since
+		that block is sent first so that none are missed between connections.
+		Each event is the hash and height of a block, if a block is seen a second
+		time at the same height then there was a reorg.
+		`,
+		n.blocks,
+		help_pb.F_PERM_READ,
+	)
+	return n
+}
@@ -0,0 +1,266 @@
+package neutrino
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/connmgr/banmgr"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/neutrino"
+)
+
+// defaultBanDuration is how long a ban lasts if no duration is requested.
+const defaultBanDuration = 24 * time.Hour
+
+// DescribePeer converts a neutrino peer to its description in the API.
+func DescribePeer(sp *neutrino.ServerPeer) *rpc_pb.PeerDesc {
+	desc := sp.Describe()
+	peerDesc := rpc_pb.PeerDesc{
+		BytesReceived:        sp.BytesReceived(),
+		BytesSent:            sp.BytesSent(),
+		LastRecv:             sp.LastRecv().String(),
+		LastSend:             sp.LastSend().String(),
+		Connected:            sp.Connected(),
+		Addr:                 sp.Addr(),
+		Inbound:              sp.Inbound(),
+		Id:                   sp.ID(),
+		UserAgent:            sp.UserAgent(),
+		Services:             sp.Services().String(),
+		VersionKnown:         sp.VersionKnown(),
+		AdvertisedProtoVer:   desc.AdvertisedProtoVer,
+		ProtocolVersion:      sp.ProtocolVersion(),
+		SendHeadersPreferred: desc.SendHeadersPreferred,
+		VerAckReceived:       sp.VerAckReceived(),
+		WitnessEnabled:       desc.WitnessEnabled,
+		WireEncoding:         strconv.Itoa(int(desc.WireEncoding)),
+		TimeOffset:           sp.TimeOffset(),
+		TimeConnected:        desc.TimeConnected.String(),
+		StartingHeight:       sp.StartingHeight(),
+		LastBlock:            sp.LastBlock(),
+		LastPingNonce:        sp.LastPingNonce(),
+		LastPingTime:         sp.LastPingTime().String(),
+		LastPingMicros:       sp.LastPingMicros(),
+	}
+	if na := sp.NA(); na != nil {
+		peerDesc.Na = na.IP.String() + ":" + strconv.Itoa(int(na.Port))
+	}
+	if sp.LastAnnouncedBlock() != nil {
+		peerDesc.LastAnnouncedBlock = sp.LastAnnouncedBlock().CloneBytes()
+	}
+	return &peerDesc
+}
+
+// DescribeBans lists the banned and suspicious addresses in the ban manager.
+func DescribeBans(bm *banmgr.BanMgr) ([]*rpc_pb.NeutrinoBan, er.R) {
+	var out []*rpc_pb.NeutrinoBan
+	err := bm.ForEachIp(func(bi banmgr.BanInfo) er.R {
+		out = append(out, &rpc_pb.NeutrinoBan{
+			Addr:     bi.Addr,
+			Reason:   bi.Reason,
+			EndTime:  bi.BanExpiresTime.String(),
+			BanScore: bi.BanScore,
+		})
+		return nil
+	})
+	return out, err
+}
+
+type peers struct {
+	cs *neutrino.ChainService
+}
+
+func (p *peers) list(*rpc_pb.Null) (*rpc_pb.NeutrinoPeersResponse, er.R) {
+	persistent := make(map[int32]bool)
+	for _, sp := range p.cs.AddedNodeInfo() {
+		persistent[sp.ID()] = true
+	}
+	out := &rpc_pb.NeutrinoPeersResponse{}
+	for _, sp := range p.cs.Peers() {
+		pd := DescribePeer(sp)
+		pd.Persistent = persistent[sp.ID()]
+		out.Peers = append(out.Peers, pd)
+	}
+	out.BytesReceived, out.BytesSent = p.cs.NetTotals()
+	bb, err := p.cs.BestBlock()
+	if err != nil {
+		return nil, err
+	}
+	out.Height = bb.Height
+	return out, nil
+}
+
+func (p *peers) connect(in *rpc_pb.NeutrinoConnectRequest) (*rpc_pb.Null, er.R) {
+	if in.Addr == "" {
+		return nil, er.New("addr is required")
+	}
+	if err := p.cs.ConnectNode(in.Addr, in.Persistent); err != nil {
+		return nil, err
+	}
+	return &rpc_pb.Null{}, nil
+}
+
+func (p *peers) disconnect(in *rpc_pb.NeutrinoDisconnectRequest) (*rpc_pb.Null, er.R) {
+	var err er.R
+	if in.Addr != "" {
+		err = p.cs.DisconnectNodeByAddr(in.Addr)
+	} else if in.Id != 0 {
+		err = p.cs.DisconnectNodeByID(in.Id)
+	} else {
+		return nil, er.New("addr or id is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.Null{}, nil
+}
+
+func (p *peers) remove(in *rpc_pb.NeutrinoDisconnectRequest) (*rpc_pb.Null, er.R) {
+	var err er.R
+	if in.Addr != "" {
+		err = p.cs.RemoveNodeByAddr(in.Addr)
+	} else if in.Id != 0 {
+		err = p.cs.RemoveNodeByID(in.Id)
+	} else {
+		return nil, er.New("addr or id is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.Null{}, nil
+}
+
+func (p *peers) bans(*rpc_pb.Null) (*rpc_pb.NeutrinoBansResponse, er.R) {
+	bans, err := DescribeBans(p.cs.BanMgr())
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.NeutrinoBansResponse{Bans: bans}, nil
+}
+
+func (p *peers) ban(in *rpc_pb.NeutrinoBanRequest) (*rpc_pb.Null, er.R) {
+	if in.Addr == "" {
+		return nil, er.New("addr is required")
+	} else if in.DurationSeconds < 0 {
+		return nil, er.New("duration_seconds must not be negative")
+	}
+	duration := defaultBanDuration
+	if in.DurationSeconds > 0 {
+		duration = time.Duration(in.DurationSeconds) * time.Second
+	}
+	reason := in.Reason
+	if reason == "" {
+		reason = "banned by user"
+	}
+	p.cs.BanPeer(in.Addr, duration, reason)
+	return &rpc_pb.Null{}, nil
+}
+
+func (p *peers) unban(in *rpc_pb.NeutrinoUnbanRequest) (*rpc_pb.Null, er.R) {
+	if !p.cs.BanMgr().Unban(in.Addr) {
+		return nil, er.Errorf("[%s] is not banned", in.Addr)
+	}
+	return &rpc_pb.Null{}, nil
+}
+
+func registerPeers(a *apiv1.Apiv1, cs *neutrino.ChainService) {
+	p := &peers{cs: cs}
+	peersCat := apiv1.DefineCategory(a, "peers",
+		`
+		Management of the p2p nodes which neutrino syncs from
+
+		A node which is stuck on a bad peer can be fixed by disconnecting or
+		banning it, and good peers can be added without restarting.
+		`,
+	)
+	apiv1.Endpoint(
+		peersCat,
+		"",
+		`
+		List the connected peers
+
+		last_block is the height of the best block which each peer has told us
+		about, a peer which is far behind the others is not able to sync us.
+		`,
+		p.list,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		peersCat,
+		"connect",
+		`
+		Connect to a peer
+
+		If persistent is true then the peer is reconnected whenever the connection
+		is lost, until it is removed with /neutrino/peers/remove.
+		`,
+		p.connect,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		peersCat,
+		"disconnect",
+		`
+		Disconnect a peer
+
+		The peer is given by addr or by id. The node may connect to it again later,
+		to prevent that, ban it instead. Persistent peers must be removed with
+		/neutrino/peers/remove.
+		`,
+		p.disconnect,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		peersCat,
+		"remove",
+		`
+		Disconnect a persistent peer and stop reconnecting to it
+		`,
+		p.remove,
+		help_pb.F_PERM_WRITE,
+	)
+
+	bansCat := apiv1.DefineCategory(peersCat, "bans",
+		`
+		Addresses which are not allowed to connect
+
+		Peers are banned automatically when they misbehave, bans are kept in
+		memory only so they end when the node is restarted.
+		`,
+	)
+	apiv1.Endpoint(
+		bansCat,
+		"",
+		`
+		List banned addresses
+
+		Addresses which have misbehaved but are not yet banned are listed with
+		their ban_score.
+		`,
+		p.bans,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		bansCat,
+		"add",
+		`
+		Ban an address
+
+		Every peer which is connected from the IP address is disconnected and it
+		is not allowed to connect again until the ban ends.
+		`,
+		p.ban,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		bansCat,
+		"remove",
+		`
+		Remove the ban of an address
+		`,
+		p.unban,
+		help_pb.F_PERM_WRITE,
+	)
+}
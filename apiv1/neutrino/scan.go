@@ -0,0 +1,521 @@
+package neutrino
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/neutrino"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/chainiface"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+const (
+	// maxScanJobs is the number of jobs which are kept, finished jobs are
+	// forgotten oldest first to make room for new ones.
+	maxScanJobs = 16
+
+	// scanBatch is the number of blocks which are passed to FilterBlocks at
+	// once, progress is reported after each batch.
+	scanBatch = 2000
+)
+
+// scanJob is a search of a range of blocks for transactions of a set of
+// addresses which are not in the wallet.
+type scanJob struct {
+	id          uint32
+	addrs       map[string]btcutil.Address
+	startHeight int32
+	endHeight   int32
+	chainParams *chaincfg.Params
+	stop        chan struct{}
+
+	m       sync.Mutex
+	height  int32
+	txns    []*rpc_pb.NeutrinoScanTx
+	unspent map[wire.OutPoint]*rpc_pb.NeutrinoScanUtxo
+	done    bool
+	err     er.R
+	// changed is closed and replaced whenever the job makes progress
+	changed chan struct{}
+}
+
+func (j *scanJob) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// watched returns the unspent coins which were found so far, a transaction
+// which spends one of them is relevant even if it pays to no scanned address.
+func (j *scanJob) watched() map[wire.OutPoint]btcutil.Address {
+	j.m.Lock()
+	defer j.m.Unlock()
+	out := make(map[wire.OutPoint]btcutil.Address, len(j.unspent))
+	for op, u := range j.unspent {
+		out[op] = j.addrs[u.Address]
+	}
+	return out
+}
+
+func (j *scanJob) progress(height int32) {
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.height = height
+	j.notify()
+}
+
+// addTxns records the transactions of a block which pay to or spend from the
+// scanned addresses and updates the unspent coins.
+func (j *scanJob) addTxns(txns []*wire.MsgTx, block *wtxmgr.BlockMeta) {
+	j.m.Lock()
+	defer j.m.Unlock()
+	for _, tx := range txns {
+		st := &rpc_pb.NeutrinoScanTx{
+			Txid:      tx.TxHash().String(),
+			Height:    block.Height,
+			BlockHash: block.Hash.String(),
+		}
+		found := make(map[string]struct{})
+		for _, in := range tx.TxIn {
+			if u, ok := j.unspent[in.PreviousOutPoint]; ok {
+				st.Sspent += u.Svalue
+				found[u.Address] = struct{}{}
+				delete(j.unspent, in.PreviousOutPoint)
+			}
+		}
+		txid := tx.TxHash()
+		for i, out := range tx.TxOut {
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(out.PkScript, j.chainParams)
+			if err != nil || len(addrs) != 1 {
+				continue
+			}
+			addr := addrs[0].EncodeAddress()
+			if _, ok := j.addrs[addr]; !ok {
+				continue
+			}
+			st.Sreceived += out.Value
+			found[addr] = struct{}{}
+			j.unspent[wire.OutPoint{Hash: txid, Index: uint32(i)}] = &rpc_pb.NeutrinoScanUtxo{
+				Outpoint: &rpc_pb.OutPoint{
+					TxidBytes:   txid[:],
+					TxidStr:     txid.String(),
+					OutputIndex: uint32(i),
+				},
+				Address: addr,
+				Svalue:  out.Value,
+				Height:  block.Height,
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+		for addr := range found {
+			st.Addresses = append(st.Addresses, addr)
+		}
+		sort.Strings(st.Addresses)
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err == nil {
+			st.TxBin = buf.Bytes()
+		}
+		j.txns = append(j.txns, st)
+	}
+	j.height = block.Height
+	j.notify()
+}
+
+func (j *scanJob) finish(err er.R) {
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.done = true
+	j.err = err
+	j.notify()
+}
+
+// unspentList returns the unspent coins in the order they were received,
+// the job must be locked.
+func (j *scanJob) unspentList() []*rpc_pb.NeutrinoScanUtxo {
+	out := make([]*rpc_pb.NeutrinoScanUtxo, 0, len(j.unspent))
+	for _, u := range j.unspent {
+		out = append(out, u)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].Height != out[b].Height {
+			return out[a].Height < out[b].Height
+		} else if out[a].Outpoint.TxidStr != out[b].Outpoint.TxidStr {
+			return out[a].Outpoint.TxidStr < out[b].Outpoint.TxidStr
+		}
+		return out[a].Outpoint.OutputIndex < out[b].Outpoint.OutputIndex
+	})
+	return out
+}
+
+func errString(err er.R) string {
+	if err == nil {
+		return ""
+	}
+	return err.Message()
+}
+
+func (j *scanJob) status(full bool) *rpc_pb.NeutrinoAddressScanStatus {
+	j.m.Lock()
+	defer j.m.Unlock()
+	out := &rpc_pb.NeutrinoAddressScanStatus{
+		Id:          j.id,
+		StartHeight: j.startHeight,
+		EndHeight:   j.endHeight,
+		Height:      j.height,
+		Done:        j.done,
+		Error:       errString(j.err),
+	}
+	for addr := range j.addrs {
+		out.Addresses = append(out.Addresses, addr)
+	}
+	sort.Strings(out.Addresses)
+	if full {
+		out.Transactions = append(out.Transactions, j.txns...)
+		out.Unspent = j.unspentList()
+	}
+	return out
+}
+
+type scanner struct {
+	cs          *neutrino.ChainService
+	chainParams *chaincfg.Params
+
+	m      sync.Mutex
+	nextID uint32
+	jobs   map[uint32]*scanJob
+}
+
+func (s *scanner) job(id uint32) (*scanJob, er.R) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if j, ok := s.jobs[id]; ok {
+		return j, nil
+	}
+	return nil, er.Errorf("No scan job with id [%d]", id)
+}
+
+func (s *scanner) scan(j *scanJob) er.R {
+	addrs := make([]btcutil.Address, 0, len(j.addrs))
+	for _, addr := range j.addrs {
+		addrs = append(addrs, addr)
+	}
+	for height := j.startHeight; height <= j.endHeight; {
+		blocks := make([]wtxmgr.BlockMeta, 0, scanBatch)
+		for h := height; h <= j.endHeight && len(blocks) < scanBatch; h++ {
+			hash, err := s.cs.GetBlockHash(int64(h))
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, wtxmgr.BlockMeta{
+				Block: dbstructs.Block{Hash: *hash, Height: h},
+			})
+		}
+		height += int32(len(blocks))
+
+		// FilterBlocks stops at the first block with relevant transactions,
+		// the rest of the batch is filtered from the block after it because
+		// the coins which were found there need to be watched.
+		for len(blocks) > 0 {
+			select {
+			case <-j.stop:
+				return er.New("The scan was cancelled")
+			case <-s.cs.Quit():
+				return er.New("The scan was stopped because pld is shutting down")
+			default:
+			}
+			res, err := s.cs.FilterBlocks(&chainiface.FilterBlocksRequest{
+				Blocks:           blocks,
+				ImportedAddrs:    addrs,
+				WatchedOutPoints: j.watched(),
+			})
+			if err != nil {
+				return err
+			} else if res == nil {
+				break
+			}
+			j.addTxns(res.RelevantTxns, &res.BlockMeta)
+			blocks = blocks[res.BatchIndex+1:]
+		}
+		j.progress(height - 1)
+	}
+	return nil
+}
+
+func (s *scanner) run(j *scanJob) {
+	log.Infof("Address scan [%d] of [%d] addresses from block [%d] to [%d] started",
+		j.id, len(j.addrs), j.startHeight, j.endHeight)
+	err := s.scan(j)
+	if err != nil {
+		log.Infof("Address scan [%d] stopped: [%s]", j.id, err.Message())
+	} else {
+		log.Infof("Address scan [%d] complete", j.id)
+	}
+	j.finish(err)
+}
+
+func (s *scanner) start(in *rpc_pb.NeutrinoAddressScanRequest) (*rpc_pb.NeutrinoAddressScanStatus, er.R) {
+	if len(in.Addresses) == 0 {
+		return nil, er.New("At least one address is required")
+	}
+	addrs := make(map[string]btcutil.Address, len(in.Addresses))
+	for _, a := range in.Addresses {
+		addr, err := btcutil.DecodeAddress(a, s.chainParams)
+		if err != nil {
+			return nil, er.Errorf("Invalid address [%s]: %s", a, err.Message())
+		}
+		addrs[addr.EncodeAddress()] = addr
+	}
+	bb, err := s.cs.BestBlock()
+	if err != nil {
+		return nil, err
+	}
+	endHeight := in.EndHeight
+	if endHeight == 0 {
+		endHeight = bb.Height
+	} else if endHeight > bb.Height {
+		return nil, er.Errorf("end_height [%d] is beyond the tip of the chain [%d]",
+			endHeight, bb.Height)
+	}
+	if in.StartHeight < 0 || in.StartHeight > endHeight {
+		return nil, er.Errorf("start_height must be between 0 and end_height [%d]", endHeight)
+	}
+	j := &scanJob{
+		addrs:       addrs,
+		startHeight: in.StartHeight,
+		endHeight:   endHeight,
+		chainParams: s.chainParams,
+		stop:        make(chan struct{}),
+		height:      in.StartHeight - 1,
+		unspent:     make(map[wire.OutPoint]*rpc_pb.NeutrinoScanUtxo),
+		changed:     make(chan struct{}),
+	}
+	if err := s.add(j); err != nil {
+		return nil, err
+	}
+	go s.run(j)
+	return j.status(false), nil
+}
+
+// add assigns an id to the job and keeps it, forgetting the oldest finished
+// job if there are too many.
+func (s *scanner) add(j *scanJob) er.R {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if len(s.jobs) >= maxScanJobs {
+		var oldest *scanJob
+		for _, other := range s.jobs {
+			other.m.Lock()
+			done := other.done
+			other.m.Unlock()
+			if done && (oldest == nil || other.id < oldest.id) {
+				oldest = other
+			}
+		}
+		if oldest == nil {
+			return er.Errorf("There are already [%d] scan jobs running", maxScanJobs)
+		}
+		delete(s.jobs, oldest.id)
+	}
+	s.nextID++
+	j.id = s.nextID
+	s.jobs[j.id] = j
+	return nil
+}
+
+func (s *scanner) status(in *rpc_pb.NeutrinoAddressScanJobRequest) (*rpc_pb.NeutrinoAddressScanStatus, er.R) {
+	j, err := s.job(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	return j.status(true), nil
+}
+
+func (s *scanner) listJobs(*rpc_pb.Null) (*rpc_pb.NeutrinoAddressScanJobs, er.R) {
+	s.m.Lock()
+	jobs := make([]*scanJob, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.m.Unlock()
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].id < jobs[b].id })
+	out := &rpc_pb.NeutrinoAddressScanJobs{}
+	for _, j := range jobs {
+		out.Jobs = append(out.Jobs, j.status(false))
+	}
+	return out, nil
+}
+
+func (s *scanner) cancel(in *rpc_pb.NeutrinoAddressScanJobRequest) (*rpc_pb.Null, er.R) {
+	s.m.Lock()
+	j, ok := s.jobs[in.Id]
+	delete(s.jobs, in.Id)
+	s.m.Unlock()
+	if !ok {
+		return nil, er.Errorf("No scan job with id [%d]", in.Id)
+	}
+	close(j.stop)
+	return &rpc_pb.Null{}, nil
+}
+
+func (s *scanner) events(
+	in *rpc_pb.NeutrinoAddressScanJobRequest,
+	stop <-chan struct{},
+) (<-chan *rpc_pb.NeutrinoAddressScanEvent, er.R) {
+	j, err := s.job(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *rpc_pb.NeutrinoAddressScanEvent)
+	go func() {
+		defer close(out)
+		sent := 0
+		lastHeight := int32(-1)
+		for {
+			// Transactions which were found before the client connected are
+			// sent first so that none are missed.
+			var evs []*rpc_pb.NeutrinoAddressScanEvent
+			j.m.Lock()
+			for ; sent < len(j.txns); sent++ {
+				evs = append(evs, &rpc_pb.NeutrinoAddressScanEvent{
+					Id:          j.id,
+					Height:      j.txns[sent].Height,
+					Transaction: j.txns[sent],
+				})
+			}
+			if j.done {
+				evs = append(evs, &rpc_pb.NeutrinoAddressScanEvent{
+					Id:      j.id,
+					Height:  j.height,
+					Done:    true,
+					Error:   errString(j.err),
+					Unspent: j.unspentList(),
+				})
+			} else if j.height != lastHeight {
+				lastHeight = j.height
+				evs = append(evs, &rpc_pb.NeutrinoAddressScanEvent{
+					Id:     j.id,
+					Height: j.height,
+				})
+			}
+			done := j.done
+			changed := j.changed
+			j.m.Unlock()
+
+			for _, ev := range evs {
+				select {
+				case out <- ev:
+				case <-stop:
+					return
+				}
+			}
+			if done {
+				return
+			}
+			select {
+			case <-changed:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func registerScan(a *apiv1.Apiv1, cs *neutrino.ChainService, chainParams *chaincfg.Params) {
+	s := &scanner{
+		cs:          cs,
+		chainParams: chainParams,
+		jobs:        make(map[uint32]*scanJob),
+	}
+	addressCat := apiv1.DefineCategory(a, "address",
+		`
+		Information about addresses which are not in the wallet
+		`,
+	)
+	scanCat := apiv1.DefineCategory(addressCat, "scan",
+		`
+		Search the chain for the transactions and coins of any addresses
+
+		A scan job checks the compact filter of each block for the addresses and
+		downloads the blocks which match, so the addresses do not need to be
+		imported into the wallet. Jobs are kept in memory only, they are lost
+		when the node is restarted. Unconfirmed transactions are not found.
+		`,
+	)
+	apiv1.Endpoint(
+		scanCat,
+		"",
+		`
+		List the scan jobs
+
+		Jobs are listed without their transactions and unspent coins, use
+		/neutrino/address/scan/status to get them.
+		`,
+		s.listJobs,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		scanCat,
+		"start",
+		`
+		Start a scan job
+
+		Blocks from start_height to end_height are scanned. Coins which were
+		received before start_height are not known so spends of them are not
+		found either, for a complete history start_height must be before the
+		first payment to the addresses. The id of the job is returned.
+		`,
+		s.start,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		scanCat,
+		"status",
+		`
+		Get the progress and results of a scan job
+
+		The transactions which were found and the coins which are unspent as of
+		the last scanned block are returned, also while the job is running.
+		`,
+		s.status,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		scanCat,
+		"cancel",
+		`
+		Cancel a scan job
+
+		A running job is stopped, and the job is forgotten whether it was running
+		or not.
+		`,
+		s.cancel,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.StreamSource(
+		scanCat,
+		"events",
+		`
+		Stream the results of a scan job
+
+		Every transaction which is found is sent as an event, including those
+		which were found before the stream was opened, along with progress events
+		with the height of the last block scanned. The last event has done set
+		and includes the unspent coins.
+		`,
+		s.events,
+		help_pb.F_PERM_READ,
+	)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/generated/proto/walletunlocker_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
@@ -103,6 +104,59 @@ func changepassphrase(
 	}, nil
 }
 
+func split(req *rpc_pb.SplitSeedRequest) (*rpc_pb.SplitSeedResponse, er.R) {
+	mnemonic := strings.Join(req.Seed, " ")
+	if len(mnemonic) == 0 {
+		return nil, er.New("Seed is required in the request")
+	}
+	seedEnc, err := seedwords.SeedFromWords(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	defer seedEnc.Zero()
+	shares, err := seedEnc.Split(int(req.Threshold), int(req.Shares))
+	if err != nil {
+		return nil, err
+	}
+	out := &rpc_pb.SplitSeedResponse{}
+	for _, sh := range shares {
+		words, err := sh.Words("english")
+		sh.Zero()
+		if err != nil {
+			return nil, err
+		}
+		out.Shares = append(out.Shares, &rpc_pb.SeedShare{
+			Words: strings.Split(words, " "),
+		})
+	}
+	return out, nil
+}
+
+func combine(req *rpc_pb.CombineSeedSharesRequest) (*rpc_pb.CombineSeedSharesResponse, er.R) {
+	shares := make([]*seedwords.Share, 0, len(req.Shares))
+	for i, s := range req.Shares {
+		sh, err := seedwords.ShareFromWords(strings.Join(s.Words, " "))
+		if err != nil {
+			return nil, er.Errorf("Share number [%d]: %s", i+1, err.Message())
+		}
+		defer sh.Zero()
+		shares = append(shares, sh)
+	}
+	seedEnc, err := seedwords.CombineShares(shares)
+	if err != nil {
+		return nil, err
+	}
+	defer seedEnc.Zero()
+	mnemonic, err := seedEnc.Words("english")
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.CombineSeedSharesResponse{
+		Seed:            strings.Split(mnemonic, " "),
+		NeedsPassphrase: seedEnc.NeedsPassphrase(),
+	}, nil
+}
+
 func Register(
 	a *apiv1.Apiv1,
 	params *chaincfg.Params,
@@ -117,6 +171,7 @@ func Register(
 		This seed can then be used to initialize a wallet.
 		`,
 		create,
+		help_pb.F_PERM_SECRET,
 	)
 	apiv1.Endpoint(
 		a,
@@ -128,5 +183,34 @@ func Register(
 		representing the same seed but encrypted with a different passphrase.
 		`,
 		changepassphrase,
+		help_pb.F_PERM_SECRET,
+	)
+	apiv1.Endpoint(
+		a,
+		"split",
+		`
+		Split a wallet seed into shares
+
+		The seed is split into a number of shares of 19 words, any threshold of
+		which can be combined with util/seed/combine to recover the seed, fewer
+		reveal nothing about it. The seed stays encrypted with its passphrase, so
+		the passphrase is still needed to use the recovered seed. The shares can
+		also be given to pld --create.
+		`,
+		split,
+		help_pb.F_PERM_SECRET,
+	)
+	apiv1.Endpoint(
+		a,
+		"combine",
+		`
+		Recover a wallet seed from shares
+
+		Shares which were made by util/seed/split are combined into the seed words.
+		Each share has a checksum, and shares of different seeds, duplicate shares
+		or too few shares are errors.
+		`,
+		combine,
+		help_pb.F_PERM_SECRET,
 	)
 }
@@ -0,0 +1,260 @@
+package account
+
+import (
+	"bytes"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/enough"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+type rpc struct {
+	w *wallet.Wallet
+}
+
+// lookup finds the account named in a request, the scope is only needed if
+// the name is used in more than one scope.
+func (r *rpc) lookup(name string, purpose, coin uint32) (waddrmgr.KeyScope, uint32, er.R) {
+	if name == "" {
+		return waddrmgr.KeyScope{}, 0, er.New("account name is required")
+	}
+	if purpose == 0 {
+		return r.w.LookupAccount(name, nil)
+	}
+	scope, err := r.scope(purpose, coin)
+	if err != nil {
+		return waddrmgr.KeyScope{}, 0, err
+	}
+	return r.w.LookupAccount(name, &scope)
+}
+
+// scope returns the key scope of a request, if the coin is not given then it is
+// the coin type which this wallet uses for the purpose.
+func (r *rpc) scope(purpose, coin uint32) (waddrmgr.KeyScope, er.R) {
+	if coin != 0 {
+		return waddrmgr.KeyScope{Purpose: purpose, Coin: coin}, nil
+	}
+	return r.w.ScopeForPurpose(purpose)
+}
+
+func (r *rpc) list(req *rpc_pb.ListAccountsRequest) (*rpc_pb.ListAccountsResponse, er.R) {
+	minconf := req.Minconf
+	if minconf == 0 {
+		minconf = 1
+	}
+	accts, err := r.w.Accounts(minconf)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*rpc_pb.AccountInfo, 0, len(accts))
+	for _, a := range accts {
+		ai := &rpc_pb.AccountInfo{
+			Name:             a.AccountName,
+			Number:           a.AccountNumber,
+			Purpose:          a.Scope.Purpose,
+			Coin:             a.Scope.Coin,
+			WatchOnly:        a.WatchOnly,
+			GapLimit:         a.GapLimit,
+			ExternalKeyCount: a.ExternalKeyCount,
+			InternalKeyCount: a.InternalKeyCount,
+			Stotal:           int64(a.Total),
+			Sspendable:       int64(a.Spendable),
+			Simmaturereward:  int64(a.ImmatureReward),
+			Sunconfirmed:     int64(a.Unconfirmed),
+			Outputcount:      a.OutputCount,
+		}
+		if a.WatchOnly && a.AccountPubKey != nil {
+			ai.AccountKey = a.AccountPubKey.String()
+		}
+		out = append(out, ai)
+	}
+	return &rpc_pb.ListAccountsResponse{Accounts: out}, nil
+}
+
+func (r *rpc) importxpub(req *rpc_pb.ImportAccountXpubRequest) (*rpc_pb.AccountInfo, er.R) {
+	if req.Name == "" {
+		return nil, er.New("account name is required")
+	}
+	key, impliedPurpose, err := waddrmgr.ParseAccountPubKey(req.AccountKey, r.w.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+	purpose := req.Purpose
+	if purpose == 0 {
+		purpose = impliedPurpose
+		if purpose == 0 {
+			purpose = waddrmgr.KeyScopeBIP0044.Purpose
+		}
+	} else if impliedPurpose != 0 && impliedPurpose != purpose {
+		return nil, er.Errorf("The account key is for purpose [%d] but purpose [%d] was requested",
+			impliedPurpose, purpose)
+	}
+	scope, err := r.scope(purpose, req.Coin)
+	if err != nil {
+		return nil, err
+	}
+	props, err := r.w.ImportAccountWatchingOnly(scope, req.Name, key, req.GapLimit)
+	if err != nil {
+		return nil, err
+	}
+	if req.RescanFromHeight > 0 {
+		if err := r.w.ResyncChain(req.RescanFromHeight, -1, nil, false); err != nil {
+			return nil, err
+		}
+	}
+	return &rpc_pb.AccountInfo{
+		Name:             props.AccountName,
+		Number:           props.AccountNumber,
+		Purpose:          scope.Purpose,
+		Coin:             scope.Coin,
+		WatchOnly:        props.WatchOnly,
+		GapLimit:         props.GapLimit,
+		AccountKey:       props.AccountPubKey.String(),
+		ExternalKeyCount: props.ExternalKeyCount,
+		InternalKeyCount: props.InternalKeyCount,
+	}, nil
+}
+
+func (r *rpc) address(req *rpc_pb.AccountRequest) (*rpc_pb.GetNewAddressResponse, er.R) {
+	scope, account, err := r.lookup(req.Name, req.Purpose, req.Coin)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := r.w.NewAddress(account, scope)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.GetNewAddressResponse{
+		Address: addr.EncodeAddress(),
+	}, nil
+}
+
+func (r *rpc) createTransaction(
+	req *rpc_pb.AccountCreateTransactionRequest,
+) (*rpc_pb.CreateTransactionResponse, er.R) {
+	scope, account, err := r.lookup(req.Name, req.Purpose, req.Coin)
+	if err != nil {
+		return nil, err
+	}
+	if req.MinConf < 0 {
+		return nil, er.New("minconf must be positive")
+	}
+	var amount btcutil.Amount
+	if req.Amount <= 0 {
+		return nil, er.New("amount must be positive")
+	} else if req.Amount >= 6e9 {
+		amount = btcutil.Amount(enough.SweepOutputAmount)
+	} else if amount, err = btcutil.NewAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	to, err := btcutil.DecodeAddress(req.ToAddress, r.w.ChainParams())
+	if err != nil {
+		return nil, er.Errorf("cannot decode address: %s", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(to)
+	if err != nil {
+		return nil, er.Errorf("cannot create txout script: %s", err)
+	}
+
+	tx, err := r.w.CreateWatchOnlyTx(scope, account, wallet.CreateTxReq{
+		Outputs:     []*wire.TxOut{wire.NewTxOut(int64(amount), pkScript)},
+		Minconf:     req.MinConf,
+		FeeSatPerKB: txrules.DefaultRelayFeePerKb,
+		MaxInputs:   int(req.MaxInputs),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, in := range tx.Tx.TxIn {
+		r.w.LockOutpoint(in.PreviousOutPoint, req.Autolock)
+	}
+	log.Infof("Created unsigned transaction [%s] from watch-only account [%s]",
+		log.Txid(tx.Tx.TxHash().String()), req.Name)
+
+	b := bytes.NewBuffer(make([]byte, 0, tx.Tx.SerializeSize()))
+	if err := tx.Tx.Serialize(b); err != nil {
+		return nil, err
+	}
+	return &rpc_pb.CreateTransactionResponse{
+		Transaction: b.Bytes(),
+	}, nil
+}
+
+func Register(
+	a *apiv1.Apiv1,
+	w *wallet.Wallet,
+) {
+	r := rpc{w: w}
+	apiv1.Endpoint(
+		a,
+		"",
+		`
+		List the accounts of the wallet with their balances
+
+		Every account of every key scope is listed, including watch-only accounts
+		which were imported from an extended public key. Coins in watch-only
+		accounts are counted in the account balance but they cannot be spent by
+		this wallet.
+		`,
+		r.list,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		a,
+		"importxpub",
+		`
+		Import a watch-only account from an extended public key
+
+		The account key is the extended public key of an account, i.e. the key at
+		m/purpose'/coin_type'/account', such as it is exported by a cold storage
+		wallet. xpub, ypub and zpub keys are accepted, a ypub key is imported
+		under purpose 49, a zpub key under purpose 84 and an xpub key under
+		purpose 44 unless another purpose is given.
+
+		Addresses of the account are derived up to the gap limit (default 20) and
+		each time one of them is paid, more are derived so that there are always
+		gap_limit unused addresses past the last used one. The account can track
+		its balance and make unsigned transactions but it can never sign.
+		Payments made before the account was imported are only found by a
+		re-scan, set rescan_from_height or use wallet/address/resync.
+		`,
+		r.importxpub,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"address",
+		`
+		Generates a new receiving address in an account
+
+		The account is looked up by name, the purpose and coin only need to be
+		given if there is more than one account with the same name.
+		`,
+		r.address,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"createtransaction",
+		`
+		Create an unsigned transaction which spends from a watch-only account
+
+		Coins are selected from the addresses of the account and change is paid
+		to a new internal address of the account. The transaction is returned
+		unsigned, it must be signed by the wallet which holds the private key of
+		the account before it can be sent. The outputs which it spends are locked
+		with the autolock name so that they will not be spent twice.
+		`,
+		r.createTransaction,
+		help_pb.F_PERM_WRITE,
+	)
+}
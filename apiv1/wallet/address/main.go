@@ -8,6 +8,7 @@ import (
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
@@ -76,6 +77,10 @@ func (r *rpc) _import(req *rpc_pb.ImportPrivKeyRequest) (*rpc_pb.ImportPrivKeyRe
 func (r *rpc) balances(
 	in *rpc_pb.GetAddressBalancesRequest,
 ) (*rpc_pb.GetAddressBalancesResponse, er.R) {
+	labels, err := r.w.AddressLabels()
+	if err != nil {
+		return nil, err
+	}
 	if adb, err := r.w.CalculateAddressBalances(in.Minconf, in.Showzerobalance); err != nil {
 		return nil, err
 	} else {
@@ -97,6 +102,7 @@ func (r *rpc) balances(
 				Sunconfirmed:    int64(v.Unconfirmed),
 				Outputcount:     v.OutputCount,
 				Vote:            vote,
+				Label:           labels[k.EncodeAddress()],
 			})
 		}
 		return &rpc_pb.GetAddressBalancesResponse{Addrs: resp}, nil
@@ -174,6 +180,39 @@ func (r *rpc) signmessage(in *rpc_pb.SignMessageRequest) (*rpc_pb.SignMessageRes
 	}, nil
 }
 
+func (r *rpc) label(req *rpc_pb.SetAddressLabelRequest) (*rpc_pb.Null, er.R) {
+	return nil, r.w.SetAddressLabel(req.Address, req.Label)
+}
+
+func (r *rpc) book(*rpc_pb.Null) (*rpc_pb.AddressBookResponse, er.R) {
+	addrs, ops, err := r.w.AddressBook()
+	if err != nil {
+		return nil, err
+	}
+	out := &rpc_pb.AddressBookResponse{
+		Addresses: make([]*rpc_pb.AddressBookEntry, 0, len(addrs)),
+		Outpoints: make([]*rpc_pb.OutPointLabel, 0, len(ops)),
+	}
+	for _, a := range addrs {
+		out.Addresses = append(out.Addresses, &rpc_pb.AddressBookEntry{
+			Address: a.Address,
+			Label:   a.Label,
+			Mine:    a.Mine,
+		})
+	}
+	for _, o := range ops {
+		out.Outpoints = append(out.Outpoints, &rpc_pb.OutPointLabel{
+			Outpoint: &rpc_pb.OutPoint{
+				TxidBytes:   o.OutPoint.Hash[:],
+				TxidStr:     o.OutPoint.Hash.String(),
+				OutputIndex: o.OutPoint.Index,
+			},
+			Label: o.Label,
+		})
+	}
+	return out, nil
+}
+
 func Register(
 	a *apiv1.Apiv1,
 	w *wallet.Wallet,
@@ -190,6 +229,7 @@ func Register(
 		Use meta/getinfo to follow up on the status.
 		`,
 		r.resync,
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		a,
@@ -202,6 +242,7 @@ func Register(
 		Check meta/getinfo to see if there is a resync job ongoing.
 		`,
 		r.stopresync,
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		a,
@@ -214,6 +255,7 @@ func Register(
 		In a wallet with many outputs, this endpoint can take a long time.
 		`,
 		r.balances,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		a,
@@ -224,6 +266,7 @@ func Register(
 		Generates a new payment address
 		`,
 		r.create,
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		a,
@@ -237,6 +280,7 @@ func Register(
 		are affected.
 		`,
 		r.dumpprivkey,
+		help_pb.F_PERM_SECRET,
 	)
 	apiv1.Endpoint(
 		a,
@@ -250,6 +294,7 @@ func Register(
 		wallet from seed as they are not mathmatically derived from the seed.
 		`,
 		r._import,
+		help_pb.F_PERM_SECRET,
 	)
 	apiv1.Endpoint(
 		a,
@@ -265,5 +310,32 @@ func Register(
 		beginning with a 'p') can currently be used to sign messages.
 		`,
 		r.signmessage,
+		help_pb.F_PERM_SPEND,
+	)
+	apiv1.Endpoint(
+		a,
+		"label",
+		`
+		Label an address in the address book
+
+		The address may be one of the wallet's or a counterparty's, e.g. a mining
+		pool payout address. The label is shown by wallet/address/balances and
+		wallet/transaction/query. An empty label removes the address from the
+		address book. Coins can be labeled with wallet/unspent/label.
+		`,
+		r.label,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"book",
+		`
+		List the address book
+
+		Returns every labeled address, and whether it belongs to the wallet, and
+		every labeled coin.
+		`,
+		r.book,
+		help_pb.F_PERM_READ,
 	)
 }
@@ -0,0 +1,155 @@
+package consolidate
+
+import (
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/pktwallet/wallet"
+)
+
+type rpc struct {
+	w *wallet.Wallet
+}
+
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func configToRpc(c *wallet.ConsolidationConfig) *rpc_pb.ConsolidationConfig {
+	return &rpc_pb.ConsolidationConfig{
+		Enabled:         c.Enabled,
+		Threshold:       c.Threshold,
+		BatchSize:       c.BatchSize,
+		ToAddress:       c.ToAddress,
+		SfeePerKb:       int64(c.FeeSatPerKB),
+		MaxFeePercent:   c.MaxFeePercent,
+		MinConf:         c.MinConf,
+		IntervalSeconds: int64(c.Interval / time.Second),
+		MaxSfeePerKb:    int64(c.MaxFeeSatPerKB),
+	}
+}
+
+func (r *rpc) status(*rpc_pb.Null) (*rpc_pb.ConsolidationStatus, er.R) {
+	s, err := r.w.ConsolidationStatus()
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.ConsolidationStatus{
+		Config:                 configToRpc(&s.Config),
+		Running:                s.Running,
+		LastRun:                unixTime(s.LastRun),
+		NextRun:                unixTime(s.NextRun),
+		LastError:              s.LastError,
+		AddressesOverThreshold: int32(s.AddressesOverThreshold),
+		Transactions:           s.Transactions,
+		CoinsConsolidated:      s.CoinsConsolidated,
+		SfeesPaid:              int64(s.FeesPaid),
+		LastTxid:               s.LastTxid,
+	}, nil
+}
+
+func (r *rpc) configure(req *rpc_pb.ConsolidationConfig) (*rpc_pb.ConsolidationConfig, er.R) {
+	if req.SfeePerKb < 0 || req.MaxSfeePerKb < 0 || req.IntervalSeconds < 0 {
+		return nil, er.New("fee rates and interval must not be negative")
+	}
+	c := wallet.DefaultConsolidationConfig()
+	c.Enabled = req.Enabled
+	c.ToAddress = req.ToAddress
+	if req.Threshold > 0 {
+		c.Threshold = req.Threshold
+	}
+	if req.BatchSize > 0 {
+		c.BatchSize = req.BatchSize
+	}
+	if req.SfeePerKb > 0 {
+		c.FeeSatPerKB = btcutil.Amount(req.SfeePerKb)
+	}
+	if req.MaxFeePercent > 0 {
+		c.MaxFeePercent = req.MaxFeePercent
+	}
+	c.MaxFeeSatPerKB = btcutil.Amount(req.MaxSfeePerKb)
+	if req.MinConf > 0 {
+		c.MinConf = req.MinConf
+	}
+	if req.IntervalSeconds > 0 {
+		c.Interval = time.Duration(req.IntervalSeconds) * time.Second
+	}
+	if err := r.w.SetConsolidationConfig(&c); err != nil {
+		return nil, err
+	}
+	return configToRpc(&c), nil
+}
+
+func (r *rpc) run(*rpc_pb.Null) (*rpc_pb.Null, er.R) {
+	cfg, err := r.w.ConsolidationConfig()
+	if err != nil {
+		return nil, err
+	} else if !cfg.Enabled {
+		return nil, er.New("consolidation is not enabled, see wallet/consolidate/configure")
+	}
+	r.w.TriggerConsolidation()
+	return &rpc_pb.Null{}, nil
+}
+
+func Register(
+	a *apiv1.Apiv1,
+	w *wallet.Wallet,
+) {
+	r := rpc{w: w}
+	apiv1.Endpoint(
+		a,
+		"",
+		`
+		Get the configuration and state of the consolidation service
+
+		Totals count the consolidation transactions which were made since the
+		wallet was started.
+		`,
+		r.status,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		a,
+		"configure",
+		`
+		Configure the consolidation service
+
+		When it is enabled, the consolidation service periodically looks for
+		addresses which have more coins than the threshold and sweeps their coins
+		into a single coin, either at the same address or at to_address. Each
+		transaction spends at most batch_size coins, the smallest coins first, so
+		an address with very many coins is folded over several runs.
+
+		Consolidation only runs while the chain is synced and the wallet is
+		unlocked. Coins which are locked with wallet/unspent/lock/create are never
+		spent and neither are coins of watch-only or multisig accounts. If the
+		fee at the configured fee rate would be more than max_fee_percent of the
+		value of the coins, the address is skipped. If max_sfee_per_kb is set
+		then consolidation waits while the network fee estimate is higher.
+
+		The whole configuration is replaced, fields which are zero take their
+		default values.
+		`,
+		r.configure,
+		help_pb.F_PERM_SPEND,
+	)
+	apiv1.Endpoint(
+		a,
+		"run",
+		`
+		Run the consolidation service now
+
+		The service runs within the next minute rather than waiting for its
+		interval to pass.
+		`,
+		r.run,
+		help_pb.F_PERM_WRITE,
+	)
+}
@@ -1,22 +1,29 @@
 package wallet
 
 import (
+	"bytes"
 	"strings"
 	"time"
 
 	"github.com/pkt-cash/pktd/apiv1/lightning"
+	"github.com/pkt-cash/pktd/apiv1/wallet/account"
 	"github.com/pkt-cash/pktd/apiv1/wallet/address"
+	"github.com/pkt-cash/pktd/apiv1/wallet/consolidate"
+	"github.com/pkt-cash/pktd/apiv1/wallet/multisig"
 	"github.com/pkt-cash/pktd/apiv1/wallet/transaction"
 	"github.com/pkt-cash/pktd/apiv1/wallet/unspent"
+	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/util/mailbox"
 	"github.com/pkt-cash/pktd/generated/proto/meta_pb"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/generated/proto/walletunlocker_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/lnd/lnwallet"
 	"github.com/pkt-cash/pktd/pktlog/log"
 	"github.com/pkt-cash/pktd/pktwallet/wallet"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/seedwords"
 )
 
 type rpc struct {
@@ -72,6 +79,88 @@ func (r *rpc) seed(req *rpc_pb.GetWalletSeedRequest) (*rpc_pb.GetWalletSeedRespo
 	}, nil
 }
 
+func (r *rpc) backup(in *rpc_pb.WalletBackupRequest) (*rpc_pb.WalletBackupResponse, er.R) {
+	pass := []byte(in.Passphrase)
+	if len(in.PassphraseBin) > 0 {
+		pass = in.PassphraseBin
+	}
+	b, err := r.w.Backup(pass)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.WalletBackupResponse{
+		Backup: b,
+	}, nil
+}
+
+func (r *rpc) sweep(in *rpc_pb.SweepRequest) (*rpc_pb.SweepResponse, er.R) {
+	req := wallet.SweepReq{
+		Lookahead:   in.Lookahead,
+		StartHeight: in.StartHeight,
+		FeeSatPerKB: btcutil.Amount(in.SfeePerKb),
+		NoPublish:   in.NoPublish,
+	}
+	for _, k := range in.Wif {
+		wif, err := btcutil.DecodeWIF(k)
+		if err != nil {
+			return nil, err
+		}
+		req.Keys = append(req.Keys, wif)
+	}
+	if len(in.Seed) > 0 {
+		seedEnc, err := seedwords.SeedFromWords(strings.Join(in.Seed, " "))
+		if err != nil {
+			return nil, err
+		}
+		pass := []byte(in.SeedPassphrase)
+		if len(in.SeedPassphraseBin) > 0 {
+			pass = in.SeedPassphraseBin
+		}
+		seed, err := seedEnc.Decrypt(pass, false)
+		if err != nil {
+			return nil, err
+		}
+		defer seed.Zero()
+		req.Seed = seed
+	}
+	if in.ToAddress != "" {
+		addr, err := btcutil.DecodeAddress(in.ToAddress, r.w.ChainParams())
+		if err != nil {
+			return nil, err
+		}
+		req.ToAddress = addr
+	}
+	res, err := r.w.Sweep(req)
+	if err != nil {
+		return nil, err
+	}
+	var txBuf bytes.Buffer
+	if err := res.Tx.Serialize(&txBuf); err != nil {
+		return nil, err
+	}
+	out := &rpc_pb.SweepResponse{
+		Txid:      res.Tx.TxHash().String(),
+		TxBin:     txBuf.Bytes(),
+		Stotal:    int64(res.Total),
+		Sfee:      int64(res.Fee),
+		ToAddress: res.ToAddress,
+		Remaining: int32(res.Remaining),
+		Published: res.Published,
+	}
+	for _, so := range res.Inputs {
+		out.Inputs = append(out.Inputs, &rpc_pb.SweptOutput{
+			Outpoint: &rpc_pb.OutPoint{
+				TxidBytes:   so.OutPoint.Hash[:],
+				TxidStr:     so.OutPoint.Hash.String(),
+				OutputIndex: so.OutPoint.Index,
+			},
+			Address: so.Address,
+			Samount: int64(so.Amount),
+		})
+	}
+	return out, nil
+}
+
 // ChangePassphrase changes the password of the wallet and sends the new password
 // across the UnlockPasswords channel to automatically unlock the wallet if
 // successful.
@@ -221,6 +310,61 @@ func Register(
 			"Detected unspent transactions associated with one of our wallet addresses"),
 		w,
 	)
+	account.Register(
+		apiv1.DefineCategory(walletCat, "account",
+			`
+			Accounts of the wallet, including watch-only accounts
+
+			Each account derives its own chain of addresses. Besides the accounts which
+			derive from the wallet seed, an account can be imported from the extended
+			public key of another wallet, e.g. a cold storage wallet, so that its balance
+			can be followed and unsigned transactions made from it.
+			`,
+		), w)
+	multisig.Register(
+		apiv1.DefineCategory(walletCat, "multisig",
+			`
+			Multisig accounts which are shared by several cosigners
+
+			A multisig account is made from the extended public keys of n cosigners and
+			a threshold m, spending from it needs the signatures of m of them. Spends are
+			made as PSBTs which are passed from cosigner to cosigner to be signed and
+			finalized by the last one.
+			`,
+		), w)
+	consolidate.Register(
+		apiv1.DefineCategory(walletCat, "consolidate",
+			`
+			Automatic consolidation of addresses which have many small coins
+
+			Addresses which receive many payments, e.g. from mining, build up so many
+			coins that spending them needs several large transactions. The consolidation
+			service folds them into fewer coins in the background.
+			`,
+		), w)
+	apiv1.StreamSource(
+		walletCat,
+		"events",
+		`
+		Stream of payments, confirmations and balance changes in the wallet
+
+		An event is sent when a transaction which pays to or spends from the wallet
+		is seen unconfirmed, when it is mined, and when it is unconfirmed because its
+		block was reorged out. Each transaction event shows how it changes the balance
+		of each address and account. An event is also sent for each new block, and if
+		confirmations is set then for each transaction which reaches that depth.
+
+		Every event has a sequence number, to resume after reconnecting pass the
+		sequence and stream_id of the last event received as after_sequence and
+		stream_id. The most recent events are kept in memory, if they are no longer
+		available or pld has restarted, pass from_height to replay the transactions
+		from that height and all unconfirmed transactions. A transaction may be
+		replayed twice if it changes while replaying, so clients should use the txid
+		to identify it.
+		`,
+		w.NtfnServer.WalletEvents,
+		help_pb.F_PERM_READ,
+	)
 	apiv1.Endpoint(
 		walletCat,
 		"unlock",
@@ -228,6 +372,7 @@ func Register(
 		Unlock an encrypted wallet for on-chain transactions.
 		`,
 		r.unlockWallet,
+		help_pb.F_PERM_ADMIN,
 	)
 	apiv1.Endpoint(
 		walletCat,
@@ -237,6 +382,7 @@ func Register(
 		If the lightning daemon has been started then this call will fail.
 		`,
 		r.lockWallet,
+		help_pb.F_PERM_ADMIN,
 	)
 	apiv1.Endpoint(
 		walletCat,
@@ -245,6 +391,7 @@ func Register(
 		Get a secret seed which is generated using the wallet's private key, this can be used as a password for another application
 		`,
 		r.getsecret,
+		help_pb.F_PERM_SECRET,
 	)
 	apiv1.Endpoint(
 		walletCat,
@@ -256,6 +403,24 @@ func Register(
 		ENCRYPTED form (using the wallet passphrase as key). The output is 15 words.
 		`,
 		r.seed,
+		help_pb.F_PERM_SECRET,
+	)
+	apiv1.Endpoint(
+		walletCat,
+		"backup",
+		`
+		Make an encrypted backup of the wallet
+
+		The backup holds the seed, the birthday block, account names, watch-only and
+		multisig accounts, imported private keys and scripts, transaction labels,
+		the address book, locked outpoints and network steward votes. It is
+		encrypted with the wallet passphrase, which must be given and the wallet must
+		be unlocked. The backup is only returned, save it to a file and to restore,
+		run pld --create --restorebackup=<file>, the wallet will only sync from the
+		birthday block.
+		`,
+		r.backup,
+		help_pb.F_PERM_SECRET,
 	)
 	apiv1.Endpoint(
 		walletCat,
@@ -268,6 +433,7 @@ func Register(
 		of the wallet.
 		`,
 		r.balance,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		walletCat,
@@ -279,6 +445,7 @@ func Register(
 		automatically unlock the wallet database if successful.
 		`,
 		r.changepassphrase,
+		help_pb.F_PERM_ADMIN,
 	)
 	apiv1.Endpoint(
 		walletCat,
@@ -289,6 +456,24 @@ func Register(
     	CheckPassword verify that the password in the request is valid for the wallet.
 		`,
 		r.checkpassphrase,
+		help_pb.F_PERM_ADMIN,
+	)
+	apiv1.Endpoint(
+		walletCat,
+		"sweepkeys",
+		`
+		Move every coin of some private keys or another seed into the wallet
+
+		Sweep takes private keys in WIF format, e.g. from a paper wallet, and/or the
+		seed words of another wallet. Their unspent coins are found using the compact
+		block filters and paid to a new address of the wallet in one transaction.
+		Unlike wallet/address/import, the keys are not kept and the wallet does not
+		resync. The search begins at start_height, or at the birthday of the seed,
+		so it can take some time. Unconfirmed coins are not found. If no_publish is
+		set then the signed transaction is returned but not broadcast.
+		`,
+		r.sweep,
+		help_pb.F_PERM_SECRET,
 	)
 
 }
@@ -0,0 +1,295 @@
+package multisig
+
+import (
+	"bytes"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/btcutil/psbt"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/enough"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+type rpc struct {
+	w *wallet.Wallet
+}
+
+func accountInfo(a *waddrmgr.MultisigAccount) *rpc_pb.MultisigAccountInfo {
+	keys := make([]string, 0, len(a.Keys))
+	for _, k := range a.Keys {
+		keys = append(keys, k.String())
+	}
+	return &rpc_pb.MultisigAccountInfo{
+		Name:              a.Name,
+		Threshold:         a.Threshold,
+		CosignerKeys:      keys,
+		GapLimit:          a.GapLimit,
+		NextExternalIndex: a.NextExternalIndex,
+		NextInternalIndex: a.NextInternalIndex,
+	}
+}
+
+func parsePsbt(b []byte) (*psbt.Packet, er.R) {
+	if len(b) == 0 {
+		return nil, er.New("psbt is required")
+	}
+	return psbt.NewFromRawBytes(bytes.NewReader(b), false)
+}
+
+func serializePsbt(packet *psbt.Packet) ([]byte, er.R) {
+	var b bytes.Buffer
+	if err := packet.Serialize(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (r *rpc) list(req *rpc_pb.ListMultisigAccountsRequest) (*rpc_pb.ListMultisigAccountsResponse, er.R) {
+	minconf := req.Minconf
+	if minconf == 0 {
+		minconf = 1
+	}
+	accts, err := r.w.MultisigAccounts(minconf)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*rpc_pb.MultisigAccountInfo, 0, len(accts))
+	for _, a := range accts {
+		ai := accountInfo(&a.MultisigAccount)
+		ai.Stotal = int64(a.Total)
+		ai.Sspendable = int64(a.Spendable)
+		ai.Sunconfirmed = int64(a.Unconfirmed)
+		ai.Outputcount = a.OutputCount
+		out = append(out, ai)
+	}
+	return &rpc_pb.ListMultisigAccountsResponse{Accounts: out}, nil
+}
+
+func (r *rpc) create(req *rpc_pb.CreateMultisigAccountRequest) (*rpc_pb.MultisigAccountInfo, er.R) {
+	if req.Name == "" {
+		return nil, er.New("account name is required")
+	}
+	keys := make([]*hdkeychain.ExtendedKey, 0, len(req.CosignerKeys))
+	for _, k := range req.CosignerKeys {
+		key, err := waddrmgr.ParseCosignerPubKey(k, r.w.ChainParams())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	acct, err := r.w.CreateMultisigAccount(req.Name, req.Threshold, keys, req.GapLimit)
+	if err != nil {
+		return nil, err
+	}
+	return accountInfo(acct), nil
+}
+
+func (r *rpc) address(req *rpc_pb.MultisigAddressRequest) (*rpc_pb.GetNewAddressResponse, er.R) {
+	if req.Name == "" {
+		return nil, er.New("account name is required")
+	}
+	addr, err := r.w.NewMultisigAddress(req.Name, req.Internal)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.GetNewAddressResponse{
+		Address: addr.EncodeAddress(),
+	}, nil
+}
+
+func (r *rpc) fundpsbt(req *rpc_pb.MultisigFundPsbtRequest) (*rpc_pb.MultisigPsbtResponse, er.R) {
+	if req.Name == "" {
+		return nil, er.New("account name is required")
+	}
+	if req.MinConf < 0 {
+		return nil, er.New("minconf must be positive")
+	}
+	var amount btcutil.Amount
+	var err er.R
+	if req.Amount <= 0 {
+		return nil, er.New("amount must be positive")
+	} else if req.Amount >= 6e9 {
+		amount = btcutil.Amount(enough.SweepOutputAmount)
+	} else if amount, err = btcutil.NewAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	to, err := btcutil.DecodeAddress(req.ToAddress, r.w.ChainParams())
+	if err != nil {
+		return nil, er.Errorf("cannot decode address: %s", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(to)
+	if err != nil {
+		return nil, er.Errorf("cannot create txout script: %s", err)
+	}
+
+	packet, err := r.w.FundMultisigPsbt(
+		req.Name,
+		[]*wire.TxOut{wire.NewTxOut(int64(amount), pkScript)},
+		req.MinConf,
+		txrules.DefaultRelayFeePerKb,
+		int(req.MaxInputs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, in := range packet.UnsignedTx.TxIn {
+		r.w.LockOutpoint(in.PreviousOutPoint, req.Autolock)
+	}
+	log.Infof("Created PSBT [%s] from multisig account [%s]",
+		log.Txid(packet.UnsignedTx.TxHash().String()), req.Name)
+
+	b, err := serializePsbt(packet)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.MultisigPsbtResponse{Psbt: b}, nil
+}
+
+func (r *rpc) signpsbt(req *rpc_pb.MultisigPsbtRequest) (*rpc_pb.MultisigPsbtResponse, er.R) {
+	packet, err := parsePsbt(req.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	added, err := r.w.SignMultisigPsbt(packet)
+	if err != nil {
+		return nil, err
+	}
+	b, err := serializePsbt(packet)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_pb.MultisigPsbtResponse{
+		Psbt:            b,
+		SignaturesAdded: int32(added),
+	}, nil
+}
+
+func (r *rpc) finalizepsbt(req *rpc_pb.MultisigPsbtRequest) (*rpc_pb.MultisigFinalizePsbtResponse, er.R) {
+	packet, err := parsePsbt(req.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.w.FinalizePsbt(packet); err != nil {
+		return nil, err
+	}
+	signed, err := serializePsbt(packet)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, err
+	}
+	b := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(b); err != nil {
+		return nil, err
+	}
+	return &rpc_pb.MultisigFinalizePsbtResponse{
+		SignedPsbt: signed,
+		RawFinalTx: b.Bytes(),
+	}, nil
+}
+
+func Register(
+	a *apiv1.Apiv1,
+	w *wallet.Wallet,
+) {
+	r := rpc{w: w}
+	apiv1.Endpoint(
+		a,
+		"",
+		`
+		List the multisig accounts of the wallet with their balances
+
+		Coins which are paid to a multisig account are counted in its balance
+		but they are never spent by wallet/transaction/create, they can only be
+		spent with wallet/multisig/fundpsbt.
+		`,
+		r.list,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		a,
+		"create",
+		`
+		Create an m-of-n multisig account from the extended public keys of the cosigners
+
+		Every cosigner must create the account with the same threshold and the
+		same keys, the order of the keys does not matter. Each address of the
+		account is a P2WSH script made from the keys which are derived from every
+		cosigner key at the same branch and index, so all cosigners see the same
+		addresses. xpub, ypub, zpub, Ypub and Zpub keys are accepted at any
+		depth, for this wallet to sign, one of the keys must be the account key
+		of one of its own accounts, see wallet/account.
+
+		The wallet must be unlocked because the addresses are imported as
+		scripts. Addresses are watched up to the gap limit (default 20) past the
+		last address which was given out, on both the receiving and change
+		branches.
+		`,
+		r.create,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"address",
+		`
+		Generates a new receiving or change address in a multisig account
+
+		The wallet must be unlocked.
+		`,
+		r.address,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"fundpsbt",
+		`
+		Create a PSBT which spends from a multisig account
+
+		Coins are selected from the addresses of the account and change is paid
+		to a new change address of the account. The PSBT carries the witness
+		script and key derivations of every input so that each cosigner can sign
+		it with wallet/multisig/signpsbt. The outputs which it spends are locked
+		with the autolock name so that they will not be spent twice.
+		`,
+		r.fundpsbt,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"signpsbt",
+		`
+		Add this wallet's signatures to a multisig PSBT
+
+		Every input of a multisig account which this wallet holds a key for is
+		signed, the PSBT is returned with the new partial signatures so that it
+		can be passed on to the next cosigner.
+		`,
+		r.signpsbt,
+		help_pb.F_PERM_SPEND,
+	)
+	apiv1.Endpoint(
+		a,
+		"finalizepsbt",
+		`
+		Sign and finalize a PSBT once enough cosigners have signed
+
+		The wallet adds its own signatures and then finalizes every input, an
+		error is returned if any multisig input has fewer signatures than its
+		threshold. The final transaction is returned and can be sent with
+		wallet/transaction/publish.
+		`,
+		r.finalizepsbt,
+		help_pb.F_PERM_SPEND,
+	)
+}
@@ -2,18 +2,21 @@ package transaction
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/pkt-cash/pktd/btcjson"
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/util"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/describetxn"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/pktlog/log"
 	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
 	"github.com/pkt-cash/pktd/pktwallet/wallet"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txexport"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
 	"github.com/pkt-cash/pktd/txscript"
 	"github.com/pkt-cash/pktd/wire"
@@ -196,6 +199,10 @@ func (r *rpc) createTransaction(req *rpc_pb.CreateTransactionRequest) (*rpc_pb.C
 	if req.InputMinHeight > 0 {
 		inputminheight = int(req.InputMinHeight)
 	}
+	coinSelection, err := wallet.ParseCoinSelection(req.CoinSelection)
+	if err != nil {
+		return nil, err
+	}
 	// Create map of address and amount pairs.
 	amount, err := mkAmount(req.Amount)
 	if err != nil {
@@ -215,7 +222,20 @@ func (r *rpc) createTransaction(req *rpc_pb.CreateTransactionRequest) (*rpc_pb.C
 	if req.Unsigned {
 		sendmode = wallet.SendModeUnsigned
 	}
-	tx, err := sendOutputs(r.w, amounts, vote, &fromaddresses, minconf, txrules.DefaultRelayFeePerKb, sendmode, &req.ChangeAddress, inputminheight, maxinputs)
+	if req.Simulate {
+		txr, err := prepareTxReq(r.w, amounts, vote, &fromaddresses, minconf, txrules.DefaultRelayFeePerKb,
+			sendmode, &req.ChangeAddress, inputminheight, maxinputs, coinSelection)
+		if err != nil {
+			return nil, err
+		}
+		sim, err := simulate(r.w, txr)
+		if err != nil {
+			return nil, err
+		}
+		return &rpc_pb.CreateTransactionResponse{Simulation: sim}, nil
+	}
+	tx, err := sendOutputs(r.w, amounts, vote, &fromaddresses, minconf, txrules.DefaultRelayFeePerKb,
+		sendmode, &req.ChangeAddress, inputminheight, maxinputs, coinSelection)
 	if err != nil {
 		return nil, err
 	}
@@ -252,7 +272,8 @@ func (r *rpc) sendvote(req *rpc_pb.SendVoteRequest) (*rpc_pb.SendFromResponse, e
 		return nil, er.Errorf("cannot create voteFor txout script: %s", err)
 	} else if txr, err := prepareTxReq(r.w, map[string]btcutil.Amount{}, nil,
 		&[]string{req.FromAddress}, int32(req.MinConf), txrules.DefaultRelayFeePerKb,
-		wallet.SendModeBcasted, nil, int(req.MinHeight), int(req.MaxInputs)); err != nil {
+		wallet.SendModeBcasted, nil, int(req.MinHeight), int(req.MaxInputs),
+		wallet.CoinSelectionDefault); err != nil {
 		return nil, err
 	} else if vscr, err := mkVoteScript(req.IsCandidate, voteForScript); err != nil {
 		return nil, err
@@ -276,6 +297,10 @@ func (r *rpc) sendFrom(req *rpc_pb.SendFromRequest) (*rpc_pb.SendFromResponse, e
 	if err != nil {
 		return nil, err
 	}
+	coinSelection, err := wallet.ParseCoinSelection(req.CoinSelection)
+	if err != nil {
+		return nil, err
+	}
 	fromaddresses := req.FromAddress
 	minconf := int32(req.MinConf)
 	if minconf < 0 {
@@ -291,7 +316,24 @@ func (r *rpc) sendFrom(req *rpc_pb.SendFromRequest) (*rpc_pb.SendFromResponse, e
 
 	maxinputs := int(req.MaxInputs)
 
-	tx, err := sendPairs(r.w, amounts, &fromaddresses, minconf, txrules.DefaultRelayFeePerKb, maxinputs, minheight)
+	if req.Simulate {
+		vote, err := r.w.NetworkStewardVote(0, waddrmgr.KeyScopeBIP0044)
+		if err != nil {
+			return nil, err
+		}
+		txr, err := prepareTxReq(r.w, amounts, vote, &fromaddresses, minconf, txrules.DefaultRelayFeePerKb,
+			wallet.SendModeBcasted, nil, minheight, maxinputs, coinSelection)
+		if err != nil {
+			return nil, err
+		}
+		sim, err := simulate(r.w, txr)
+		if err != nil {
+			return nil, err
+		}
+		return &rpc_pb.SendFromResponse{Simulation: sim}, nil
+	}
+	tx, err := sendPairs(r.w, amounts, &fromaddresses, minconf, txrules.DefaultRelayFeePerKb, maxinputs, minheight,
+		coinSelection)
 	if err != nil {
 		return nil, err
 	}
@@ -318,6 +360,123 @@ func (r *rpc) publish(in *rpc_pb.PublishTransactionRequest) (*rpc_pb.PublishTran
 	}, nil
 }
 
+func (r *rpc) bumpfee(req *rpc_pb.BumpFeeRequest) (*rpc_pb.FeeBump, er.R) {
+	txid, err := chainhash.NewHashFromStr(req.Txid)
+	if err != nil {
+		return nil, err
+	}
+	bfr := wallet.FeeBumpReq{
+		Txid:        *txid,
+		FeeSatPerKB: btcutil.Amount(req.SfeePerKb),
+		Outputs:     req.Outputs,
+	}
+	if req.ToAddress != "" {
+		addr, err := btcutil.DecodeAddress(req.ToAddress, r.w.ChainParams())
+		if err != nil {
+			return nil, er.Errorf("cannot decode address: %s", err)
+		}
+		bfr.ToAddress = addr
+	}
+	bump, err := r.w.BumpFee(bfr)
+	if err != nil {
+		if waddrmgr.ErrLocked.Is(err) {
+			return nil, er.New("Enter the wallet passphrase with `./bin/pldctl unlock` first")
+		}
+		return nil, err
+	}
+	return bump.Rpc(), nil
+}
+
+func exportFilter(req *rpc_pb.ExportTransactionsRequest) *txexport.Filter {
+	f := &txexport.Filter{
+		Addresses:          req.Addresses,
+		IncludeUnconfirmed: req.IncludeUnconfirmed,
+	}
+	if req.Start > 0 {
+		f.Start = time.Unix(req.Start, 0)
+	}
+	if req.End > 0 {
+		f.End = time.Unix(req.End, 0)
+	}
+	return f
+}
+
+func (r *rpc) export(req *rpc_pb.ExportTransactionsRequest) (*rpc_pb.ExportTransactionsResponse, er.R) {
+	f := exportFilter(req)
+	var b bytes.Buffer
+	w, err := txexport.NewWriter(req.Format, &b, f)
+	if err != nil {
+		return nil, err
+	}
+	count := 0
+	if err := r.w.ExportTransactions(f, func(row *txexport.Row) er.R {
+		count++
+		return w.Write(row)
+	}); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	format := req.Format
+	if format == "" {
+		format = txexport.FormatCSV
+	}
+	return &rpc_pb.ExportTransactionsResponse{
+		Format: format,
+		Data:   b.String(),
+		Count:  int32(count),
+	}, nil
+}
+
+// exportBatch is the number of transactions which an export stream reads at a
+// time, the database is not held open while they are sent so a slow client
+// does not hold up the wallet.
+const exportBatch = 100
+
+func (r *rpc) exportStream(
+	req *rpc_pb.ExportTransactionsRequest,
+	stop <-chan struct{},
+	fail func(err er.R),
+) (<-chan *rpc_pb.ExportedTransaction, er.R) {
+	f := exportFilter(req)
+	out := make(chan *rpc_pb.ExportedTransaction)
+	go func() {
+		defer close(out)
+		var c txexport.Cursor
+		for !c.Done() {
+			var rows []*rpc_pb.ExportedTransaction
+			if err := r.w.ExportTransactionsFrom(f, &c, exportBatch, func(row *txexport.Row) er.R {
+				rows = append(rows, &rpc_pb.ExportedTransaction{
+					Date:         row.Time.Unix(),
+					Txid:         row.Txid,
+					Direction:    row.Direction,
+					Samount:      int64(row.Amount),
+					Sfee:         int64(row.Fee),
+					FeeKnown:     row.FeeKnown,
+					Address:      row.Address,
+					Counterparty: row.Counterparty,
+					Label:        row.Label,
+					Height:       row.Height,
+					Sbalance:     int64(row.Balance),
+				})
+				return nil
+			}); err != nil {
+				fail(err)
+				return
+			}
+			for _, row := range rows {
+				select {
+				case out <- row:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
 func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 	r := rpc{w: w}
 	apiv1.Endpoint(
@@ -331,6 +490,7 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		it will appear as "not found" even if the transaction is real.
 		`,
 		r.getTransaction,
+		help_pb.F_PERM_READ,
 	)
 
 	apiv1.Endpoint(
@@ -344,8 +504,16 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		endpoint. In order to make multiple transactions concurrently, prior to
 		the first transaction being submitted to the chain, you must specify the
 		autolock field.
+
+		The coins to spend are chosen by the coin_selection strategy:
+		branchandbound looks for coins which pay without making change,
+		smallestfirst spends the smallest coins first to consolidate dust,
+		largestfirst spends the fewest coins and privacy never spends coins of
+		more than one address. With simulate, no transaction is made and the
+		response only shows the fee and change which it would have.
 		`,
 		r.createTransaction,
+		help_pb.F_PERM_SPEND,
 	)
 
 	apiv1.Endpoint(
@@ -355,9 +523,11 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		Authors, signs, and sends a transaction which sources funds from specific addresses
 
 		SendFrom authors, signs, and sends a transaction which sources it's funds
-		from specific addresses.
+		from specific addresses. The coin_selection and simulate fields are the
+		same as in wallet/transaction/create.
 		`,
 		r.sendFrom,
+		help_pb.F_PERM_SPEND,
 	)
 
 	apiv1.Endpoint(
@@ -374,6 +544,7 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		on the number of coins this address has.
 		`,
 		r.sendvote,
+		help_pb.F_PERM_SPEND,
 	)
 
 	// TODO(cjd): This is not written right, needs to be addressed
@@ -405,6 +576,7 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		will be missing such as input amounts and fees.
 		`,
 		r.decodeRawTransaction,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		a,
@@ -420,6 +592,7 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		if transactions are missing then a resync may be necessary.
 		`,
 		r.w.GetTransactions1,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		a,
@@ -428,6 +601,60 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		Publish a transaction to the network
 		`,
 		r.publish,
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"bumpfee",
+		`
+		Speed up an unconfirmed transaction by paying more fee with a child transaction
+
+		A child transaction is made which spends the outputs of the stuck
+		transaction, usually its change, back to the wallet. The child pays
+		enough fee that the two together pay sfee_per_kb, so miners who want the
+		child's fee must mine the parent as well (child pays for parent). The fee
+		is paid from the outputs which are spent, so they must be worth more than
+		it. Outputs which are locked with wallet/unspent/lock/create are not
+		spent.
+
+		If the stuck transaction spends coins which are not the wallet's, its fee
+		is not known and the child pays for the whole size of both. The child is
+		broadcast and rebroadcast like any other wallet transaction, it is listed
+		by wallet/transaction/query with the details of the fee bump so whether
+		it is confirmed can be followed there.
+		`,
+		r.bumpfee,
+		help_pb.F_PERM_SPEND,
+	)
+	apiv1.Endpoint(
+		a,
+		"export",
+		`
+		Export the wallet's transaction history as a statement
+
+		Each transaction has its date, txid, direction (receive, send, self or
+		mined), amount, fee, the wallet's address, the counterparty address, label,
+		block height and the running balance. The amount is the change to the
+		balance so for a send it includes the fee. The format is csv, ofx (for
+		accounting software) or jsonl (one JSON object per line).
+
+		start and end limit the statement to a period, the running balance still
+		counts every transaction before it. If addresses are given then only coins
+		which are received by or spent from them are counted. Unconfirmed
+		transactions are left out unless include_unconfirmed is set. When this is
+		requested as a stream, each transaction is sent as it is read.
+		`,
+		r.export,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.StreamSourceWithError(
+		a,
+		"export",
+		`
+		Stream the wallet's transaction history, one transaction per message
+		`,
+		r.exportStream,
+		help_pb.F_PERM_READ,
 	)
 
 }
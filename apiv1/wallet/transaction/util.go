@@ -6,6 +6,7 @@ import (
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/pktlog/log"
 	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
 	"github.com/pkt-cash/pktd/pktwallet/wallet"
@@ -23,14 +24,16 @@ import (
 // It returns the transaction hash in string format upon success
 // All errors are returned in btcjson.RPCError format
 func sendPairs(w *wallet.Wallet, amounts map[string]btcutil.Amount,
-	fromAddressses *[]string, minconf int32, feeSatPerKb btcutil.Amount, maxInputs, inputMinHeight int) (string, er.R) {
+	fromAddressses *[]string, minconf int32, feeSatPerKb btcutil.Amount, maxInputs, inputMinHeight int,
+	coinSelection wallet.CoinSelection) (string, er.R) {
 
 	vote, err := w.NetworkStewardVote(0, waddrmgr.KeyScopeBIP0044)
 	if err != nil {
 		return "", err
 	}
 
-	tx, err := sendOutputs(w, amounts, vote, fromAddressses, minconf, feeSatPerKb, wallet.SendModeBcasted, nil, inputMinHeight, maxInputs)
+	tx, err := sendOutputs(w, amounts, vote, fromAddressses, minconf, feeSatPerKb, wallet.SendModeBcasted, nil,
+		inputMinHeight, maxInputs, coinSelection)
 	if err != nil {
 		return "", err
 	}
@@ -60,6 +63,7 @@ func prepareTxReq(
 	changeAddress *string,
 	inputMinHeight int,
 	maxInputs int,
+	coinSelection wallet.CoinSelection,
 ) (*wallet.CreateTxReq, er.R) {
 	req := wallet.CreateTxReq{
 		Minconf:        minconf,
@@ -68,8 +72,9 @@ func prepareTxReq(
 		InputMinHeight: inputMinHeight,
 		MaxInputs:      maxInputs,
 		Label:          "",
+		CoinSelection:  coinSelection,
 	}
-	if inputMinHeight > 0 {
+	if inputMinHeight > 0 && coinSelection == wallet.CoinSelectionDefault {
 		// TODO(cjd): Ideally we would expose the comparator choice to the
 		// API consumer, but this is an API break. When we're using inputMinHeight
 		// it's normally because we're trying to do multiple createtransaction
@@ -124,9 +129,10 @@ func sendOutputs(
 	changeAddress *string,
 	inputMinHeight int,
 	maxInputs int,
+	coinSelection wallet.CoinSelection,
 ) (*txauthor.AuthoredTx, er.R) {
 	if req, err := prepareTxReq(w, amounts, vote, fromAddressses, minconf, feeSatPerKb,
-		sendMode, changeAddress, inputMinHeight, maxInputs); err != nil {
+		sendMode, changeAddress, inputMinHeight, maxInputs, coinSelection); err != nil {
 		return nil, err
 	} else {
 		return sendTxRequest(w, req)
@@ -150,6 +156,27 @@ func sendTxRequest(w *wallet.Wallet, req *wallet.CreateTxReq) (*txauthor.Authore
 	return tx, nil
 }
 
+// simulate selects the coins for a transaction without making it.
+func simulate(w *wallet.Wallet, req *wallet.CreateTxReq) (*rpc_pb.SimulatedTransaction, er.R) {
+	sim, err := w.SimulateTx(*req)
+	if err != nil {
+		return nil, err
+	}
+	name := string(sim.CoinSelection)
+	if sim.CoinSelection == wallet.CoinSelectionDefault {
+		name = "default"
+	}
+	return &rpc_pb.SimulatedTransaction{
+		CoinSelection: name,
+		InputCount:    int32(sim.InputCount),
+		AddressCount:  int32(sim.AddressCount),
+		SinputTotal:   int64(sim.InputTotal),
+		Sfee:          int64(sim.Fee),
+		Schange:       int64(sim.Change),
+		Vsize:         int32(sim.VSize),
+	}, nil
+}
+
 // makeOutputs creates a slice of transaction outputs from a pair of address
 // strings to amounts.  This is used to create the outputs to include in newly
 // created transactions from a JSON object describing the output destinations
@@ -3,6 +3,7 @@ package lock
 import (
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/pktwallet/wallet"
@@ -84,6 +85,7 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		These are batched by group name.
 		`,
 		r.listlockunspent,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		a,
@@ -96,6 +98,7 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		NOTE: The lock group name "none" is reserved.
 		`,
 		r.lockunspent,
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		a,
@@ -108,6 +111,7 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		word "none" is specified as the lock name, all uncategorized locks will be removed.
 		`,
 		r.unlockunspent,
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		a,
@@ -116,5 +120,6 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		Remove every lock, including all categories.
 		`,
 		r.unlockallunspent,
+		help_pb.F_PERM_WRITE,
 	)
 }
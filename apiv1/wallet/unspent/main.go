@@ -6,11 +6,13 @@ import (
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/util"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/pktwallet/wallet"
 	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
 )
 
 type rpc struct {
@@ -28,6 +30,14 @@ func (r *rpc) listunspent(in *rpc_pb.ListUnspentRequest) (*rpc_pb.ListUnspentRes
 	if err != nil {
 		return nil, err
 	}
+	addrLabels, err := r.w.AddressLabels()
+	if err != nil {
+		return nil, err
+	}
+	opLabels, err := r.w.OutpointLabels()
+	if err != nil {
+		return nil, err
+	}
 
 	witnessOutputs := make([]*rpc_pb.Utxo, 0, len(unspentOutputs))
 	for _, output := range unspentOutputs {
@@ -68,11 +78,15 @@ func (r *rpc) listunspent(in *rpc_pb.ListUnspentRequest) (*rpc_pb.ListUnspentRes
 			//fill the utxo
 			utxo := &rpc_pb.Utxo{
 				AddressType: 	addressType,
+				Address:        output.Address,
 				AmountSat:      int64(amt),
 				Outpoint: &rpc_pb.OutPoint{
 					TxidBytes: txid[:],
 					TxidStr:  output.TxID,
+					OutputIndex: output.Vout,
 				},
+				Label:        opLabels[wire.OutPoint{Hash: *txid, Index: output.Vout}],
+				AddressLabel: addrLabels[output.Address],
 			}
 			witnessOutputs = append(witnessOutputs, utxo)
 		}
@@ -83,6 +97,17 @@ func (r *rpc) listunspent(in *rpc_pb.ListUnspentRequest) (*rpc_pb.ListUnspentRes
 	}, nil
 }
 
+func (r *rpc) label(in *rpc_pb.SetOutPointLabelRequest) (*rpc_pb.Null, er.R) {
+	if in.Outpoint == nil {
+		return nil, er.New("outpoint is required")
+	}
+	txHash, err := chainhash.NewHashFromStr(in.Outpoint.TxidStr)
+	if err != nil {
+		return nil, err
+	}
+	op := wire.OutPoint{Hash: *txHash, Index: in.Outpoint.OutputIndex}
+	return nil, r.w.SetOutpointLabel(op, in.Label)
+}
 
 func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 	r := rpc{w: w}
@@ -112,5 +137,18 @@ func Register(a *apiv1.Apiv1, w *wallet.Wallet) {
 		number of confirmations between the specified minimum and maximum.
 		`,
 		r.listunspent,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		a,
+		"label",
+		`
+		Label an unspent output in the address book
+
+		Unlike the name of a lock, the label is kept when pld restarts. It is shown
+		by wallet/unspent and wallet/transaction/query, an empty label removes it.
+		`,
+		r.label,
+		help_pb.F_PERM_WRITE,
 	)
 }
@@ -0,0 +1,495 @@
+package wallets
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkt-cash/pktd/apiv1/lightning"
+	api_wallet "github.com/pkt-cash/pktd/apiv1/wallet"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/lock"
+	"github.com/pkt-cash/pktd/btcutil/util/mailbox"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/neutrino"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/wallet"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/seedwords"
+)
+
+// Header which selects the wallet that a request to /api/v1/wallet/ is for,
+// browsers which cannot set headers may use ?wallet=<name> instead.
+const walletHeader = "Pld-Wallet"
+
+// The name of the wallet which is stored in wallet.db rather than wallet_<name>.db
+const defaultName = "wallet"
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+// Names of endpoints in the wallets category, which therefore cannot be wallet names
+var reservedNames = map[string]struct{}{
+	"load":   {},
+	"create": {},
+	"unload": {},
+	"select": {},
+}
+
+type loadedWallet struct {
+	name   string
+	file   string
+	loader *wallet.Loader
+	w      *wallet.Wallet
+	cat    *apiv1.Apiv1
+}
+
+type walletsMut struct {
+	// nil while a wallet is being opened so that it cannot be opened twice
+	wallets  map[string]*loadedWallet
+	selected string
+}
+
+// Wallets is the set of wallets which are open in this pld, they all share the
+// one neutrino ChainService so headers and filters are only synced once.
+// The main wallet is the one which pld was started with, it is used by lightning
+// and is always loaded.
+type Wallets struct {
+	cat            *apiv1.Apiv1
+	cs             *neutrino.ChainService
+	params         *chaincfg.Params
+	dir            string
+	noFreelistSync bool
+	main           string
+	m              lock.GenMutex[walletsMut]
+}
+
+func fileOf(name string) string {
+	if name == defaultName {
+		return defaultName + ".db"
+	}
+	return "wallet_" + name + ".db"
+}
+
+func nameOf(file string) (string, bool) {
+	if file == defaultName+".db" {
+		return defaultName, true
+	}
+	if strings.HasPrefix(file, "wallet_") && strings.HasSuffix(file, ".db") {
+		name := strings.TrimSuffix(strings.TrimPrefix(file, "wallet_"), ".db")
+		return name, validName.MatchString(name)
+	}
+	return "", false
+}
+
+func checkName(name string) er.R {
+	if !validName.MatchString(name) {
+		return er.Errorf("Invalid wallet name [%s], names may contain letters, numbers, "+
+			"_ and - and must be no more than 64 characters", name)
+	}
+	if _, ok := reservedNames[name]; ok {
+		return er.Errorf("[%s] cannot be used as a wallet name", name)
+	}
+	return nil
+}
+
+func passphrase(s string, bin []byte) []byte {
+	if len(bin) > 0 {
+		return bin
+	}
+	return []byte(s)
+}
+
+// rewrite sends requests for /api/v1/wallet/ to the wallet which is named in
+// the header, or to the selected wallet if there is no header.
+func (ws *Wallets) rewrite(r *http.Request, path string) (string, er.R) {
+	if path != "wallet" && !strings.HasPrefix(path, "wallet/") {
+		return path, nil
+	}
+	name := r.Header.Get(walletHeader)
+	if name == "" {
+		name = r.URL.Query().Get("wallet")
+	}
+	if err := ws.m.In(func(m *walletsMut) er.R {
+		if name == "" {
+			name = m.selected
+		}
+		if name != "" && m.wallets[name] == nil {
+			return er.Errorf("Wallet [%s] is not loaded", name)
+		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	if name == "" || name == ws.main {
+		return path, nil
+	}
+	target := "wallets/" + name + strings.TrimPrefix(path, "wallet")
+	// Lightning adds endpoints such as wallet/psbt/ and wallet/sweep which
+	// only work with the main wallet, so they have no copy for other wallets.
+	if !apiv1.HasEndpoint(ws.cat, target) && apiv1.HasEndpoint(ws.cat, path) {
+		return "", er.Errorf("[%s] is only available for the main wallet [%s], "+
+			"but wallet [%s] is selected", path, ws.main, name)
+	}
+	return target, nil
+}
+
+func (ws *Wallets) register(lw *loadedWallet, startLightning *mailbox.Mailbox[*lightning.StartLightning]) {
+	lw.cat = apiv1.DefineCategory(ws.cat, lw.name,
+		`
+		The endpoints of /api/v1/wallet/ for one wallet
+		`,
+	)
+	api_wallet.Register(lw.cat, lw.w, startLightning)
+	lw.w.RegisterRpc(lw.cat)
+}
+
+func info(lw *loadedWallet, main, selected string) *rpc_pb.WalletDesc {
+	return &rpc_pb.WalletDesc{
+		Name:         lw.name,
+		File:         lw.file,
+		Loaded:       true,
+		Main:         lw.name == main,
+		Selected:     lw.name == selected || (selected == "" && lw.name == main),
+		Locked:       lw.w.Locked(),
+		SyncedHeight: lw.w.Manager.SyncedTo().Height,
+	}
+}
+
+func (ws *Wallets) info(name string) (*rpc_pb.WalletDesc, er.R) {
+	var out *rpc_pb.WalletDesc
+	if err := ws.m.In(func(m *walletsMut) er.R {
+		if lw := m.wallets[name]; lw != nil {
+			out = info(lw, ws.main, m.selected)
+			return nil
+		}
+		return er.Errorf("Wallet [%s] is not loaded", name)
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (ws *Wallets) list(*rpc_pb.Null) (*rpc_pb.ListWalletsResponse, er.R) {
+	out := &rpc_pb.ListWalletsResponse{}
+	ws.m.In(func(m *walletsMut) er.R {
+		for _, lw := range m.wallets {
+			if lw != nil {
+				out.Wallets = append(out.Wallets, info(lw, ws.main, m.selected))
+			}
+		}
+		return nil
+	})
+	loaded := make(map[string]struct{})
+	for _, wi := range out.Wallets {
+		loaded[wi.Name] = struct{}{}
+	}
+	entries, errr := os.ReadDir(ws.dir)
+	if errr != nil {
+		return nil, er.E(errr)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if name, ok := nameOf(e.Name()); ok {
+			if _, ok := loaded[name]; !ok {
+				out.Wallets = append(out.Wallets, &rpc_pb.WalletDesc{
+					Name: name,
+					File: e.Name(),
+				})
+			}
+		}
+	}
+	sort.Slice(out.Wallets, func(i, j int) bool {
+		return out.Wallets[i].Name < out.Wallets[j].Name
+	})
+	return out, nil
+}
+
+// open reserves the name and then opens the wallet using open, the lock is not
+// held while the wallet is opening because that can take some time.
+func (ws *Wallets) open(
+	name string,
+	open func(loader *wallet.Loader) (*wallet.Wallet, er.R),
+) (*loadedWallet, er.R) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	if err := ws.m.In(func(m *walletsMut) er.R {
+		if _, ok := m.wallets[name]; ok {
+			return er.Errorf("Wallet [%s] is already loaded", name)
+		}
+		m.wallets[name] = nil
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	lw := &loadedWallet{
+		name:   name,
+		file:   fileOf(name),
+		loader: wallet.NewLoader(ws.params, ws.dir, fileOf(name), ws.noFreelistSync, 256),
+	}
+	w, err := open(lw.loader)
+	if err != nil {
+		ws.m.In(func(m *walletsMut) er.R {
+			delete(m.wallets, name)
+			return nil
+		})
+		return nil, err
+	}
+	lw.w = w
+	w.SynchronizeSharedRPC(ws.cs)
+	// This wallet is not used by lightning so it can always be locked
+	noLightning := mailbox.NewMailbox[*lightning.StartLightning](nil)
+	ws.register(lw, &noLightning)
+	ws.m.In(func(m *walletsMut) er.R {
+		m.wallets[name] = lw
+		return nil
+	})
+	log.Infof("Loaded wallet [%s] from [%s]", name, lw.file)
+	return lw, nil
+}
+
+func (ws *Wallets) load(req *rpc_pb.WalletNameRequest) (*rpc_pb.WalletDesc, er.R) {
+	if _, err := ws.open(req.Name, func(loader *wallet.Loader) (*wallet.Wallet, er.R) {
+		if exists, err := loader.WalletExists(); err != nil {
+			return nil, err
+		} else if !exists {
+			return nil, er.Errorf("There is no wallet file [%s]", fileOf(req.Name))
+		}
+		return loader.OpenExistingWallet([]byte(wallet.InsecurePubPassphrase), false, nil)
+	}); err != nil {
+		return nil, err
+	}
+	return ws.info(req.Name)
+}
+
+func (ws *Wallets) create(req *rpc_pb.CreateWalletRequest) (*rpc_pb.CreateWalletResponse, er.R) {
+	privPass := passphrase(req.WalletPassphrase, req.WalletPassphraseBin)
+	if len(privPass) == 0 {
+		return nil, er.New("A wallet passphrase is required")
+	}
+	out := &rpc_pb.CreateWalletResponse{}
+	var seed *seedwords.Seed
+	if len(req.WalletSeed) > 0 {
+		seedEnc, err := seedwords.SeedFromWords(strings.Join(req.WalletSeed, " "))
+		if err != nil {
+			return nil, err
+		}
+		seedPass := passphrase(req.SeedPassphrase, req.SeedPassphraseBin)
+		if len(seedPass) == 0 && seedEnc.NeedsPassphrase() {
+			return nil, er.New("The provided seed requires a passphrase")
+		}
+		if seed, err = seedEnc.Decrypt(seedPass, false); err != nil {
+			return nil, err
+		}
+	} else {
+		s, err := seedwords.RandomSeed()
+		if err != nil {
+			return nil, err
+		}
+		seed = s
+		// Like pld --create, the seed is given back encrypted with the wallet passphrase
+		seedEnc := seed.Encrypt(privPass)
+		words, err := seedEnc.Words("english")
+		seedEnc.Zero()
+		if err != nil {
+			return nil, err
+		}
+		out.Seed = strings.Split(words, " ")
+	}
+	defer seed.Zero()
+
+	if _, err := ws.open(req.Name, func(loader *wallet.Loader) (*wallet.Wallet, er.R) {
+		return loader.CreateNewWallet(
+			[]byte(wallet.InsecurePubPassphrase), privPass, nil, time.Now(), seed, nil)
+	}); err != nil {
+		return nil, err
+	}
+	wi, err := ws.info(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	out.Wallet = wi
+	return out, nil
+}
+
+func (ws *Wallets) unloadWallet(name string) er.R {
+	var lw *loadedWallet
+	if err := ws.m.In(func(m *walletsMut) er.R {
+		if name == ws.main {
+			return er.Errorf("Wallet [%s] is the main wallet, it cannot be unloaded", name)
+		}
+		lw = m.wallets[name]
+		if lw == nil {
+			return er.Errorf("Wallet [%s] is not loaded", name)
+		}
+		delete(m.wallets, name)
+		if m.selected == name {
+			m.selected = ""
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := apiv1.DeregisterCategory(lw.cat); err != nil {
+		log.Warnf("Unable to remove endpoints of wallet [%s]: [%s]", name, err)
+	}
+	if err := lw.loader.UnloadWallet(); err != nil {
+		return err
+	}
+	log.Infof("Unloaded wallet [%s]", name)
+	return nil
+}
+
+func (ws *Wallets) unload(req *rpc_pb.WalletNameRequest) (*rpc_pb.Null, er.R) {
+	return nil, ws.unloadWallet(req.Name)
+}
+
+func (ws *Wallets) selectWallet(req *rpc_pb.WalletNameRequest) (*rpc_pb.WalletDesc, er.R) {
+	name := req.Name
+	if name == "" {
+		name = ws.main
+	}
+	if err := ws.m.In(func(m *walletsMut) er.R {
+		if m.wallets[name] == nil {
+			return er.Errorf("Wallet [%s] is not loaded", name)
+		}
+		m.selected = name
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ws.info(name)
+}
+
+// Stop unloads every wallet other than the main wallet
+func (ws *Wallets) Stop() {
+	var names []string
+	ws.m.In(func(m *walletsMut) er.R {
+		for name, lw := range m.wallets {
+			if lw != nil && name != ws.main {
+				names = append(names, name)
+			}
+		}
+		return nil
+	})
+	for _, name := range names {
+		if err := ws.unloadWallet(name); err != nil {
+			log.Warnf("Unable to unload wallet [%s]: [%s]", name, err)
+		}
+	}
+}
+
+// Register makes the wallets endpoints and causes requests to /api/v1/wallet/
+// to go to the wallet which they select. mainWallet is the wallet which pld was
+// started with, which is in the file mainFile in dir, other wallets are loaded
+// from the same directory.
+func Register(
+	a *apiv1.Apiv1,
+	mainWallet *wallet.Wallet,
+	cs *neutrino.ChainService,
+	dir string,
+	mainFile string,
+	noFreelistSync bool,
+	startLightning *mailbox.Mailbox[*lightning.StartLightning],
+) *Wallets {
+	mainName, ok := nameOf(mainFile)
+	if !ok {
+		mainName = strings.TrimSuffix(mainFile, ".db")
+	}
+	ws := &Wallets{
+		cat: apiv1.DefineCategory(a, "wallets",
+			`
+			Load, create and switch between multiple wallets
+
+			Every loaded wallet shares the same neutrino chain so block headers and
+			filters are only synced once. The endpoints of each wallet are at
+			/api/v1/wallets/<name>/, and a request to /api/v1/wallet/ can be sent to a
+			wallet by setting the Pld-Wallet header to its name. Requests with no
+			header go to the selected wallet, which is the main wallet unless another
+			has been selected. Lightning always uses the main wallet, the wallet which
+			pld was started with, so the endpoints which lightning adds to
+			/api/v1/wallet/, such as wallet/psbt/ and wallet/sweep, fail with an
+			error when another wallet is selected.
+			`,
+		),
+		cs:             cs,
+		params:         mainWallet.ChainParams(),
+		dir:            dir,
+		noFreelistSync: noFreelistSync,
+		main:           mainName,
+	}
+	mainLw := &loadedWallet{name: mainName, file: mainFile, w: mainWallet}
+	ws.m = lock.NewGenMutex(walletsMut{
+		wallets: map[string]*loadedWallet{mainName: mainLw},
+	}, "wallets")
+	ws.register(mainLw, startLightning)
+	a.UseRewrite(ws.rewrite)
+
+	apiv1.Endpoint(
+		ws.cat,
+		"",
+		`
+		List the wallets
+
+		Every loaded wallet is listed along with each wallet file in the wallet
+		directory which can be loaded.
+		`,
+		ws.list,
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		ws.cat,
+		"load",
+		`
+		Load a wallet from the file wallet_<name>.db
+
+		The wallet is synced with the chain in the background, it must be unlocked
+		before it can be spent from.
+		`,
+		ws.load,
+		help_pb.F_PERM_ADMIN,
+	)
+	apiv1.Endpoint(
+		ws.cat,
+		"create",
+		`
+		Create and load a new wallet
+
+		If no seed is given then a new one is made and returned, encrypted with
+		the wallet passphrase. The wallet is stored as wallet_<name>.db.
+		`,
+		ws.create,
+		help_pb.F_PERM_ADMIN,
+	)
+	apiv1.Endpoint(
+		ws.cat,
+		"unload",
+		`
+		Stop and close a wallet
+
+		The main wallet cannot be unloaded because it is used by lightning.
+		`,
+		ws.unload,
+		help_pb.F_PERM_ADMIN,
+	)
+	apiv1.Endpoint(
+		ws.cat,
+		"select",
+		`
+		Select the wallet which is used by /api/v1/wallet/
+
+		Requests which do not have a Pld-Wallet header go to this wallet,
+		an empty name selects the main wallet.
+		`,
+		ws.selectWallet,
+		help_pb.F_PERM_ADMIN,
+	)
+	return ws
+}
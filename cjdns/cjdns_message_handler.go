@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/pktlog/log"
@@ -666,6 +667,7 @@ func (c *Cjdns) registerRpc() {
 		func(req *rpc_pb.CjdnsPingRequest) (*rpc_pb.CjdnsPingResponse, er.R) {
 			return c.PingCjdns(req)
 		},
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		cjdnsCategory,
@@ -676,6 +678,7 @@ func (c *Cjdns) registerRpc() {
 		func(req *rpc_pb.CjdnsPaymentInvoiceRequest) (*rpc_pb.CjdnsPaymentInvoiceResponse, er.R) {
 			return c.CjdnsInvoiceRequest(req)
 		},
+		help_pb.F_PERM_WRITE,
 	)
 }
 
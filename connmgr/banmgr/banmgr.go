@@ -125,7 +125,7 @@ func (b *BanMgr) AddBanScore(host string, persistent, transient uint32, reason s
 	}
 
 	if b.suspicious == nil {
-		log.Debugf("Misbehaving peer %s: %s and no ban manager yet")
+		log.Debugf("Misbehaving peer %s: %s and no ban manager yet", ip, reason)
 		return false
 	}
 	b.suspicious[ip] = SuspiciousPeers{
@@ -159,3 +159,29 @@ func (b *BanMgr) AddBanScore(host string, persistent, transient uint32, reason s
 	}
 	return false
 }
+
+// Ban bans the host for the given duration, replacing any existing ban of it.
+// Whitelisted hosts are still banned because this is an explicit request.
+func (b *BanMgr) Ban(host string, duration time.Duration, reason string) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	ip := TrimAddress(host)
+	log.Infof("Banning peer %s for %v: %s", ip, duration, reason)
+	b.banned[ip] = BannedPeers{time.Now().Add(duration), reason}
+}
+
+// Unban removes the ban and the ban score of the host, it returns false if the
+// host was neither banned nor suspicious.
+func (b *BanMgr) Unban(host string) bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+	ip := TrimAddress(host)
+	_, banned := b.banned[ip]
+	_, suspicious := b.suspicious[ip]
+	delete(b.banned, ip)
+	delete(b.suspicious, ip)
+	if banned || suspicious {
+		log.Infof("Peer %s is no longer banned", ip)
+	}
+	return banned || suspicious
+}
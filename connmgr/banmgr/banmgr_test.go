@@ -0,0 +1,41 @@
+package banmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+)
+
+// TestBanUnban tests that explicit bans are enforced, listed and removed.
+func TestBanUnban(t *testing.T) {
+	b := New(&Config{BanThreashold: 100})
+	b.Ban("10.1.2.3:64764", time.Hour, "stuck")
+	if !b.IsBanned("10.1.2.3:1234") {
+		t.Fatalf("Peer should be banned on any port")
+	}
+	var bans []BanInfo
+	if err := b.ForEachIp(func(bi BanInfo) er.R {
+		bans = append(bans, bi)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(bans) != 1 || bans[0].Addr != "10.1.2.3" || bans[0].Reason != "stuck" {
+		t.Fatalf("Unexpected bans %v", bans)
+	}
+	if !b.Unban("10.1.2.3") {
+		t.Fatalf("Unban should find the ban")
+	}
+	if b.IsBanned("10.1.2.3") {
+		t.Fatalf("Peer should not be banned after unban")
+	}
+	if b.Unban("10.1.2.3") {
+		t.Fatalf("Second unban should find nothing")
+	}
+
+	b.Ban("10.1.2.4", -time.Second, "expired")
+	if b.IsBanned("10.1.2.4") {
+		t.Fatalf("Expired ban should not be enforced")
+	}
+}
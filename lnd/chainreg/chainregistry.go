@@ -330,6 +330,13 @@ func NewChainControl(cfg *Config, api *apiv1.Apiv1) (*ChainControl, er.R) {
 	if err := cc.FeeEstimator.Start(); err != nil {
 		return nil, err
 	}
+	cfg.Wallet.SetFeeEstimator(func() (btcutil.Amount, er.R) {
+		feeRate, err := cc.FeeEstimator.EstimateFeePerKW(6)
+		if err != nil {
+			return 0, err
+		}
+		return btcutil.Amount(feeRate.FeePerKVByte()), nil
+	})
 
 	wc, err := btcwallet.New(*walletConfig, api)
 	if err != nil {
@@ -28,7 +28,7 @@ func bright(str string) string {
 //	show a fancy output for the master help
 func getMasterHelp(pldServer string) er.R {
 
-	response, errr := http.Get(pldServer + "/api/v1/help")
+	response, errr := http.DefaultClient.Get(pldServer + "/api/v1/help")
 	if errr != nil {
 		return er.New("Fail executing pld command: " + errr.Error())
 	}
@@ -156,6 +156,13 @@ func printCommandHelp(endpointHelp *help_pb.EndpointHelp) er.R {
 	}
 	fmt.Fprintf(os.Stdout, "\n")
 
+	//	show the permission which a token needs in order to call the command
+	for _, feature := range endpointHelp.Features {
+		if strings.HasPrefix(feature.String(), "PERM_") {
+			fmt.Fprintf(os.Stdout, "PERMISSION: %s\n\n", strings.ToLower(strings.TrimPrefix(feature.String(), "PERM_")))
+		}
+	}
+
 	if len(endpointHelp.Request.Fields) > 0 {
 		fmt.Fprintf(os.Stdout, "OPTIONS:\n")
 		for _, requestField := range endpointHelp.Request.Fields {
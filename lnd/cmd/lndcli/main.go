@@ -7,6 +7,8 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -32,14 +34,22 @@ func main() {
 
 func main1() er.R {
 	var showRequestPayload bool
+	var token, tokenFile, tlsCertPath string
 	pldServer := "http://localhost:53199"
 
 	//	parse command line arguments
 	flag.StringVar(&pldServer, "pld_server", "http://localhost:53199", "set the pld server URL")
 	flag.BoolVar(&showRequestPayload, "show_req_payload", false, "show the request payload before invoke the pld command")
+	flag.StringVar(&token, "token", "", "REST API token, needed if pld was started with --restauth")
+	flag.StringVar(&tokenFile, "tokenfile", "", "read the REST API token from a file, e.g. ~/.pktwallet/pkt/admin.token")
+	flag.StringVar(&tlsCertPath, "tlscertpath", "", "trust this certificate when connecting to pld over https")
 
 	flag.Parse()
 
+	if err := setupHttpClient(token, tokenFile, tlsCertPath); err != nil {
+		return err
+	}
+
 	//	if a protocol is missing from pld_server, assume HTTP as default
 	if !strings.HasPrefix(pldServer, "http://") && !strings.HasPrefix(pldServer, "https://") {
 		pldServer = "http://" + pldServer
@@ -339,6 +349,47 @@ func formatRequestField(fieldHierarchy string, requestField *help_pb.Field, argu
 	return formattedField, nil
 }
 
+//	adds the REST API token to every request
+type tokenTransport struct {
+	token string
+	inner http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.inner.RoundTrip(req)
+}
+
+//	configure the default HTTP client with the token and TLS certificate, if any
+func setupHttpClient(token, tokenFile, tlsCertPath string) er.R {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsCertPath != "" {
+		certBytes, err := os.ReadFile(tlsCertPath)
+		if err != nil {
+			return er.Errorf("unable to read TLS certificate: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(certBytes) {
+			return er.Errorf("no certificate found in [%s]", tlsCertPath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	if token == "" && tokenFile != "" {
+		tokenBytes, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return er.Errorf("unable to read token file: %s", err)
+		}
+		token = strings.TrimSpace(string(tokenBytes))
+	}
+	var rt http.RoundTripper = transport
+	if token != "" {
+		rt = &tokenTransport{token: token, inner: transport}
+	}
+	http.DefaultClient = &http.Client{Transport: rt}
+	return nil
+}
+
 //	invoke pld's REST endpoint and try to parse error messages eventually returned by the server
 func executeCommand(pldServer string, command string, payload string) er.R {
 
@@ -349,12 +400,12 @@ func executeCommand(pldServer string, command string, payload string) er.R {
 
 	//	if there's no payload, use HTTP GET method to invoke pld command, otherwise use POST method
 	if len(payload) == 0 {
-		response, errr = http.Get(commandURI)
+		response, errr = http.DefaultClient.Get(commandURI)
 		if errr != nil {
 			return er.New("fail executing pld command: " + errr.Error())
 		}
 	} else {
-		response, errr = http.Post(commandURI, "application/json", strings.NewReader(payload))
+		response, errr = http.DefaultClient.Post(commandURI, "application/json", strings.NewReader(payload))
 		if errr != nil {
 			return er.New("fail executing pld command: " + errr.Error())
 		}
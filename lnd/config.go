@@ -54,6 +54,10 @@ const (
 	defaultTowerSubDirname = "watchtower"
 	defaultLogLevel        = "info"
 	defaultRESTPort        = 53199
+	defaultTLSCertFilename = "tls.cert"
+	defaultTLSKeyFilename  = "tls.key"
+	defaultRestTokensFile  = "rest_tokens.json"
+	defaultAdminTokenFile  = "admin.token"
 	defaultPeerPort        = 9735
 
 	defaultNoSeedBackup                  = false
@@ -151,13 +155,14 @@ var (
 type Config struct {
 	ShowVersion bool `short:"V" long:"version" description:"Display version information and exit"`
 
-	LndDir       string `long:"lnddir" description:"The base directory that contains lnd's data, logs, configuration file, etc."`
-	PktDir       string `long:"pktdir" description:"The base directory that contains pktwallet's data etc."`
-	ConfigFile   string `short:"C" long:"configfile" description:"Path to configuration file"`
-	DataDir      string `short:"b" long:"datadir" description:"The directory to store pld's data within"`
-	WalletFile   string `long:"wallet" description:"Wallet file name or path, if a simple word such as 'personal' then pktwallet will look for wallet_personal.db, if prefixed with a / then pktwallet will consider it an absolute path. (default: wallet.db)"`
-	SyncFreelist bool   `long:"sync-freelist" description:"Whether the databases used within pld should sync their freelist to disk. This is disabled by default resulting in improved memory performance during operation, but with an increase in startup time."`
-	Create       bool   `long:"create" description:"Create a new wallet, walking through the steps to do so"`
+	LndDir        string `long:"lnddir" description:"The base directory that contains lnd's data, logs, configuration file, etc."`
+	PktDir        string `long:"pktdir" description:"The base directory that contains pktwallet's data etc."`
+	ConfigFile    string `short:"C" long:"configfile" description:"Path to configuration file"`
+	DataDir       string `short:"b" long:"datadir" description:"The directory to store pld's data within"`
+	WalletFile    string `long:"wallet" description:"Wallet file name or path, if a simple word such as 'personal' then pktwallet will look for wallet_personal.db, if prefixed with a / then pktwallet will consider it an absolute path. (default: wallet.db)"`
+	SyncFreelist  bool   `long:"sync-freelist" description:"Whether the databases used within pld should sync their freelist to disk. This is disabled by default resulting in improved memory performance during operation, but with an increase in startup time."`
+	Create        bool   `long:"create" description:"Create a new wallet, walking through the steps to do so"`
+	RestoreBackup string `long:"restorebackup" description:"With --create, restore the wallet from a backup file which was made by /wallet/backup, the passphrase must be the one the wallet had when the backup was made"`
 
 	// We'll parse these 'raw' string arguments into real net.Addrs in the
 	// loadConfig function. We need to expose the 'raw' strings so the
@@ -177,6 +182,15 @@ type Config struct {
 	MaxBackoff        time.Duration `long:"maxbackoff" description:"Longest backoff when reconnecting to persistent peers. Valid time units are {s, m, h}."`
 	ConnectionTimeout time.Duration `long:"connectiontimeout" description:"The timeout value for network connections. Valid time units are {ms, s, m, h}."`
 
+	RestAuth           bool     `long:"restauth" description:"Require a bearer token for every REST request, an admin token is written to admin.token in the data directory on first start"`
+	RestTLS            bool     `long:"resttls" description:"Serve the REST API over TLS, a self-signed certificate is generated if one does not exist"`
+	TLSCertPath        string   `long:"tlscertpath" description:"Path to the TLS certificate for the REST API (default: <lnddir>/tls.cert)"`
+	TLSKeyPath         string   `long:"tlskeypath" description:"Path to the TLS private key for the REST API (default: <lnddir>/tls.key)"`
+	TLSExtraIPs        []string `long:"tlsextraip" description:"Adds an extra ip to the generated certificate"`
+	TLSExtraDomains    []string `long:"tlsextradomain" description:"Adds an extra domain to the generated certificate"`
+	TLSAutoRefresh     bool     `long:"tlsautorefresh" description:"Re-generate the TLS certificate if the IPs or domains it covers have changed"`
+	TLSDisableAutofill bool     `long:"tlsdisableautofill" description:"Do not include the interface IPs or the system hostname in the generated TLS certificate"`
+
 	DebugLevel string `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <global-level>,<subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 
 	CPUProfile string `long:"cpuprofile" description:"Write CPU profile to the specified file"`
@@ -552,6 +566,14 @@ func ValidateConfig(cfg Config, usageMessage string) (*Config, er.R) {
 	cfg.Tor.PrivateKeyPath = CleanAndExpandPath(cfg.Tor.PrivateKeyPath)
 	cfg.Tor.WatchtowerKeyPath = CleanAndExpandPath(cfg.Tor.WatchtowerKeyPath)
 	cfg.Watchtower.TowerDir = CleanAndExpandPath(cfg.Watchtower.TowerDir)
+	if cfg.TLSCertPath == "" {
+		cfg.TLSCertPath = filepath.Join(lndDir, defaultTLSCertFilename)
+	}
+	if cfg.TLSKeyPath == "" {
+		cfg.TLSKeyPath = filepath.Join(lndDir, defaultTLSKeyFilename)
+	}
+	cfg.TLSCertPath = CleanAndExpandPath(cfg.TLSCertPath)
+	cfg.TLSKeyPath = CleanAndExpandPath(cfg.TLSKeyPath)
 
 	// Create the lnd directory and all other sub directories if they don't
 	// already exist. This makes sure that directory trees are also created
@@ -692,6 +714,18 @@ func ValidateConfig(cfg Config, usageMessage string) (*Config, er.R) {
 	}
 	cfg.Tor.Control = control.String()
 
+	// The neutrino proxy defaults to the Tor SOCKS port if no port is given.
+	if cfg.NeutrinoMode.Proxy != "" {
+		proxy, err := lncfg.ParseAddressString(
+			cfg.NeutrinoMode.Proxy, strconv.Itoa(defaultTorSOCKSPort),
+			cfg.net.ResolveTCPAddr,
+		)
+		if err != nil {
+			return nil, err
+		}
+		cfg.NeutrinoMode.Proxy = proxy.String()
+	}
+
 	// Ensure that tor socks host:port is not equal to tor control
 	// host:port. This would lead to lnd not starting up properly.
 	if cfg.Tor.SOCKS == cfg.Tor.Control {
@@ -1204,6 +1238,22 @@ type WalletSetupCfg struct {
 	PublicPassphrase *string `json:"viewpassphrase"`
 	Seed             *string `json:"seed"`
 	SeedPassphrase   *string `json:"seedpassphrase"`
+
+	// SeedShares are shares of the seed which were made by
+	// /util/seed/split, they are used instead of Seed.
+	SeedShares []string `json:"seedshares"`
+}
+
+func decryptSetupSeed(seedEnc *seedwords.SeedEnc, passphrase *string) (*seedwords.Seed, er.R) {
+	defer seedEnc.Zero()
+	if passphrase == nil && seedEnc.NeedsPassphrase() {
+		return nil, er.New("The provided seed requires a passphrase")
+	}
+	var bs []byte
+	if passphrase != nil {
+		bs = []byte(*passphrase)
+	}
+	return seedEnc.Decrypt(bs, false)
 }
 
 // createWallet prompts the user for information needed to generate a new wallet
@@ -1256,7 +1306,24 @@ func createWallet(cfg *Config) er.R {
 		if setupCfg.PublicPassphrase != nil {
 			pubPass = []byte(*setupCfg.PublicPassphrase)
 		}
-		if setupCfg.Seed != nil {
+		if len(setupCfg.SeedShares) > 0 {
+			shares := make([]*seedwords.Share, 0, len(setupCfg.SeedShares))
+			for _, words := range setupCfg.SeedShares {
+				sh, err := seedwords.ShareFromWords(words)
+				if err != nil {
+					return err
+				}
+				defer sh.Zero()
+				shares = append(shares, sh)
+			}
+			seedEnc, err := seedwords.CombineShares(shares)
+			if err != nil {
+				return err
+			}
+			if seed, err = decryptSetupSeed(seedEnc, setupCfg.SeedPassphrase); err != nil {
+				return err
+			}
+		} else if setupCfg.Seed != nil {
 			if decoded, err := hex.DecodeString(*setupCfg.Seed); err == nil {
 				zero.Bytes(decoded)
 				seedInput = []byte(*setupCfg.Seed)
@@ -1265,18 +1332,8 @@ func createWallet(cfg *Config) er.R {
 				if err != nil {
 					return err
 				}
-				var bs []byte
-				if setupCfg.SeedPassphrase != nil {
-					bs = []byte(*setupCfg.SeedPassphrase)
-				}
-				if setupCfg.SeedPassphrase != nil || !seedEnc.NeedsPassphrase() {
-					s, err := seedEnc.Decrypt(bs, false)
-					if err != nil {
-						return err
-					}
-					seed = s
-				} else {
-					return er.New("The provided seed requires a passphrase")
+				if seed, err = decryptSetupSeed(seedEnc, setupCfg.SeedPassphrase); err != nil {
+					return err
 				}
 			}
 		} else {
@@ -1344,10 +1401,34 @@ func createWallet(cfg *Config) er.R {
 		})
 	}
 
+	// When restoring from a backup, the seed comes from the backup and it is
+	// encrypted with the same passphrase as the rest of the backup.
+	var backup *wallet.WalletBackup
+	if cfg.RestoreBackup != "" {
+		b, errr := ioutil.ReadFile(cfg.RestoreBackup)
+		if errr != nil {
+			return er.E(errr)
+		}
+		bk, err := wallet.DecryptBackup(b, privPass)
+		if err != nil {
+			return err
+		}
+		sd, err := bk.DecryptSeed(privPass)
+		if err != nil {
+			return err
+		}
+		if seed != nil {
+			seed.Zero()
+		}
+		seed = sd
+		seedInput = nil
+		backup = bk
+	}
+
 	// Ascertain the wallet generation seed.  This will either be an
 	// automatically generated value the user has already confirmed or a
 	// value the user has entered which has already been validated.
-	if tty {
+	if tty && backup == nil {
 		si, sd, err := prompt.Seed(reader, privPass)
 		if err != nil {
 			return err
@@ -1364,6 +1445,21 @@ func createWallet(cfg *Config) er.R {
 		return werr
 	}
 
+	if backup != nil {
+		if tty {
+			fmt.Println("Restoring the backup...")
+		}
+		lockChan := make(chan time.Time, 1)
+		if err := w.Unlock(privPass, lockChan); err != nil {
+			return err
+		}
+		err := w.RestoreBackup(backup)
+		lockChan <- time.Time{}
+		if err != nil {
+			return err
+		}
+	}
+
 	w.Manager.Close()
 	if tty {
 		fmt.Println("The wallet has been created successfully.")
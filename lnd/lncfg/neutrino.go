@@ -5,14 +5,18 @@ import "time"
 // Neutrino holds the configuration options for the daemon's connection to
 // neutrino.
 type Neutrino struct {
-	AddPeers           []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
-	ConnectPeers       []string      `long:"connect" description:"Connect only to the specified peers at startup"`
-	MaxPeers           int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
-	BanDuration        time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
-	BanThreshold       uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
-	FeeURL             string        `long:"feeurl" description:"DEPRECATED: Optional URL for fee estimation. If a URL is not specified, static fees will be used for estimation."`
-	AssertFilterHeader string        `long:"assertfilterheader" description:"Optional filter header in height:hash format to assert the state of neutrino's filter header chain on startup. If the assertion does not hold, then the filter header chain will be re-synced from the genesis block."`
-	UserAgentName      string        `long:"useragentname" description:"Used to help identify ourselves to other bitcoin peers"`
-	UserAgentVersion   string        `long:"useragentversion" description:"Used to help identify ourselves to other bitcoin peers"`
-	CheckConectivity   bool          `long:"checkconectivity" description:"Force a CheckConectivity at startup"`
+	AddPeers             []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
+	ConnectPeers         []string      `long:"connect" description:"Connect only to the specified peers at startup"`
+	MaxPeers             int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
+	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
+	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
+	FeeURL               string        `long:"feeurl" description:"DEPRECATED: Optional URL for fee estimation. If a URL is not specified, static fees will be used for estimation."`
+	AssertFilterHeader   string        `long:"assertfilterheader" description:"Optional filter header in height:hash format to assert the state of neutrino's filter header chain on startup. If the assertion does not hold, then the filter header chain will be re-synced from the genesis block."`
+	UserAgentName        string        `long:"useragentname" description:"Used to help identify ourselves to other bitcoin peers"`
+	UserAgentVersion     string        `long:"useragentversion" description:"Used to help identify ourselves to other bitcoin peers"`
+	CheckConectivity     bool          `long:"checkconectivity" description:"Force a CheckConectivity at startup"`
+	Proxy                string        `long:"proxy" description:"Connect to peers through this SOCKS5 proxy, given as host:port, for example Tor at localhost:9050. DNS seeds are resolved through the proxy and .onion peers are allowed, both need the proxy to be Tor. Not needed if tor.active is set, then peers are connected through Tor already."`
+	ProxyStreamIsolation bool          `long:"proxystreamisolation" description:"Use a separate Tor circuit for each peer by randomizing the proxy credentials of each connection."`
+	HeaderSnapshot       string        `long:"headersnapshot" description:"Optional header snapshot file, made with wallettool exportheaders, to import block and filter headers from at startup rather than syncing them from peers. headersnapshothash and an assertfilterheader at or below the end of the snapshot are required, the snapshot is also verified against the checkpoints."`
+	HeaderSnapshotHash   string        `long:"headersnapshothash" description:"The sha256 of the headersnapshot file in hex, as printed by wallettool exportheaders. The snapshot is not imported unless it matches."`
 }
@@ -6,6 +6,7 @@ package lnd
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -21,13 +22,16 @@ import (
 
 	apifunctions "github.com/pkt-cash/pktd/apiv1"
 	"github.com/pkt-cash/pktd/apiv1/lightning"
+	apiwallets "github.com/pkt-cash/pktd/apiv1/wallets"
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/util/mailbox"
+	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
 	"github.com/pkt-cash/pktd/cjdns"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/autopilot"
+	"github.com/pkt-cash/pktd/lnd/cert"
 	"github.com/pkt-cash/pktd/lnd/chainreg"
 	"github.com/pkt-cash/pktd/lnd/chanacceptor"
 	"github.com/pkt-cash/pktd/lnd/channeldb"
@@ -35,8 +39,12 @@ import (
 	"github.com/pkt-cash/pktd/lnd/lncfg"
 	"github.com/pkt-cash/pktd/lnd/lnrpc"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1/apitoken"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/autopilotrpc"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/invoicesrpc"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/routerrpc"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/signrpc"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/walletrpc"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/wtclientrpc"
 	"github.com/pkt-cash/pktd/lnd/lnwallet"
 	"github.com/pkt-cash/pktd/lnd/signal"
@@ -93,8 +101,42 @@ func Main(cfg *Config, shutdownChan <-chan struct{}) er.R {
 
 	api, apiRouter := apiv1.New()
 
+	if cfg.RestAuth {
+		tokens, err := apitoken.Open(filepath.Join(cfg.DataDir, defaultRestTokensFile))
+		if err != nil {
+			return err
+		}
+		adminTokenPath := filepath.Join(cfg.DataDir, defaultAdminTokenFile)
+		if created, err := tokens.EnsureAdmin(adminTokenPath); err != nil {
+			return err
+		} else if created {
+			log.Infof("Created REST admin token in [%s]", adminTokenPath)
+		}
+		api.UseTokens(tokens)
+	}
+
+	var restTLS *tls.Config
+	if cfg.RestTLS {
+		if c, err := getRestTLSConfig(cfg); err != nil {
+			return err
+		} else {
+			restTLS = c
+		}
+	}
+
 	for _, restEndpoint := range cfg.RESTListeners {
-		lis, err := lncfg.ListenOnAddress(restEndpoint)
+		if !cfg.RestAuth && !lncfg.IsLoopback(restEndpoint.String()) &&
+			!lncfg.IsUnix(restEndpoint) {
+			log.Warnf("REST listening on [%s] without --restauth, anyone who can "+
+				"reach this address can control the wallet", restEndpoint)
+		}
+		var lis net.Listener
+		var err er.R
+		if restTLS != nil {
+			lis, err = lncfg.TLSListenOnAddress(restEndpoint, restTLS)
+		} else {
+			lis, err = lncfg.ListenOnAddress(restEndpoint)
+		}
 		if err != nil {
 			log.Errorf("REST unable to listen on %s", restEndpoint)
 			return err
@@ -163,12 +205,23 @@ func Main(cfg *Config, shutdownChan <-chan struct{}) er.R {
 
 	initLightning := mailbox.NewMailbox[*lightning.StartLightning](nil)
 
-	apifunctions.Register(
+	neutrinoApi := apifunctions.Register(
+		api,
+		wallet,
+		neutrinoCS,
+		&initLightning,
+	)
+	defer neutrinoApi.Stop()
+	wallets := apiwallets.Register(
 		api,
 		wallet,
 		neutrinoCS,
+		walletPath,
+		walletFilename,
+		!cfg.SyncFreelist,
 		&initLightning,
 	)
+	defer wallets.Stop()
 
 	startLightning := initLightning.AwaitUpdate()
 	return startupLightning(
@@ -181,6 +234,46 @@ func Main(cfg *Config, shutdownChan <-chan struct{}) er.R {
 		metaService,
 	)
 }
+
+// getRestTLSConfig loads the TLS certificate for the REST API, generating a
+// self-signed one if none exists, or if it is out of date and TLSAutoRefresh
+// is enabled.
+func getRestTLSConfig(cfg *Config) (*tls.Config, er.R) {
+	if !lnrpc.FileExists(cfg.TLSCertPath) && !lnrpc.FileExists(cfg.TLSKeyPath) {
+		log.Infof("Generating TLS certificate for REST [%s]", cfg.TLSCertPath)
+		if err := cert.GenCertPair(
+			"pld autogenerated cert", cfg.TLSCertPath, cfg.TLSKeyPath,
+			cfg.TLSExtraIPs, cfg.TLSExtraDomains, cfg.TLSDisableAutofill,
+			cert.DefaultAutogenValidity,
+		); err != nil {
+			return nil, err
+		}
+	}
+	certData, parsedCert, errr := cert.LoadCert(cfg.TLSCertPath, cfg.TLSKeyPath)
+	if errr != nil {
+		return nil, er.Errorf("Unable to load TLS certificate [%s]: [%s]",
+			cfg.TLSCertPath, errr)
+	}
+	if cfg.TLSAutoRefresh {
+		outdated, err := cert.IsOutdated(parsedCert, cfg.TLSExtraIPs,
+			cfg.TLSExtraDomains, cfg.TLSDisableAutofill)
+		if err != nil {
+			return nil, err
+		}
+		if outdated || time.Now().After(parsedCert.NotAfter) {
+			log.Infof("TLS certificate [%s] is out of date, regenerating", cfg.TLSCertPath)
+			if err := os.Remove(cfg.TLSCertPath); err != nil {
+				return nil, er.E(err)
+			}
+			if err := os.Remove(cfg.TLSKeyPath); err != nil {
+				return nil, er.E(err)
+			}
+			return getRestTLSConfig(cfg)
+		}
+	}
+	return cert.TLSConfFromCert(certData), nil
+}
+
 func startupLightning(
 	cfg *Config,
 	shutdownChan <-chan struct{},
@@ -266,9 +359,9 @@ func startupLightning(
 	}
 
 	if cfg.Tor.Active {
-		log.Infof("Proxying all network traffic via Tor "+
-			"(stream_isolation=%v)! NOTE: Ensure the backend node "+
-			"is proxying over Tor as well", cfg.Tor.StreamIsolation)
+		log.Infof("Proxying all network traffic, including neutrino "+
+			"peers, via Tor (stream_isolation=%v)!",
+			cfg.Tor.StreamIsolation)
 	}
 
 	// If the watchtower client should be active, open the client database.
@@ -475,6 +568,25 @@ func startupLightning(
 	}
 	restContext.RegisterFunctions(api)
 
+	invoicesRpc, err := invoicesrpc.New(cfg.SubRPCServers.InvoicesRPC)
+	if err != nil {
+		return err
+	}
+	defer invoicesRpc.Stop()
+	invoicesrpc.Register(invoicesRpc, api.Category("lightning/invoice"))
+
+	walletKit, err := walletrpc.New(cfg.SubRPCServers.WalletKitRPC)
+	if err != nil {
+		return err
+	}
+	walletrpc.Register(walletKit, api.Category("wallet"))
+
+	signer, err := signrpc.New(cfg.SubRPCServers.SignRPC)
+	if err != nil {
+		return err
+	}
+	signrpc.Register(signer, api.Category("lightning"))
+
 	// We have brought up the RPC server so we can now cause the lightning/start to complete.
 	startLightning.StartupComplete.Store(true)
 
@@ -746,6 +858,31 @@ func initNeutrinoBackend(cfg *Config, chainDir string, napi *apiv1.Apiv1) (*neut
 		return nil, nil, errr
 	}
 
+	if cfg.NeutrinoMode.HeaderSnapshot != "" {
+		if err := importHeaderSnapshot(
+			db, cfg.ActiveNetParams.Params,
+			cfg.NeutrinoMode.HeaderSnapshot,
+			cfg.NeutrinoMode.HeaderSnapshotHash, headerStateAssertion,
+		); err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+	}
+
+	// Neutrino peers are connected through the same network as everything
+	// else, unless a proxy is configured just for them.
+	neutrinoNet := cfg.net
+	if cfg.NeutrinoMode.Proxy != "" && !cfg.Tor.Active {
+		log.Infof("Proxying neutrino peer connections via [%s] "+
+			"(stream_isolation=%v)", cfg.NeutrinoMode.Proxy,
+			cfg.NeutrinoMode.ProxyStreamIsolation)
+		neutrinoNet = &tor.ProxyNet{
+			SOCKS:           cfg.NeutrinoMode.Proxy,
+			StreamIsolation: cfg.NeutrinoMode.ProxyStreamIsolation,
+		}
+	}
+	_, onion := neutrinoNet.(*tor.ProxyNet)
+
 	// With the database open, we can now create an instance of the
 	// neutrino light client. We pass in relevant configuration parameters
 	// required.
@@ -756,13 +893,17 @@ func initNeutrinoBackend(cfg *Config, chainDir string, napi *apiv1.Apiv1) (*neut
 		AddPeers:     cfg.NeutrinoMode.AddPeers,
 		ConnectPeers: cfg.NeutrinoMode.ConnectPeers,
 		Dialer: func(addr net.Addr) (net.Conn, er.R) {
-			return cfg.net.Dial(
+			return neutrinoNet.Dial(
 				addr.Network(), addr.String(),
 				cfg.ConnectionTimeout,
 			)
 		},
 		NameResolver: func(host string) ([]net.IP, er.R) {
-			addrs, err := cfg.net.LookupHost(host)
+			// There is no need to ask the proxy about IP addresses.
+			if ip := net.ParseIP(host); ip != nil {
+				return []net.IP{ip}, nil
+			}
+			addrs, err := neutrinoNet.LookupHost(host)
 			if err != nil {
 				return nil, err
 			}
@@ -779,6 +920,7 @@ func initNeutrinoBackend(cfg *Config, chainDir string, napi *apiv1.Apiv1) (*neut
 
 			return ips, nil
 		},
+		Onion:              onion,
 		AssertFilterHeader: headerStateAssertion,
 		CheckConectivity:   cfg.NeutrinoMode.CheckConectivity,
 	}
@@ -810,6 +952,44 @@ func initNeutrinoBackend(cfg *Config, chainDir string, napi *apiv1.Apiv1) (*neut
 	return neutrinoCS, cleanUp, nil
 }
 
+// importHeaderSnapshot adds the block and filter headers from a snapshot file
+// to the neutrino database, so that neutrino only needs to sync the headers
+// which come after it. The file must have the sha256 sumHex.
+func importHeaderSnapshot(db walletdb.DB, params *chaincfg.Params, path, sumHex string,
+	assert *headerfs.FilterHeader) er.R {
+
+	var sum [32]byte
+	b, errr := hex.DecodeString(sumHex)
+	if errr != nil || len(b) != len(sum) {
+		return er.Errorf("a header snapshot needs neutrino.headersnapshothash " +
+			"to be the sha256 of the snapshot file in hex")
+	}
+	copy(sum[:], b)
+	if assert == nil {
+		return er.Errorf("a header snapshot needs neutrino.assertfilterheader " +
+			"to check the filter headers in it")
+	}
+
+	f, errr := os.Open(path)
+	if errr != nil {
+		return er.Errorf("unable to open header snapshot: %v", errr)
+	}
+	defer f.Close()
+
+	store, err := headerfs.NewNeutrinoDBStore(db, params, false)
+	if err != nil {
+		return err
+	}
+	log.Infof("Importing headers from snapshot [%s]", path)
+	height, err := store.ImportSnapshot(f, params, assert, sum)
+	if err != nil {
+		return er.Errorf("unable to import header snapshot [%s]: %v",
+			path, err)
+	}
+	log.Infof("Headers are imported up to height [%d]", height)
+	return nil
+}
+
 // parseHeaderStateAssertion parses the user-specified neutrino header state
 // into a headerfs.FilterHeader.
 func parseHeaderStateAssertion(state string) (*headerfs.FilterHeader, er.R) {
@@ -0,0 +1,321 @@
+// Package apiclient is a typed Go client for the pld REST API.
+//
+// Each endpoint has a method on Client, these are generated into endpoints.go
+// from the endpoint help in endpoints.json so that paths and request/response
+// types are never written by hand. After endpoints change, refresh
+// endpoints.json from a pld which has lightning running and was started with
+// --restauth and --cjdnssocket, so that every endpoint is registered, then run
+// go generate:
+//
+//	go run ./genclient -pld_server http://localhost:53199 -tokenfile ~/.pktwallet/pkt/admin.token -save endpoints.json
+//
+// Requests can be sent as JSON or protobuf, streaming endpoints return a
+// Stream which yields one event at a time. Errors from the server are typed
+// so that they can be checked with e.g. apiclient.ErrForbidden.Is(err).
+package apiclient
+
+//go:generate go run ./genclient -in endpoints.json -out endpoints.go
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+)
+
+// Err is the type of all errors which are returned by the client
+var Err = er.NewErrorType("apiclient.Err")
+
+var (
+	// ErrBadRequest means the request could not be parsed or was not allowed
+	ErrBadRequest = Err.CodeWithNumberAndDetail("ErrBadRequest", http.StatusBadRequest,
+		"the request was invalid")
+	// ErrUnauthorized means the token is missing or invalid
+	ErrUnauthorized = Err.CodeWithNumberAndDetail("ErrUnauthorized", http.StatusUnauthorized,
+		"missing or invalid token")
+	// ErrForbidden means the token does not have the permission which the endpoint needs
+	ErrForbidden = Err.CodeWithNumberAndDetail("ErrForbidden", http.StatusForbidden,
+		"the token does not have permission to call this endpoint")
+	// ErrNotFound means there is no such endpoint, e.g. lightning is not started
+	ErrNotFound = Err.CodeWithNumberAndDetail("ErrNotFound", http.StatusNotFound,
+		"no such endpoint")
+	// ErrMethodNotAllowed means the endpoint was called with the wrong HTTP method
+	ErrMethodNotAllowed = Err.CodeWithNumberAndDetail("ErrMethodNotAllowed",
+		http.StatusMethodNotAllowed, "method not allowed")
+	// ErrUnsupportedMediaType means the server did not accept the content type
+	ErrUnsupportedMediaType = Err.CodeWithNumberAndDetail("ErrUnsupportedMediaType",
+		http.StatusUnsupportedMediaType, "unsupported content type")
+	// ErrServer means the endpoint was called and it returned an error
+	ErrServer = Err.CodeWithNumberAndDetail("ErrServer", http.StatusInternalServerError,
+		"the server returned an error")
+	// ErrTransport means the server could not be reached or the reply was unreadable
+	ErrTransport = Err.CodeWithDetail("ErrTransport", "unable to communicate with pld")
+	// ErrEndOfStream is returned by Stream.Recv once the stream has ended normally
+	ErrEndOfStream = Err.CodeWithDetail("ErrEndOfStream", "the stream has ended")
+	// ErrStream means the server ended a stream because of an error
+	ErrStream = Err.CodeWithDetail("ErrStream", "the stream was ended by an error")
+)
+
+// Trailer which carries the error, if any, which ended an HTTP stream.
+const streamErrorTrailer = "Pld-Stream-Error"
+
+// Config is the configuration of a Client
+type Config struct {
+	// URL of pld, e.g. http://localhost:53199
+	URL string
+	// Token to authenticate with, needed if pld was started with --restauth
+	Token string
+	// TokenFile is read to get the token if Token is empty
+	TokenFile string
+	// TLSCertPath is a certificate to trust when connecting to pld over https
+	TLSCertPath string
+	// Protobuf sends requests and reads replies as protobuf rather than JSON
+	Protobuf bool
+	// Wallet is the name of the wallet which /api/v1/wallet/ requests go to,
+	// if empty then they go to the wallet which is selected in pld
+	Wallet string
+	// HTTPClient is used instead of making a client, if set
+	HTTPClient *http.Client
+}
+
+// Client calls the endpoints of a pld node
+type Client struct {
+	url      string
+	token    string
+	protobuf bool
+	wallet   string
+	http     *http.Client
+}
+
+// New creates a Client from a Config
+func New(cfg Config) (*Client, er.R) {
+	c := &Client{
+		url:      strings.TrimSuffix(cfg.URL, "/"),
+		token:    cfg.Token,
+		protobuf: cfg.Protobuf,
+		wallet:   cfg.Wallet,
+		http:     cfg.HTTPClient,
+	}
+	if c.url == "" {
+		c.url = "http://localhost:53199"
+	}
+	if c.token == "" && cfg.TokenFile != "" {
+		b, err := os.ReadFile(cfg.TokenFile)
+		if err != nil {
+			return nil, er.Errorf("Unable to read token file [%s]: [%s]", cfg.TokenFile, err)
+		}
+		c.token = strings.TrimSpace(string(b))
+	}
+	if c.http == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.TLSCertPath != "" {
+			b, err := os.ReadFile(cfg.TLSCertPath)
+			if err != nil {
+				return nil, er.Errorf("Unable to read TLS certificate [%s]: [%s]", cfg.TLSCertPath, err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(b) {
+				return nil, er.Errorf("No certificate found in [%s]", cfg.TLSCertPath)
+			}
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		}
+		c.http = &http.Client{Transport: transport}
+	}
+	return c, nil
+}
+
+// WithWallet returns a Client which sends /api/v1/wallet/ requests to the
+// named wallet, it shares the connection pool of c.
+func (c *Client) WithWallet(name string) *Client {
+	out := *c
+	out.wallet = name
+	return &out
+}
+
+func isNull(m proto.Message) bool {
+	if m == nil {
+		return true
+	}
+	_, ok := m.(*rpc_pb.Null)
+	return ok
+}
+
+func (c *Client) contentType() string {
+	if c.protobuf {
+		return "application/protobuf"
+	}
+	return "application/json"
+}
+
+func (c *Client) encode(m proto.Message) ([]byte, er.R) {
+	if c.protobuf {
+		return er.E1(proto.Marshal(m))
+	}
+	return er.E1(protojson.Marshal(m))
+}
+
+func (c *Client) decode(b []byte, m proto.Message) er.R {
+	if c.protobuf {
+		return er.E(proto.Unmarshal(b, m))
+	}
+	return er.E(protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, m))
+}
+
+// do sends a request, requests with no content are sent as GET unless a
+// stream is wanted.
+func (c *Client) do(path string, req proto.Message, stream bool) (*http.Response, er.R) {
+	method := "GET"
+	var body io.Reader
+	if stream || !isNull(req) {
+		if req == nil {
+			req = &rpc_pb.Null{}
+		}
+		b, err := c.encode(req)
+		if err != nil {
+			return nil, err
+		}
+		method = "POST"
+		body = bytes.NewReader(b)
+	}
+	hreq, errr := http.NewRequest(method, c.url+"/api/v1/"+path, body)
+	if errr != nil {
+		return nil, ErrTransport.New("", er.E(errr))
+	}
+	hreq.Header.Set("Content-Type", c.contentType())
+	if stream {
+		// This asks for a stream, the framing follows the Content-Type so with
+		// protobuf each event is prefixed by its length rather than being a line.
+		hreq.Header.Set("Accept", "application/x-ndjson")
+	}
+	if c.token != "" {
+		hreq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.wallet != "" {
+		hreq.Header.Set("Pld-Wallet", c.wallet)
+	}
+	resp, errr := c.http.Do(hreq)
+	if errr != nil {
+		return nil, ErrTransport.New("", er.E(errr))
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.errorFrom(resp)
+	}
+	return resp, nil
+}
+
+// errorFrom makes a typed error from a reply which is not 200 OK, endpoint
+// errors carry a RestError while authentication errors are plain text.
+func (c *Client) errorFrom(resp *http.Response) er.R {
+	code := Err.NumberToCode(resp.StatusCode)
+	if code == nil {
+		code = ErrServer
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return code.New(resp.Status, nil)
+	}
+	msg := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		var re rpc_pb.RestError
+		if c.decode(b, &re) == nil && re.Message != "" {
+			msg = re.Message
+		}
+	}
+	return code.New(msg, nil)
+}
+
+// Call calls an endpoint and reads the reply into res, res may be nil if the
+// endpoint replies with nothing.
+func (c *Client) Call(path string, req proto.Message, res proto.Message) er.R {
+	resp, err := c.do(path, req, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, errr := io.ReadAll(resp.Body)
+	if errr != nil {
+		return ErrTransport.New("", er.E(errr))
+	}
+	if res == nil || len(b) == 0 {
+		return nil
+	}
+	if err := c.decode(b, res); err != nil {
+		return ErrTransport.New("unable to decode reply", err)
+	}
+	return nil
+}
+
+// Stream is an open event stream from a streaming endpoint
+type Stream[R proto.Message] struct {
+	c    *Client
+	resp *http.Response
+	r    *bufio.Reader
+}
+
+// OpenStream calls a streaming endpoint, events are then read with Recv.
+func OpenStream[R proto.Message](c *Client, path string, req proto.Message) (*Stream[R], er.R) {
+	resp, err := c.do(path, req, true)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream[R]{c: c, resp: resp, r: bufio.NewReader(resp.Body)}, nil
+}
+
+func (s *Stream[R]) end(errr error) er.R {
+	if errr != io.EOF {
+		return ErrTransport.New("", er.E(errr))
+	}
+	// Trailers are only available once the body has been read to the end
+	if msg := s.resp.Trailer.Get(streamErrorTrailer); msg != "" {
+		return ErrStream.New(msg, nil)
+	}
+	return ErrEndOfStream.Default()
+}
+
+// Recv waits for the next event, when the stream ends it returns ErrEndOfStream
+// or ErrStream if the server ended it because of an error.
+func (s *Stream[R]) Recv() (R, er.R) {
+	out := reflect.New(reflect.TypeOf((*R)(nil)).Elem().Elem()).Interface().(R)
+	var b []byte
+	if s.c.protobuf {
+		l, errr := binary.ReadUvarint(s.r)
+		if errr != nil {
+			return out, s.end(errr)
+		}
+		b = make([]byte, l)
+		if _, errr := io.ReadFull(s.r, b); errr != nil {
+			return out, s.end(errr)
+		}
+	} else {
+		for len(b) == 0 {
+			line, errr := s.r.ReadBytes('\n')
+			if errr != nil && (errr != io.EOF || len(bytes.TrimSpace(line)) == 0) {
+				return out, s.end(errr)
+			}
+			// Empty lines are sent to keep the connection alive
+			b = bytes.TrimSpace(line)
+		}
+	}
+	if err := s.c.decode(b, out); err != nil {
+		return out, ErrTransport.New("unable to decode event", err)
+	}
+	return out, nil
+}
+
+// Close ends the stream
+func (s *Stream[R]) Close() er.R {
+	return er.E(s.resp.Body.Close())
+}
@@ -0,0 +1,212 @@
+package apiclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+)
+
+func testClient(t *testing.T, h http.HandlerFunc, protobuf bool) *Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := New(Config{URL: srv.URL, Token: "secret", Protobuf: protobuf})
+	require.Nil(t, err)
+	return c
+}
+
+func marshal(t *testing.T, m proto.Message, protobuf bool) []byte {
+	var b []byte
+	var errr error
+	if protobuf {
+		b, errr = proto.Marshal(m)
+	} else {
+		b, errr = protojson.Marshal(m)
+	}
+	require.NoError(t, errr)
+	return b
+}
+
+func TestCallEncoding(t *testing.T) {
+	for _, protobuf := range []bool{false, true} {
+		ct := "application/json"
+		if protobuf {
+			ct = "application/protobuf"
+		}
+		c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "POST", r.Method)
+			require.Equal(t, "/api/v1/wallet/address/create", r.URL.Path)
+			require.Equal(t, ct, r.Header.Get("Content-Type"))
+			require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			require.Equal(t, "other", r.Header.Get("Pld-Wallet"))
+			b, errr := io.ReadAll(r.Body)
+			require.NoError(t, errr)
+			var req rpc_pb.GetNewAddressRequest
+			if protobuf {
+				require.NoError(t, proto.Unmarshal(b, &req))
+			} else {
+				require.NoError(t, protojson.Unmarshal(b, &req))
+			}
+			require.True(t, req.Legacy)
+			w.Header().Set("Content-Type", ct)
+			w.Write(marshal(t, &rpc_pb.GetNewAddressResponse{Address: "pkt1qexample"}, protobuf))
+		}, protobuf).WithWallet("other")
+
+		res, err := c.WalletAddressCreate(&rpc_pb.GetNewAddressRequest{Legacy: true})
+		require.Nil(t, err)
+		require.Equal(t, "pkt1qexample", res.Address)
+	}
+}
+
+func TestDoMethod(t *testing.T) {
+	var method string
+	var body []byte
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		b, errr := io.ReadAll(r.Body)
+		require.NoError(t, errr)
+		body = b
+	}, false)
+
+	// Requests with no content are sent as GET so they can be cached and
+	// called from a browser.
+	for _, req := range []proto.Message{nil, &rpc_pb.Null{}} {
+		resp, err := c.do("meta/getinfo", req, false)
+		require.Nil(t, err)
+		resp.Body.Close()
+		require.Equal(t, "GET", method)
+		require.Empty(t, body)
+	}
+
+	resp, err := c.do("wallet/address/create", &rpc_pb.GetNewAddressRequest{}, false)
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, "POST", method)
+	require.Equal(t, "{}", string(body))
+
+	// A stream is always asked for with a POST.
+	resp, err = c.do("wallet/events", nil, true)
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, "POST", method)
+}
+
+func TestErrorFrom(t *testing.T) {
+	for _, tc := range []struct {
+		status      int
+		contentType string
+		body        proto.Message
+		text        string
+		code        *er.ErrorCode
+		msg         string
+	}{
+		{http.StatusBadRequest, "", &rpc_pb.RestError{Message: "bad txid"}, "", ErrBadRequest, "bad txid"},
+		{http.StatusUnauthorized, "text/plain; charset=utf-8", nil, "Missing token\n", ErrUnauthorized, "Missing token"},
+		{http.StatusForbidden, "text/plain; charset=utf-8", nil, "Forbidden", ErrForbidden, "Forbidden"},
+		{http.StatusNotFound, "", &rpc_pb.RestError{Message: "No such endpoint"}, "", ErrNotFound, "No such endpoint"},
+		{http.StatusMethodNotAllowed, "text/plain", nil, "405", ErrMethodNotAllowed, "405"},
+		{http.StatusUnsupportedMediaType, "text/plain", nil, "415", ErrUnsupportedMediaType, "415"},
+		{http.StatusInternalServerError, "", &rpc_pb.RestError{Message: "wallet locked"}, "", ErrServer, "wallet locked"},
+		// Statuses which have no error of their own are server errors
+		{http.StatusTeapot, "text/plain", nil, "teapot", ErrServer, "teapot"},
+	} {
+		for _, protobuf := range []bool{false, true} {
+			c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+				w.WriteHeader(tc.status)
+				if tc.body != nil {
+					w.Write(marshal(t, tc.body, protobuf))
+				} else {
+					io.WriteString(w, tc.text)
+				}
+			}, protobuf)
+			err := c.Call("wallet/address/create", &rpc_pb.GetNewAddressRequest{}, nil)
+			require.NotNil(t, err)
+			require.True(t, tc.code.Is(err), "status [%d] gave [%s]", tc.status, err)
+			require.Contains(t, err.Message(), tc.msg)
+		}
+	}
+}
+
+// streamServer writes each of the frames to the stream, flushing after each,
+// and then ends it with errMsg in the error trailer if it is not empty.
+func streamServer(t *testing.T, contentType string, frames [][]byte, errMsg string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		require.Contains(t, r.Header.Get("Accept"), "application/x-ndjson")
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Trailer", streamErrorTrailer)
+		w.WriteHeader(http.StatusOK)
+		for _, f := range frames {
+			w.Write(f)
+			w.(http.Flusher).Flush()
+		}
+		if errMsg != "" {
+			w.Header().Set(streamErrorTrailer, errMsg)
+		}
+	}
+}
+
+func recvAll(t *testing.T, c *Client) ([]string, er.R) {
+	s, err := OpenStream[*rpc_pb.GetNewAddressResponse](c, "wallet/events", nil)
+	require.Nil(t, err)
+	defer s.Close()
+	var out []string
+	for {
+		ev, err := s.Recv()
+		if err != nil {
+			return out, err
+		}
+		out = append(out, ev.Address)
+	}
+}
+
+func TestStreamNdjson(t *testing.T) {
+	ev := func(addr string) []byte {
+		return append(marshal(t, &rpc_pb.GetNewAddressResponse{Address: addr}, false), '\n')
+	}
+	// Empty lines are keepalives and are skipped
+	frames := [][]byte{[]byte("\n"), ev("a"), []byte("\n"), []byte("\n"), ev("b")}
+
+	c := testClient(t, streamServer(t, "application/x-ndjson", frames, ""), false)
+	evs, err := recvAll(t, c)
+	require.True(t, ErrEndOfStream.Is(err), "got [%s]", err)
+	require.Equal(t, []string{"a", "b"}, evs)
+
+	c = testClient(t, streamServer(t, "application/x-ndjson", frames, "wallet unloaded"), false)
+	evs, err = recvAll(t, c)
+	require.True(t, ErrStream.Is(err), "got [%s]", err)
+	require.Contains(t, err.Message(), "wallet unloaded")
+	require.Equal(t, []string{"a", "b"}, evs)
+}
+
+func TestStreamProtobuf(t *testing.T) {
+	ev := func(addr string) []byte {
+		b := marshal(t, &rpc_pb.GetNewAddressResponse{Address: addr}, true)
+		return append(protowire.AppendVarint(nil, uint64(len(b))), b...)
+	}
+	// An event may be split across writes and an empty event is zero length
+	a := ev("a")
+	frames := [][]byte{a[:1], a[1:], ev(""), ev("c")}
+
+	c := testClient(t, streamServer(t, "application/protobuf", frames, ""), true)
+	evs, err := recvAll(t, c)
+	require.True(t, ErrEndOfStream.Is(err), "got [%s]", err)
+	require.Equal(t, []string{"a", "", "c"}, evs)
+
+	c = testClient(t, streamServer(t, "application/protobuf", frames, "subscriber fell behind"), true)
+	evs, err = recvAll(t, c)
+	require.True(t, ErrStream.Is(err), "got [%s]", err)
+	require.Contains(t, err.Message(), "subscriber fell behind")
+	require.Equal(t, []string{"a", "", "c"}, evs)
+}
@@ -0,0 +1,370 @@
+// genclient writes the endpoint methods of the apiclient package, it reads the
+// help of every endpoint from a running pld (or from a file saved with -save)
+// and makes one method per endpoint with the request and response types which
+// the help gives.
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+const protoPkg = "github.com/pkt-cash/pktd/generated/proto/"
+
+// Proto packages which live under generated/proto/restrpc_pb
+var restrpcPkgs = map[string]bool{
+	"help_pb":     true,
+	"rest_pb":     true,
+	"external_pb": true,
+}
+
+// Endpoints which can not be called as a normal request
+var skip = map[string]bool{
+	"websocket": true,
+}
+
+// isWalletCopy is true for the copy of the wallet/ endpoints which pld makes
+// under wallets/<name>/ for each loaded wallet, they are reached with
+// Client.WithWallet so no methods are made for them.
+func isWalletCopy(path string) bool {
+	segs := strings.Split(strings.TrimPrefix(path, "/api/v1/"), "/")
+	return len(segs) > 2 && segs[0] == "wallets"
+}
+
+type helpType struct {
+	Name string `json:"name"`
+}
+
+type endpointHelp struct {
+	Path           string    `json:"path"`
+	Description    []string  `json:"description"`
+	Request        *helpType `json:"request,omitempty"`
+	Response       *helpType `json:"response,omitempty"`
+	Features       []string  `json:"features"`
+	StreamRequest  *helpType `json:"streamRequest,omitempty"`
+	StreamResponse *helpType `json:"streamResponse,omitempty"`
+}
+
+type endpointSimple struct {
+	HelpPath string `json:"helpPath"`
+}
+
+type category struct {
+	Endpoints  map[string]endpointSimple `json:"endpoints"`
+	Categories map[string]category       `json:"categories"`
+}
+
+type fetcher struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (f *fetcher) get(path string, out interface{}) error {
+	req, err := http.NewRequest("GET", f.url+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if f.token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.token)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(b)))
+	}
+	return json.Unmarshal(b, out)
+}
+
+func collect(cat category, out *[]string) {
+	for _, ep := range cat.Endpoints {
+		*out = append(*out, ep.HelpPath)
+	}
+	for _, c := range cat.Categories {
+		collect(c, out)
+	}
+}
+
+func (f *fetcher) fetchAll() ([]endpointHelp, error) {
+	var root category
+	if err := f.get("/api/v1/help", &root); err != nil {
+		return nil, err
+	}
+	var paths []string
+	collect(root, &paths)
+	sort.Strings(paths)
+	var out []endpointHelp
+	for _, p := range paths {
+		if isWalletCopy("/api/v1" + strings.TrimPrefix(p, "/api/v1/help")) {
+			continue
+		}
+		var eh endpointHelp
+		if err := f.get(p, &eh); err != nil {
+			return nil, err
+		}
+		out = append(out, eh)
+	}
+	return out, nil
+}
+
+// goType converts a help type name such as rpc_pb_GetInfoResponse into a Go
+// type and the import path of its package.
+func goType(name string) (string, string, error) {
+	i := strings.Index(name, "_pb_")
+	if i < 0 {
+		return "", "", fmt.Errorf("unable to find the package of type [%s]", name)
+	}
+	pkg := name[:i+3]
+	imp := protoPkg + pkg
+	if restrpcPkgs[pkg] {
+		imp = protoPkg + "restrpc_pb/" + pkg
+	}
+	return pkg + "." + name[i+4:], imp, nil
+}
+
+func isNull(t *helpType) bool {
+	return t == nil || t.Name == "" || t.Name == "rpc_pb_Null"
+}
+
+// methodName turns wallet/transaction/query into WalletTransactionQuery
+func methodName(path string) string {
+	var sb strings.Builder
+	for _, seg := range strings.Split(path, "/") {
+		for _, word := range strings.FieldsFunc(seg, func(r rune) bool {
+			return r == '_' || r == '-'
+		}) {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return sb.String()
+}
+
+type generator struct {
+	imports map[string]bool
+	body    bytes.Buffer
+}
+
+func (g *generator) typ(t *helpType) (string, error) {
+	name, imp, err := goType(t.Name)
+	if err != nil {
+		return "", err
+	}
+	g.imports[imp] = true
+	return name, nil
+}
+
+func (g *generator) doc(name, path string, eh *endpointHelp, stream bool) {
+	fmt.Fprintf(&g.body, "// %s calls %s\n//\n", name, path)
+	if len(eh.Description) > 0 {
+		fmt.Fprintf(&g.body, "// %s\n", eh.Description[0])
+	}
+	for _, f := range eh.Features {
+		if strings.HasPrefix(f, "PERM_") {
+			fmt.Fprintf(&g.body, "// Requires %s\n", f)
+		}
+	}
+	if stream {
+		fmt.Fprintf(&g.body, "// Each event is read from the Stream with Recv.\n")
+	}
+}
+
+func (g *generator) endpoint(eh *endpointHelp) error {
+	path := strings.TrimPrefix(eh.Path, "/api/v1/")
+	if skip[path] || isWalletCopy(path) {
+		return nil
+	}
+	name := methodName(path)
+	streaming := false
+	for _, f := range eh.Features {
+		if f == "STREAMING" {
+			streaming = true
+		}
+	}
+	// Endpoints which only stream give the stream types as request and response
+	streamOnly := streaming && eh.StreamResponse != nil && eh.Response != nil &&
+		eh.StreamResponse.Name == eh.Response.Name &&
+		(eh.StreamRequest == nil || eh.Request == nil || eh.StreamRequest.Name == eh.Request.Name)
+
+	if !streamOnly {
+		if err := g.call(name, path, eh); err != nil {
+			return err
+		}
+	}
+	if streaming {
+		sname := name
+		if !streamOnly {
+			sname = name + "Stream"
+		}
+		if err := g.stream(sname, path, eh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) call(name, path string, eh *endpointHelp) error {
+	g.doc(name, eh.Path, eh, false)
+	arg, reqVar := "", "nil"
+	if !isNull(eh.Request) {
+		t, err := g.typ(eh.Request)
+		if err != nil {
+			return err
+		}
+		arg, reqVar = "req *"+t, "req"
+	}
+	if isNull(eh.Response) {
+		fmt.Fprintf(&g.body, "func (c *Client) %s(%s) er.R {\n", name, arg)
+		fmt.Fprintf(&g.body, "\treturn c.Call(%q, %s, nil)\n}\n\n", path, reqVar)
+		return nil
+	}
+	res, err := g.typ(eh.Response)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&g.body, "func (c *Client) %s(%s) (*%s, er.R) {\n", name, arg, res)
+	fmt.Fprintf(&g.body, "\tres := &%s{}\n", res)
+	fmt.Fprintf(&g.body, "\tif err := c.Call(%q, %s, res); err != nil {\n\t\treturn nil, err\n\t}\n", path, reqVar)
+	fmt.Fprintf(&g.body, "\treturn res, nil\n}\n\n")
+	return nil
+}
+
+func (g *generator) stream(name, path string, eh *endpointHelp) error {
+	g.doc(name, eh.Path, eh, true)
+	arg, reqVar := "", "nil"
+	if req := eh.StreamRequest; !isNull(req) {
+		t, err := g.typ(req)
+		if err != nil {
+			return err
+		}
+		arg, reqVar = "req *"+t, "req"
+	}
+	if eh.StreamResponse == nil {
+		return fmt.Errorf("streaming endpoint [%s] has no stream response", path)
+	}
+	ev, err := g.typ(eh.StreamResponse)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&g.body, "func (c *Client) %s(%s) (*Stream[*%s], er.R) {\n", name, arg, ev)
+	fmt.Fprintf(&g.body, "\treturn OpenStream[*%s](c, %q, %s)\n}\n\n", ev, path, reqVar)
+	return nil
+}
+
+func generate(eps []endpointHelp) ([]byte, error) {
+	sort.Slice(eps, func(i, j int) bool { return eps[i].Path < eps[j].Path })
+	g := generator{imports: map[string]bool{"github.com/pkt-cash/pktd/btcutil/er": true}}
+	for i := range eps {
+		if err := g.endpoint(&eps[i]); err != nil {
+			return nil, err
+		}
+	}
+	var imports []string
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by genclient. DO NOT EDIT.\n\n")
+	out.WriteString("package apiclient\n\nimport (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+	out.Write(g.body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+func httpClient(tlsCertPath string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsCertPath != "" {
+		b, err := os.ReadFile(tlsCertPath)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in [%s]", tlsCertPath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+func run() error {
+	var pldServer, token, tokenFile, tlsCertPath, in, save, out string
+	flag.StringVar(&pldServer, "pld_server", "http://localhost:53199", "the pld server URL")
+	flag.StringVar(&token, "token", "", "REST API token, needed if pld was started with --restauth")
+	flag.StringVar(&tokenFile, "tokenfile", "", "read the REST API token from a file")
+	flag.StringVar(&tlsCertPath, "tlscertpath", "", "trust this certificate when connecting to pld over https")
+	flag.StringVar(&in, "in", "", "read endpoint help from this file rather than from pld")
+	flag.StringVar(&save, "save", "", "save the endpoint help which was read to this file")
+	flag.StringVar(&out, "out", "endpoints.go", "file to write the generated code to")
+	flag.Parse()
+
+	var eps []endpointHelp
+	if in != "" {
+		b, err := os.ReadFile(in)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &eps); err != nil {
+			return fmt.Errorf("unable to parse [%s]: %v", in, err)
+		}
+	} else {
+		if token == "" && tokenFile != "" {
+			b, err := os.ReadFile(tokenFile)
+			if err != nil {
+				return err
+			}
+			token = strings.TrimSpace(string(b))
+		}
+		client, err := httpClient(tlsCertPath)
+		if err != nil {
+			return err
+		}
+		f := fetcher{url: strings.TrimSuffix(pldServer, "/"), token: token, client: client}
+		if eps, err = f.fetchAll(); err != nil {
+			return err
+		}
+	}
+	if save != "" {
+		b, err := json.MarshalIndent(eps, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(save, append(b, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	src, err := generate(eps)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0644)
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "genclient: %v\n", err)
+		os.Exit(1)
+	}
+}
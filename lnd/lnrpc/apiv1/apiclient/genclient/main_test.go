@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/endpoints.go.golden")
+
+func readEndpoints(t *testing.T, path string) []endpointHelp {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var eps []endpointHelp
+	require.NoError(t, json.Unmarshal(b, &eps))
+	return eps
+}
+
+// TestGenerateGolden covers each kind of endpoint: calls with and without a
+// request or response, endpoints which only stream, endpoints which can be
+// called or streamed, and endpoints which get no method.
+func TestGenerateGolden(t *testing.T) {
+	src, err := generate(readEndpoints(t, "testdata/endpoints.json"))
+	require.NoError(t, err)
+	if *update {
+		require.NoError(t, os.WriteFile("testdata/endpoints.go.golden", src, 0644))
+	}
+	want, err := os.ReadFile("testdata/endpoints.go.golden")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(src))
+}
+
+// TestEndpointsUpToDate checks that endpoints.go is what genclient makes from
+// endpoints.json, so that neither is changed without the other.
+func TestEndpointsUpToDate(t *testing.T) {
+	src, err := generate(readEndpoints(t, "../endpoints.json"))
+	require.NoError(t, err)
+	want, err := os.ReadFile("../endpoints.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(src), "endpoints.go is out of date, run go generate")
+}
+
+func TestMethodName(t *testing.T) {
+	require.Equal(t, "WalletTransactionQuery", methodName("wallet/transaction/query"))
+	require.Equal(t, "UtilSeedCreate", methodName("util/seed/create"))
+	require.Equal(t, "LightningChannelClosedStream", methodName("lightning/channel/closed_stream"))
+	require.Equal(t, "NeutrinoAddressScanJob", methodName("neutrino/address/scan-job"))
+}
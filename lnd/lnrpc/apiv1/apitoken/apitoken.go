@@ -0,0 +1,271 @@
+// Package apitoken manages the bearer tokens which are used to authenticate
+// clients of the REST API.
+//
+// Only a hash of each token is stored, the token itself is given out once
+// when it is baked and can not be recovered afterward. Each token has a scope
+// which grants a set of permissions, each endpoint requires one permission.
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/lock"
+)
+
+// Permissions which an endpoint may require, each endpoint requires exactly one.
+const (
+	// PermRead endpoints only look at the state of the node and wallet
+	PermRead = "read"
+	// PermWrite endpoints change the state but cannot move funds or reveal secrets
+	PermWrite = "write"
+	// PermSpend endpoints can move funds or sign using the wallet's keys
+	PermSpend = "spend"
+	// PermSecret endpoints reveal or import private keys, or create or reveal a wallet seed
+	PermSecret = "secret"
+	// PermAdmin endpoints control the node itself
+	PermAdmin = "admin"
+)
+
+// Permissions is the list of all permissions
+var Permissions = []string{PermRead, PermWrite, PermSpend, PermSecret, PermAdmin}
+
+const (
+	// ScopeAdmin tokens may call every endpoint
+	ScopeAdmin = "admin"
+	// ScopeReadonly tokens may only call endpoints which do not change anything
+	ScopeReadonly = "readonly"
+	// ScopeInvoice tokens may read and make changes such as creating invoices
+	// and addresses, but they cannot spend or see secrets
+	ScopeInvoice = "invoice"
+)
+
+// Scopes is the list of named scopes, a scope may also be a comma separated
+// list of permissions such as "read,write,spend".
+var Scopes = []string{ScopeAdmin, ScopeReadonly, ScopeInvoice}
+
+var scopePerms = map[string][]string{
+	ScopeAdmin:    Permissions,
+	ScopeReadonly: {PermRead},
+	ScopeInvoice:  {PermRead, PermWrite},
+}
+
+// ScopePermissions returns the permissions which are granted by a scope.
+func ScopePermissions(scope string) ([]string, er.R) {
+	if perms, ok := scopePerms[scope]; ok {
+		return perms, nil
+	}
+	var out []string
+	for _, p := range strings.Split(scope, ",") {
+		p = strings.TrimSpace(p)
+		if !isPermission(p) {
+			return nil, er.Errorf("Invalid scope [%s], a scope must be one of %v "+
+				"or a comma separated list of permissions from %v", scope, Scopes, Permissions)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// AdminTokenName is the name of the token which is created automatically
+// when a Store is first used.
+const AdminTokenName = "admin"
+
+// Token is the stored representation of a bearer token
+type Token struct {
+	Name    string    `json:"name"`
+	Scope   string    `json:"scope"`
+	Hash    string    `json:"hash"`
+	Created time.Time `json:"created"`
+}
+
+// Store is a set of tokens which is persisted to a file
+type Store struct {
+	path   string
+	tokens lock.GenMutex[map[string]Token]
+}
+
+func hashToken(secret string) string {
+	h := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(h[:])
+}
+
+func isPermission(perm string) bool {
+	for _, p := range Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows returns true if the token grants a permission.
+func (t *Token) Allows(perm string) bool {
+	perms, err := ScopePermissions(t.Scope)
+	if err != nil {
+		return false
+	}
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Open loads the Store from a file, if the file does not exist then an empty
+// store is returned and the file is created when the first token is baked.
+func Open(path string) (*Store, er.R) {
+	tokens := make(map[string]Token)
+	if b, err := os.ReadFile(path); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, er.E(err)
+		}
+	} else {
+		var list []Token
+		if err := json.Unmarshal(b, &list); err != nil {
+			return nil, er.Errorf("Unable to parse token file [%s]: [%s]", path, err)
+		}
+		for _, t := range list {
+			tokens[t.Name] = t
+		}
+	}
+	return &Store{
+		path:   path,
+		tokens: lock.NewGenMutex(tokens, "apitoken.Store"),
+	}, nil
+}
+
+// save must be called while holding the lock
+func (s *Store) save(tokens map[string]Token) er.R {
+	list := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	b, err := json.MarshalIndent(list, "", "    ")
+	if err != nil {
+		return er.E(err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return er.E(err)
+	}
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return er.E(err)
+	}
+	return er.E(os.Rename(tmp, s.path))
+}
+
+// Bake creates a new token with a given name and scope, the token itself is
+// returned and only a hash of it is stored so it must be saved by the caller.
+func (s *Store) Bake(name, scope string) (string, er.R) {
+	if name == "" {
+		return "", er.New("Token name must not be empty")
+	}
+	if _, err := ScopePermissions(scope); err != nil {
+		return "", err
+	}
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", er.E(err)
+	}
+	secret := hex.EncodeToString(b[:])
+	return secret, s.tokens.In(func(tokens *map[string]Token) er.R {
+		if _, ok := (*tokens)[name]; ok {
+			return er.Errorf("A token named [%s] already exists, revoke it first", name)
+		}
+		(*tokens)[name] = Token{
+			Name:    name,
+			Scope:   scope,
+			Hash:    hashToken(secret),
+			Created: time.Now(),
+		}
+		if err := s.save(*tokens); err != nil {
+			delete(*tokens, name)
+			return err
+		}
+		return nil
+	})
+}
+
+// Revoke deletes a token so that it can no longer be used.
+func (s *Store) Revoke(name string) er.R {
+	return s.tokens.In(func(tokens *map[string]Token) er.R {
+		t, ok := (*tokens)[name]
+		if !ok {
+			return er.Errorf("No such token [%s]", name)
+		}
+		delete(*tokens, name)
+		if err := s.save(*tokens); err != nil {
+			(*tokens)[name] = t
+			return err
+		}
+		return nil
+	})
+}
+
+// List returns all tokens, sorted by name.
+func (s *Store) List() []Token {
+	var out []Token
+	s.tokens.In(func(tokens *map[string]Token) er.R {
+		for _, t := range *tokens {
+			out = append(out, t)
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Check returns the token which matches a secret, or nil if the secret is
+// not a valid token.
+func (s *Store) Check(secret string) *Token {
+	if secret == "" {
+		return nil
+	}
+	hash := hashToken(secret)
+	var out *Token
+	s.tokens.In(func(tokens *map[string]Token) er.R {
+		for _, t := range *tokens {
+			if t.Hash == hash {
+				tt := t
+				out = &tt
+				return nil
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// EnsureAdmin makes sure there is an admin token to start with, if there
+// is no token named AdminTokenName then one is baked and written to path so
+// that the operator can use it to bake any other tokens they need.
+func (s *Store) EnsureAdmin(path string) (bool, er.R) {
+	exists := false
+	s.tokens.In(func(tokens *map[string]Token) er.R {
+		_, exists = (*tokens)[AdminTokenName]
+		return nil
+	})
+	if exists {
+		return false, nil
+	}
+	secret, err := s.Bake(AdminTokenName, ScopeAdmin)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, []byte(secret+"\n"), 0600); err != nil {
+		s.Revoke(AdminTokenName)
+		return false, er.E(err)
+	}
+	return true, nil
+}
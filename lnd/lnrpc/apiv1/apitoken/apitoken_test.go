@@ -0,0 +1,95 @@
+package apitoken_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1/apitoken"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBakeCheckRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	s, err := apitoken.Open(path)
+	require.Nil(t, err)
+
+	secret, err := s.Bake("shop", apitoken.ScopeInvoice)
+	require.Nil(t, err)
+
+	tok := s.Check(secret)
+	require.NotNil(t, tok)
+	require.Equal(t, "shop", tok.Name)
+	require.Equal(t, apitoken.ScopeInvoice, tok.Scope)
+	require.Nil(t, s.Check(secret+"0"))
+	require.Nil(t, s.Check(""))
+
+	// Names are unique and scopes must exist
+	_, err = s.Bake("shop", apitoken.ScopeAdmin)
+	require.NotNil(t, err)
+	_, err = s.Bake("other", "superuser")
+	require.NotNil(t, err)
+
+	// The secret itself must never be written to disk
+	b, errr := os.ReadFile(path)
+	require.NoError(t, errr)
+	require.False(t, strings.Contains(string(b), secret))
+
+	// Tokens survive reopening the store
+	s2, err := apitoken.Open(path)
+	require.Nil(t, err)
+	require.NotNil(t, s2.Check(secret))
+
+	require.Nil(t, s2.Revoke("shop"))
+	require.Nil(t, s2.Check(secret))
+	require.NotNil(t, s2.Revoke("shop"))
+
+	s3, err := apitoken.Open(path)
+	require.Nil(t, err)
+	require.Nil(t, s3.Check(secret))
+	require.Len(t, s3.List(), 0)
+}
+
+func TestEnsureAdmin(t *testing.T) {
+	dir := t.TempDir()
+	s, err := apitoken.Open(filepath.Join(dir, "tokens.json"))
+	require.Nil(t, err)
+
+	adminPath := filepath.Join(dir, "admin.token")
+	created, err := s.EnsureAdmin(adminPath)
+	require.Nil(t, err)
+	require.True(t, created)
+
+	b, errr := os.ReadFile(adminPath)
+	require.NoError(t, errr)
+	tok := s.Check(strings.TrimSpace(string(b)))
+	require.NotNil(t, tok)
+	require.Equal(t, apitoken.ScopeAdmin, tok.Scope)
+
+	created, err = s.EnsureAdmin(adminPath)
+	require.Nil(t, err)
+	require.False(t, created)
+}
+
+func TestScopePermissions(t *testing.T) {
+	shop := apitoken.Token{Scope: apitoken.ScopeInvoice}
+	require.True(t, shop.Allows(apitoken.PermRead))
+	require.True(t, shop.Allows(apitoken.PermWrite))
+	require.False(t, shop.Allows(apitoken.PermSpend))
+	require.False(t, shop.Allows(apitoken.PermSecret))
+
+	admin := apitoken.Token{Scope: apitoken.ScopeAdmin}
+	for _, p := range apitoken.Permissions {
+		require.True(t, admin.Allows(p))
+	}
+
+	custom := apitoken.Token{Scope: "read, spend"}
+	require.True(t, custom.Allows(apitoken.PermSpend))
+	require.False(t, custom.Allows(apitoken.PermWrite))
+
+	_, err := apitoken.ScopePermissions("read,everything")
+	require.NotNil(t, err)
+	bad := apitoken.Token{Scope: "everything"}
+	require.False(t, bad.Allows(apitoken.PermRead))
+}
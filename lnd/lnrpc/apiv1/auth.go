@@ -0,0 +1,174 @@
+package apiv1
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/rest_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1/apitoken"
+	"github.com/pkt-cash/pktd/pktlog/log"
+)
+
+// The permission which each permission feature requires of the caller
+var permissions = map[help_pb.F]string{
+	help_pb.F_PERM_READ:   apitoken.PermRead,
+	help_pb.F_PERM_WRITE:  apitoken.PermWrite,
+	help_pb.F_PERM_SPEND:  apitoken.PermSpend,
+	help_pb.F_PERM_SECRET: apitoken.PermSecret,
+	help_pb.F_PERM_ADMIN:  apitoken.PermAdmin,
+}
+
+// withPermission makes sure that exactly one permission is in the features,
+// endpoints which do not declare a permission require admin.
+func withPermission(path string, features []help_pb.F) []help_pb.F {
+	hasPermission := false
+	for _, f := range features {
+		if _, ok := permissions[f]; ok {
+			if hasPermission {
+				log.Warnf("Error registering RPC [%s]: [%s]", path,
+					"Permission has been specified more than once")
+			}
+			hasPermission = true
+		}
+	}
+	if !hasPermission {
+		features = append(features, help_pb.F_PERM_ADMIN)
+	}
+	return features
+}
+
+// permissionOf returns the permission feature of an endpoint
+func permissionOf(features []help_pb.F) help_pb.F {
+	for _, f := range features {
+		if _, ok := permissions[f]; ok {
+			return f
+		}
+	}
+	return help_pb.F_PERM_ADMIN
+}
+
+// Used when authentication is disabled
+var noAuthToken = apitoken.Token{Scope: apitoken.ScopeAdmin}
+
+// UseTokens requires every request to carry a token from the store, it must
+// be called before the REST server is started. It also registers the
+// meta/token endpoints which are used to manage the tokens.
+func (a *Apiv1) UseTokens(store *apitoken.Store) {
+	a.internal.tokens = store
+	registerTokenEndpoints(a, store)
+}
+
+func tokenFromRequest(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > len("bearer ") && strings.EqualFold(auth[:len("bearer ")], "bearer ") {
+		return strings.TrimSpace(auth[len("bearer "):])
+	}
+	// Browser websockets can not set headers. Anywhere else the token is not
+	// accepted in the URL because URLs end up in logs and browser history.
+	if websocket.IsWebSocketUpgrade(r) {
+		return r.URL.Query().Get("token")
+	}
+	return ""
+}
+
+// authenticate finds the token which the request was made with
+func (a *Apiv1) authenticate(r *http.Request) (*apitoken.Token, er.R) {
+	if a.internal.tokens == nil {
+		return &noAuthToken, nil
+	}
+	if tok := a.internal.tokens.Check(tokenFromRequest(r)); tok != nil {
+		return tok, nil
+	}
+	return nil, er.New("401 - Missing or invalid token, use header Authorization: Bearer <token>")
+}
+
+// authorize checks whether a token has the permission which an endpoint requires
+func authorize(tok *apitoken.Token, ep *endpoint) er.R {
+	perm := permissions[permissionOf(ep.helpRes.Features)]
+	if tok.Allows(perm) {
+		return nil
+	}
+	return er.Errorf("403 - Token [%s] with scope [%s] does not have permission [%s] "+
+		"which is needed to call [%s]", tok.Name, tok.Scope, perm, ep.path)
+}
+
+func registerTokenEndpoints(a *Apiv1, store *apitoken.Store) {
+	tokenCat := DefineCategory(a, "meta/token",
+		`
+		Management of the tokens which are needed to access the REST API
+
+		Tokens are only needed if pld was started with --restauth.
+		The admin token is created on first start and written to the file admin.token
+		in the data directory. If it is revoked, a new one is created on next start.
+		`)
+	Endpoint(
+		tokenCat,
+		"",
+		`
+		List the REST API tokens
+
+		The tokens themselves are not stored so they can not be shown,
+		only the names and scopes.
+		`,
+		func(_ *rpc_pb.Null) (*rest_pb.ListTokensResponse, er.R) {
+			out := &rest_pb.ListTokensResponse{}
+			for _, t := range store.List() {
+				out.Tokens = append(out.Tokens, &rest_pb.RestToken{
+					Name:    t.Name,
+					Scope:   t.Scope,
+					Created: t.Created.Format(time.RFC3339),
+				})
+			}
+			return out, nil
+		},
+		help_pb.F_PERM_ADMIN,
+	)
+	Endpoint(
+		tokenCat,
+		"bake",
+		`
+		Create a new REST API token
+
+		Each endpoint requires one permission, shown in its help:
+		* read: only looks at the state of the node and wallet
+		* write: changes the state but cannot move funds or reveal secrets
+		* spend: can move funds or sign using the wallet's keys
+		* secret: reveals or imports private keys, or creates or reveals a wallet seed
+		* admin: controls the node itself, such as stopping it or managing tokens
+		The scope is a comma separated list of permissions, e.g. "read,write",
+		or one of these names:
+		* admin: every permission
+		* readonly: read
+		* invoice: read and write, e.g. for a shop which shows balances and creates invoices
+		The token is returned only once, it can not be recovered later.
+		`,
+		func(req *rest_pb.BakeTokenRequest) (*rest_pb.BakeTokenResponse, er.R) {
+			if secret, err := store.Bake(req.Name, req.Scope); err != nil {
+				return nil, err
+			} else {
+				return &rest_pb.BakeTokenResponse{
+					Name:  req.Name,
+					Scope: req.Scope,
+					Token: secret,
+				}, nil
+			}
+		},
+		help_pb.F_PERM_ADMIN,
+	)
+	Endpoint(
+		tokenCat,
+		"revoke",
+		`
+		Revoke a REST API token so that it can no longer be used
+		`,
+		func(req *rest_pb.RevokeTokenRequest) (*rpc_pb.Null, er.R) {
+			return nil, store.Revoke(req.Name)
+		},
+		help_pb.F_PERM_ADMIN,
+	)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/pkt-cash/pktd/generated/pkthelp"
 	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1/apitoken"
 	"github.com/pkt-cash/pktd/pktlog/log"
 )
 
@@ -139,6 +140,7 @@ type endpoint struct {
 	mkReq    func() proto.Message
 	helpRes  help_pb.EndpointHelp
 	f        func(m proto.Message) (proto.Message, er.R)
+	stream   *streamEndpoint
 }
 
 func (e *endpoint) serveHttpOrErr(w http.ResponseWriter, r *http.Request, isJson bool) er.R {
@@ -187,7 +189,11 @@ func (e *endpoint) serveHTTP(w http.ResponseWriter, r *http.Request) er.R {
 			return nil
 		}
 	}
-	if err := e.serveHttpOrErr(w, r, isJson); err != nil {
+	serve := e.serveHttpOrErr
+	if e.wantsStream(r) {
+		serve = e.serveStream
+	}
+	if err := serve(w, r, isJson); err != nil {
 		if err = marshal(w, &rpc_pb.RestError{
 			Message: err.Message(),
 			Stack:   err.Stack(),
@@ -213,8 +219,10 @@ func (e *endpoint) respondHelp(res http.ResponseWriter, r *http.Request) er.R {
 // Apiv1
 
 type apiInt struct {
-	cats  lock.GenRwLock[map[string][]string]
-	funcs lock.GenRwLock[map[string]*endpoint]
+	cats    lock.GenRwLock[map[string][]string]
+	funcs   lock.GenRwLock[map[string]*endpoint]
+	tokens  *apitoken.Store
+	rewrite func(r *http.Request, path string) (string, er.R)
 }
 
 type Apiv1 struct {
@@ -266,24 +274,7 @@ func DefineCategory(
 	return a.cat(path, &description)
 }
 
-func Stream[Q proto.Message, R proto.Message](
-	a *Apiv1,
-	name string,
-	description string,
-	ev *event.Emitter[R],
-	filter func(req Q) (func(R) bool, er.R),
-	features ...help_pb.F,
-) {
-
-}
-
-func Endpoint[Q proto.Message, R proto.Message](
-	a *Apiv1,
-	name string,
-	description string,
-	f func(req Q) (R, er.R),
-	features ...help_pb.F,
-) {
+func (a *Apiv1) epPath(name string) string {
 	path := name
 	if a.category != "" {
 		if path != "" {
@@ -292,7 +283,10 @@ func Endpoint[Q proto.Message, R proto.Message](
 			path = a.category
 		}
 	}
+	return path
+}
 
+func withStability(path string, features []help_pb.F) []help_pb.F {
 	// If no stability, set stability to EXPERIMENTAL
 	hasStability := false
 	for _, f := range features {
@@ -315,6 +309,152 @@ func Endpoint[Q proto.Message, R proto.Message](
 		// No defined stability = experimental
 		features = append(features, help_pb.F_EXPERIMENTAL)
 	}
+	return features
+}
+
+// Stream registers an event stream at a path, each client which connects
+// gets every event from ev which passes the filter that is built from
+// their request.
+// If there is also an Endpoint registered at the same path, the Endpoint
+// is called normally and the stream is used only when the client asks
+// for a stream, see serveStream().
+func Stream[Q proto.Message, R proto.Message](
+	a *Apiv1,
+	name string,
+	description string,
+	ev *event.Emitter[R],
+	filter func(req Q) (func(R) bool, er.R),
+	features ...help_pb.F,
+) {
+	registerStream[Q, R](a, name, description, func(query Q) (*subscription, er.R) {
+		if match, err := filter(query); err != nil {
+			return nil, err
+		} else {
+			return subscribe(ev, match), nil
+		}
+	}, features)
+}
+
+// StreamSource registers a stream where each client gets events from its own
+// source rather than from a shared Emitter, for example when the events come
+// from a subscription which is specific to the client's request.
+// open is called for each client, it returns a channel of events which it
+// must stop sending to once stop is closed, and should close if there will be
+// no more events.
+func StreamSource[Q proto.Message, R proto.Message](
+	a *Apiv1,
+	name string,
+	description string,
+	open func(req Q, stop <-chan struct{}) (<-chan R, er.R),
+	features ...help_pb.F,
+) {
+	StreamSourceWithError(a, name, description,
+		func(req Q, stop <-chan struct{}, _ func(err er.R)) (<-chan R, er.R) {
+			return open(req, stop)
+		},
+		features...)
+}
+
+// StreamSourceWithError is like StreamSource except that the source can end
+// the stream with an error by calling fail before it closes the channel. The
+// client then gets the error as it would if it had fallen behind, in the
+// Pld-Stream-Error trailer, an SSE error event or the last websocket message.
+func StreamSourceWithError[Q proto.Message, R proto.Message](
+	a *Apiv1,
+	name string,
+	description string,
+	open func(req Q, stop <-chan struct{}, fail func(err er.R)) (<-chan R, er.R),
+	features ...help_pb.F,
+) {
+	registerStream[Q, R](a, name, description, func(query Q) (*subscription, er.R) {
+		return subscribeSource(func(stop <-chan struct{}, fail func(err er.R)) (<-chan R, er.R) {
+			return open(query, stop, fail)
+		})
+	}, features)
+}
+
+func registerStream[Q proto.Message, R proto.Message](
+	a *Apiv1,
+	name string,
+	description string,
+	sub func(req Q) (*subscription, er.R),
+	features []help_pb.F,
+) {
+	path := a.epPath(name)
+	features = withPermission(path, withStability(path, append(features, help_pb.F_STREAMING)))
+
+	log.Infof("Registering stream [%s]", path)
+	reqHt, err := pkthelp.Help(toPm[Q]())
+	if err != nil {
+		log.Warnf("Error registering stream [%s]: [%s]", path, err)
+		return
+	}
+	resHt, err := pkthelp.Help(toPm[R]())
+	if err != nil {
+		log.Warnf("Error registering stream [%s]: [%s]", path, err)
+		return
+	}
+	stream := &streamEndpoint{
+		mkReq: toPm[Q],
+		subscribe: func(m proto.Message) (*subscription, er.R) {
+			if query, ok := m.(Q); !ok {
+				panic("invalid type")
+			} else {
+				return sub(query)
+			}
+		},
+	}
+	a.internal.funcs.W().In(func(funcs *map[string]*endpoint) er.R {
+		ep := &endpoint{
+			path:     path,
+			mkReq:    toPm[Q],
+			category: a.category,
+			helpRes: help_pb.EndpointHelp{
+				Path:        _api_v1_ + path,
+				Description: trimSplit(description),
+				Request:     convertHelpType(reqHt),
+				Response:    convertHelpType(resHt),
+				Features:    features,
+			},
+		}
+		if e, ok := (*funcs)[path]; ok {
+			// Make a new one from the old one's fields because someone might
+			// be using the old one
+			ep = &endpoint{
+				path:     e.path,
+				mkReq:    e.mkReq,
+				category: e.category,
+				f:        e.f,
+				helpRes: help_pb.EndpointHelp{
+					Path:        e.helpRes.Path,
+					Description: e.helpRes.Description,
+					Request:     e.helpRes.Request,
+					Response:    e.helpRes.Response,
+					Features:    e.helpRes.Features,
+				},
+			}
+			if !util.Contains(ep.helpRes.Features, help_pb.F_STREAMING) {
+				ep.helpRes.Features = append(
+					append([]help_pb.F{}, ep.helpRes.Features...), help_pb.F_STREAMING)
+			}
+		}
+		ep.stream = stream
+		ep.helpRes.StreamRequest = convertHelpType(reqHt)
+		ep.helpRes.StreamResponse = convertHelpType(resHt)
+		(*funcs)[path] = ep
+		return nil
+	})
+}
+
+func Endpoint[Q proto.Message, R proto.Message](
+	a *Apiv1,
+	name string,
+	description string,
+	f func(req Q) (R, er.R),
+	features ...help_pb.F,
+) {
+	path := a.epPath(name)
+	features = withPermission(path, withStability(path, features))
 
 	// We're not going to return an error from here because
 	// nobody wants to handle runtime errors while setting up
@@ -334,7 +474,7 @@ func Endpoint[Q proto.Message, R proto.Message](
 		return
 	}
 	a.internal.funcs.W().In(func(funcs *map[string]*endpoint) er.R {
-		(*funcs)[path] = &endpoint{
+		ep := &endpoint{
 			path:     path,
 			mkReq:    toPm[Q],
 			category: a.category,
@@ -353,10 +493,29 @@ func Endpoint[Q proto.Message, R proto.Message](
 				}
 			},
 		}
+		// Keep the stream if one was registered at this path first
+		if old, ok := (*funcs)[path]; ok && old.stream != nil {
+			ep.stream = old.stream
+			ep.helpRes.StreamRequest = old.helpRes.StreamRequest
+			ep.helpRes.StreamResponse = old.helpRes.StreamResponse
+			ep.helpRes.Features = append(ep.helpRes.Features, help_pb.F_STREAMING)
+		}
+		(*funcs)[path] = ep
 		return nil
 	})
 }
 
+// HasEndpoint returns true if there is an endpoint at path, which is relative
+// to /api/v1/ rather than to the category.
+func HasEndpoint(a *Apiv1, path string) bool {
+	found := false
+	a.internal.funcs.R().In(func(funcs *map[string]*endpoint) er.R {
+		_, found = (*funcs)[path]
+		return nil
+	})
+	return found
+}
+
 func Deregister(a *Apiv1, path string) er.R {
 	return a.internal.funcs.W().In(func(eps *map[string]*endpoint) er.R {
 		_, ok := (*eps)[path]
@@ -368,11 +527,56 @@ func Deregister(a *Apiv1, path string) er.R {
 	})
 }
 
+// DeregisterCategory removes every endpoint in a category and its
+// sub-categories, along with the categories themselves.
+func DeregisterCategory(a *Apiv1) er.R {
+	if a.category == "" {
+		return er.New("cannot deregister the root category")
+	}
+	inCat := func(path string) bool {
+		return path == a.category || strings.HasPrefix(path, a.category+"/")
+	}
+	if err := a.internal.funcs.W().In(func(eps *map[string]*endpoint) er.R {
+		for path := range *eps {
+			if inCat(path) {
+				delete(*eps, path)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return a.internal.cats.W().In(func(cats *map[string][]string) er.R {
+		for path := range *cats {
+			if inCat(path) {
+				delete(*cats, path)
+			}
+		}
+		return nil
+	})
+}
+
+// UseRewrite sets a function which may change the path of each request before
+// the endpoint is looked up, for example to send it to a different wallet
+// depending on a header. If it returns an error then the request fails with
+// that error. It must be called before the REST server is started.
+func (a *Apiv1) UseRewrite(rewrite func(r *http.Request, path string) (string, er.R)) {
+	a.internal.rewrite = rewrite
+}
+
+func (a *Apiv1) route(r *http.Request, path string) (string, er.R) {
+	if a.internal.rewrite == nil {
+		return path, nil
+	}
+	return a.internal.rewrite(r, path)
+}
+
 type epInfo struct {
-	shortDesc string
-	category  string
-	name      string
-	helpPath  string
+	shortDesc  string
+	category   string
+	name       string
+	helpPath   string
+	permission help_pb.F
 }
 
 func (a *Apiv1) openApiHelp() (*help_pb.OpenAPI, er.R) {
@@ -401,7 +605,7 @@ func (a *Apiv1) openApiHelp() (*help_pb.OpenAPI, er.R) {
 				txt(_api_v1_ + ep.path + ":")
 				tab(func() {
 					fakeReq := ep.mkReq()
-					if _, ok := fakeReq.(*rpc_pb.Null); ok {
+					if _, ok := fakeReq.(*rpc_pb.Null); ok || ep.f == nil {
 						// Null input means we GET, otherwise we post
 						txt("get:")
 					} else {
@@ -419,6 +623,7 @@ func (a *Apiv1) openApiHelp() (*help_pb.OpenAPI, er.R) {
 								txt(line)
 							}
 						})
+						txt("x-pld-permission: %s", permissions[permissionOf(ep.helpRes.Features)])
 					})
 				})
 			}
@@ -439,9 +644,10 @@ func (a *Apiv1) masterHelp() (*help_pb.Category, er.R) {
 					func() string { return ep.helpRes.Description[0] },
 					"<UNDEFINED>",
 				),
-				category: ep.category,
-				name:     epName(ep.path, ep.category),
-				helpPath: _api_v1_ + "help/" + ep.path,
+				category:   ep.category,
+				name:       epName(ep.path, ep.category),
+				helpPath:   _api_v1_ + "help/" + ep.path,
+				permission: permissionOf(ep.helpRes.Features),
 			})
 		}
 		return nil
@@ -483,8 +689,9 @@ func (a *Apiv1) masterHelp() (*help_pb.Category, er.R) {
 	for _, epi := range eps {
 		if cat, ok := categorized[epi.category]; ok {
 			cat.Endpoints[epi.name] = &help_pb.EndpointSimple{
-				HelpPath: epi.helpPath,
-				Brief:    epi.shortDesc,
+				HelpPath:   epi.helpPath,
+				Brief:      epi.shortDesc,
+				Permission: epi.permission,
 			}
 		} else {
 			log.Warnf("Uncategorized endpoint: [%s // %s]", epi.category, epi.name)
@@ -518,12 +725,23 @@ func New() (*Apiv1, *mux.Router) {
 			send404(res, r.URL.Path)
 			return
 		}
+		tok, err := out.authenticate(r)
+		if err != nil {
+			res.Header().Set("WWW-Authenticate", "Bearer")
+			respondError(res, http.StatusUnauthorized, err.Message())
+			return
+		}
 		path := strings.Replace(r.URL.Path, _api_v1_, "", 1)
 		isHelp := false
 		if strings.Index(path, "help/") == 0 {
 			isHelp = true
 			path = strings.Replace(path, "help/", "", 1)
 		}
+		path, err = out.route(r, path)
+		if err != nil {
+			respondError(res, http.StatusBadRequest, err.Message())
+			return
+		}
 		var ep *endpoint
 		out.internal.funcs.R().In(func(funcs *map[string]*endpoint) er.R {
 			if e, ok := (*funcs)[path]; ok {
@@ -531,11 +749,12 @@ func New() (*Apiv1, *mux.Router) {
 			}
 			return nil
 		})
-		var err er.R
 		if ep == nil {
 			err = send404(res, r.URL.Path)
 		} else if isHelp {
 			err = ep.respondHelp(res, r)
+		} else if err = authorize(tok, ep); err != nil {
+			err = respondError(res, http.StatusForbidden, err.Message())
 		} else {
 			err = ep.serveHTTP(res, r)
 		}
@@ -546,7 +765,12 @@ func New() (*Apiv1, *mux.Router) {
 
 	//	add a handler for websocket endpoint
 	r.Handle(_api_v1_+"websocket", http.HandlerFunc(func(httpResponse http.ResponseWriter, httpRequest *http.Request) {
-		webSocketHandler(&out, httpResponse, httpRequest)
+		if tok, err := out.authenticate(httpRequest); err != nil {
+			httpResponse.Header().Set("WWW-Authenticate", "Bearer")
+			respondError(httpResponse, http.StatusUnauthorized, err.Message())
+		} else {
+			webSocketHandler(&out, tok, httpResponse, httpRequest)
+		}
 	}))
 
 	Endpoint(
@@ -556,10 +780,13 @@ func New() (*Apiv1, *mux.Router) {
 		Special endpoint for initiating a websocket connection
 		
 		This allows further endpoint requests, including streaming endpoints, over the websocket.
+		When --restauth is set, a browser which can not set the Authorization header may
+		pass the token as ?token=<token>, this is only accepted when opening a websocket.
 		`,
 		func(_ *rpc_pb.Null) (*rpc_pb.Null, er.R) {
 			return nil, nil
 		},
+		help_pb.F_PERM_READ,
 	)
 
 	// Finally, register the master help endpoint
@@ -574,6 +801,7 @@ func New() (*Apiv1, *mux.Router) {
 		func(_ *rpc_pb.Null) (*help_pb.Category, er.R) {
 			return out.masterHelp()
 		},
+		help_pb.F_PERM_READ,
 	)
 
 	Endpoint(
@@ -585,6 +813,7 @@ func New() (*Apiv1, *mux.Router) {
 		func(_ *rpc_pb.Null) (*help_pb.OpenAPI, er.R) {
 			return out.openApiHelp()
 		},
+		help_pb.F_PERM_READ,
 	)
 
 	return &out, r
@@ -0,0 +1,303 @@
+package apiv1
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/event"
+	"github.com/pkt-cash/pktd/btcutil/lock"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/pktlog/log"
+)
+
+// Number of events which can be waiting to be written to a single subscriber
+// before the subscriber is considered too slow and is disconnected.
+const subscriberDepth = 64
+
+// How often to send something to an idle HTTP stream so that dead clients
+// are detected and proxies do not time out the connection.
+const streamKeepalive = time.Second * 30
+
+// Trailer which carries the error, if any, which ended an HTTP stream.
+const streamErrorTrailer = "Pld-Stream-Error"
+
+type streamEndpoint struct {
+	mkReq     func() proto.Message
+	subscribe func(req proto.Message) (*subscription, er.R)
+}
+
+// subscription is one client's view of an event stream.
+// Events which pass the client's filter are queued in events until the client
+// is ready to receive them, if the queue fills up then the subscription is
+// dropped rather than slowing down the emitter or the other subscribers.
+type subscription struct {
+	events   chan proto.Message
+	stop     event.Emitter[struct{}]
+	ended    chan struct{}
+	overflow lock.AtomicBool
+
+	// err is the error which the source ended with, if any. It is set
+	// before events is closed so it can be read once events is closed.
+	err er.R
+}
+
+func newSubscription() *subscription {
+	return &subscription{
+		events: make(chan proto.Message, subscriberDepth),
+		stop:   event.NewEmitter[struct{}]("apiv1 subscription stop"),
+		ended:  make(chan struct{}),
+	}
+}
+
+func subscribe[R proto.Message](ev *event.Emitter[R], match func(R) bool) *subscription {
+	sub := newSubscription()
+	var ready sync.WaitGroup
+	var done sync.WaitGroup
+	ready.Add(1)
+	event.GoWg(&done, func(loop *event.Loop) {
+		ev.On(loop, func(r R) {
+			if !match(r) {
+				return
+			}
+			select {
+			case sub.events <- r:
+			default:
+				sub.overflow.Store(true)
+				loop.Quit()
+			}
+		})
+		sub.stop.On(loop, func(_ struct{}) {
+			loop.Quit()
+		})
+		ready.Done()
+	})
+	go func() {
+		done.Wait()
+		close(sub.ended)
+		close(sub.events)
+	}()
+	ready.Wait()
+	return sub
+}
+
+// subscribeSource is like subscribe except that the events come from a channel
+// which belongs to this subscriber alone, so a slow client holds up its source
+// instead of being disconnected. open is given a channel which is
+// closed when the subscription ends, the source channel which it returns should
+// be closed by its owner if there will be no more events. If the source fails,
+// it calls fail with the error before closing the channel.
+func subscribeSource[R proto.Message](
+	open func(stop <-chan struct{}, fail func(err er.R)) (<-chan R, er.R),
+) (*subscription, er.R) {
+	sub := newSubscription()
+	var ready sync.WaitGroup
+	var done sync.WaitGroup
+	ready.Add(1)
+	event.GoWg(&done, func(loop *event.Loop) {
+		sub.stop.On(loop, func(_ struct{}) {
+			loop.Quit()
+		})
+		ready.Done()
+	})
+	go func() {
+		done.Wait()
+		close(sub.ended)
+	}()
+	ready.Wait()
+
+	var srcErr er.R
+	src, err := open(sub.ended, func(err er.R) { srcErr = err })
+	if err != nil {
+		sub.cancel()
+		return nil, err
+	}
+	go func() {
+		defer close(sub.events)
+		for {
+			select {
+			case r, ok := <-src:
+				if !ok {
+					// Closing src happens after fail so srcErr can be read
+					sub.err = srcErr
+					sub.cancel()
+					return
+				}
+				// The source belongs to this subscriber alone so it can be
+				// made to wait for a slow client rather than dropping it.
+				select {
+				case sub.events <- r:
+				case <-sub.ended:
+					return
+				}
+			case <-sub.ended:
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// cancel stops the subscription, it is safe to call from any goroutine and
+// more than once.
+func (s *subscription) cancel() {
+	s.stop.TryEmit(struct{}{})
+}
+
+// run passes each event to send until the subscription ends, send fails, or
+// done is closed, and returns the error which ended it, if any. If ping is non-nil, it is called whenever the stream has been
+// idle for streamKeepalive.
+func (s *subscription) run(
+	done <-chan struct{},
+	send func(m proto.Message) er.R,
+	ping func() er.R,
+) er.R {
+	defer s.cancel()
+	ticker := time.NewTicker(streamKeepalive)
+	defer ticker.Stop()
+	for {
+		select {
+		case m, ok := <-s.events:
+			if !ok {
+				if s.overflow.Load() {
+					return er.Errorf("Subscriber fell more than [%d] events behind, "+
+						"disconnecting", subscriberDepth)
+				}
+				return s.err
+			}
+			if err := send(m); err != nil {
+				return err
+			}
+			ticker.Reset(streamKeepalive)
+		case <-ticker.C:
+			if ping != nil {
+				if err := ping(); err != nil {
+					return err
+				}
+			}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// wantsStream returns true if the request should be answered with a stream
+// rather than a single response. Endpoints which only stream always stream,
+// endpoints with both a stream and a normal handler stream if asked to by the
+// Accept header.
+func (e *endpoint) wantsStream(r *http.Request) bool {
+	if e.stream == nil {
+		return false
+	}
+	if e.f == nil {
+		return true
+	}
+	accept := strings.ToLower(r.Header.Get("Accept"))
+	return strings.Contains(accept, "text/event-stream") ||
+		strings.Contains(accept, "application/x-ndjson")
+}
+
+var streamJson = protojson.MarshalOptions{
+	EmitUnpopulated: true,
+	UseEnumNumbers:  false,
+}
+
+// serveStream answers an HTTP request with one of three framings:
+// * text/event-stream: server-sent events with one JSON event per data line
+// * application/x-ndjson: one JSON event per line
+// * application/protobuf: each event prefixed with its length as a varint
+func (e *endpoint) serveStream(w http.ResponseWriter, r *http.Request, isJson bool) er.R {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return er.New("500 - This connection does not support streaming")
+	}
+	if r.Method != "POST" && r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return er.New("405 - Method not allowed: " + r.Method)
+	}
+
+	// Browser EventSource clients can only GET, so a stream may be requested
+	// with an empty request.
+	req := e.stream.mkReq()
+	if _, ok := req.(*rpc_pb.Null); !ok && r.Method == "POST" {
+		if err := unmarshal(r, req, isJson); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return err
+		}
+	}
+	sub, err := e.stream.subscribe(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return err
+	}
+
+	sse := strings.Contains(strings.ToLower(r.Header.Get("Accept")), "text/event-stream")
+	var send func(m proto.Message) er.R
+	var ping func() er.R
+	write := func(b []byte) er.R {
+		if _, err := w.Write(b); err != nil {
+			return er.E(err)
+		}
+		flusher.Flush()
+		return nil
+	}
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		send = func(m proto.Message) er.R {
+			if b, err := streamJson.Marshal(m); err != nil {
+				return er.E(err)
+			} else {
+				return write([]byte("data: " + string(b) + "\n\n"))
+			}
+		}
+		ping = func() er.R { return write([]byte(": keepalive\n\n")) }
+	} else if isJson {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		send = func(m proto.Message) er.R {
+			if b, err := streamJson.Marshal(m); err != nil {
+				return er.E(err)
+			} else {
+				return write(append(b, '\n'))
+			}
+		}
+		ping = func() er.R { return write([]byte("\n")) }
+	} else {
+		w.Header().Set("Content-Type", "application/protobuf")
+		send = func(m proto.Message) er.R {
+			if b, err := proto.Marshal(m); err != nil {
+				return er.E(err)
+			} else {
+				return write(append(protowire.AppendVarint(nil, uint64(len(b))), b...))
+			}
+		}
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set("Trailer", streamErrorTrailer)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	log.Debugf("Stream [%s] opened by [%s]", e.path, r.RemoteAddr)
+	if err := sub.run(r.Context().Done(), send, ping); err != nil {
+		log.Debugf("Stream [%s] to [%s] ended: [%s]", e.path, r.RemoteAddr, err)
+		w.Header().Set(streamErrorTrailer, err.Message())
+		if sse {
+			if b, err := streamJson.Marshal(&rpc_pb.RestError{
+				Message: err.Message(),
+				Stack:   err.Stack(),
+			}); err == nil {
+				write([]byte("event: error\ndata: " + string(b) + "\n\n"))
+			}
+		}
+	} else {
+		log.Debugf("Stream [%s] to [%s] closed", e.path, r.RemoteAddr)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package apiv1
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/event"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+)
+
+type testStream struct {
+	ev  event.Emitter[*rpc_pb.GetNewAddressResponse]
+	srv *httptest.Server
+}
+
+// newTestStream serves a stream at test/events which sends every event which
+// is emitted, unless the request is legacy in which case it only sends the
+// events whose address starts with 1.
+func newTestStream(t *testing.T) *testStream {
+	ts := &testStream{ev: event.NewEmitter[*rpc_pb.GetNewAddressResponse]("test events")}
+	a, r := New()
+	Stream(a, "test/events", "Events for testing", &ts.ev,
+		func(req *rpc_pb.GetNewAddressRequest) (func(*rpc_pb.GetNewAddressResponse) bool, er.R) {
+			return func(ev *rpc_pb.GetNewAddressResponse) bool {
+				return !req.Legacy || strings.HasPrefix(ev.Address, "1")
+			}, nil
+		},
+		help_pb.F_PERM_READ,
+	)
+	ts.srv = httptest.NewServer(r)
+	t.Cleanup(ts.srv.Close)
+	return ts
+}
+
+// waitListeners waits until the stream has n subscribers
+func (ts *testStream) waitListeners(t *testing.T, n int) {
+	require.Eventually(t, func() bool { return ts.ev.Listeners() == n },
+		5*time.Second, time.Millisecond)
+}
+
+func (ts *testStream) emit(t *testing.T, addrs ...string) {
+	for _, a := range addrs {
+		require.Nil(t, ts.ev.TryEmit(&rpc_pb.GetNewAddressResponse{Address: a}))
+	}
+}
+
+func (ts *testStream) open(t *testing.T, ctx context.Context, accept, contentType string, body []byte) *http.Response {
+	req, errr := http.NewRequestWithContext(ctx, "POST", ts.srv.URL+"/api/v1/test/events",
+		bytes.NewReader(body))
+	require.NoError(t, errr)
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Content-Type", contentType)
+	resp, errr := http.DefaultClient.Do(req)
+	require.NoError(t, errr)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	// The subscription exists before the headers are sent
+	ts.waitListeners(t, 1)
+	return resp
+}
+
+func TestStreamNdjson(t *testing.T) {
+	ts := newTestStream(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := ts.open(t, ctx, "application/x-ndjson", "application/json", []byte(`{"legacy":true}`))
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	ts.emit(t, "pkt1qa", "1b", "1c")
+	r := bufio.NewReader(resp.Body)
+	for _, want := range []string{"1b", "1c"} {
+		line, errr := r.ReadBytes('\n')
+		require.NoError(t, errr)
+		var ev rpc_pb.GetNewAddressResponse
+		require.NoError(t, protojson.Unmarshal(line, &ev))
+		require.Equal(t, want, ev.Address)
+	}
+
+	// When the client goes away, the subscription ends
+	cancel()
+	resp.Body.Close()
+	ts.waitListeners(t, 0)
+}
+
+func TestStreamSse(t *testing.T) {
+	ts := newTestStream(t)
+	// EventSource clients can only GET and send no request
+	req, errr := http.NewRequest("GET", ts.srv.URL+"/api/v1/test/events", nil)
+	require.NoError(t, errr)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, errr := http.DefaultClient.Do(req)
+	require.NoError(t, errr)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	ts.waitListeners(t, 1)
+
+	ts.emit(t, "pkt1qa", "1b")
+	r := bufio.NewReader(resp.Body)
+	for _, want := range []string{"pkt1qa", "1b"} {
+		line, errr := r.ReadString('\n')
+		require.NoError(t, errr)
+		require.True(t, strings.HasPrefix(line, "data: "), line)
+		var ev rpc_pb.GetNewAddressResponse
+		require.NoError(t, protojson.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev))
+		require.Equal(t, want, ev.Address)
+		blank, errr := r.ReadString('\n')
+		require.NoError(t, errr)
+		require.Equal(t, "\n", blank)
+	}
+}
+
+func TestStreamProtobuf(t *testing.T) {
+	ts := newTestStream(t)
+	body, errr := proto.Marshal(&rpc_pb.GetNewAddressRequest{})
+	require.NoError(t, errr)
+	resp := ts.open(t, context.Background(), "application/x-ndjson", "application/protobuf", body)
+	defer resp.Body.Close()
+	require.Equal(t, "application/protobuf", resp.Header.Get("Content-Type"))
+
+	ts.emit(t, "pkt1qa", "1b")
+	r := bufio.NewReader(resp.Body)
+	for _, want := range []string{"pkt1qa", "1b"} {
+		l, errr := binary.ReadUvarint(r)
+		require.NoError(t, errr)
+		b := make([]byte, l)
+		_, errr = io.ReadFull(r, b)
+		require.NoError(t, errr)
+		var ev rpc_pb.GetNewAddressResponse
+		require.NoError(t, proto.Unmarshal(b, &ev))
+		require.Equal(t, want, ev.Address)
+	}
+}
+
+// overflow emits events which are too big to fit in the socket buffers while
+// the client is not reading, so the subscriber falls behind and is dropped.
+func (ts *testStream) overflow(t *testing.T) {
+	big := &rpc_pb.GetNewAddressResponse{Address: strings.Repeat("x", 1<<20)}
+	for i := 0; i < 200 && ts.ev.Listeners() > 0; i++ {
+		ts.ev.TryEmit(big)
+		time.Sleep(time.Millisecond)
+	}
+	ts.waitListeners(t, 0)
+}
+
+func TestStreamOverflowTrailer(t *testing.T) {
+	ts := newTestStream(t)
+	resp := ts.open(t, context.Background(), "application/x-ndjson", "application/json", []byte("{}"))
+	defer resp.Body.Close()
+	ts.overflow(t)
+
+	// The events which were queued are still sent, then the stream ends and
+	// the reason is in the trailer.
+	n, errr := io.Copy(io.Discard, resp.Body)
+	require.NoError(t, errr)
+	require.Greater(t, n, int64(subscriberDepth<<20))
+	require.Contains(t, resp.Trailer.Get(streamErrorTrailer), "fell more than [64] events behind")
+}
+
+func TestStreamOverflowSse(t *testing.T) {
+	ts := newTestStream(t)
+	resp := ts.open(t, context.Background(), "text/event-stream", "application/json", []byte("{}"))
+	defer resp.Body.Close()
+	ts.overflow(t)
+
+	b, errr := io.ReadAll(resp.Body)
+	require.NoError(t, errr)
+	i := bytes.LastIndex(b, []byte("event: error\ndata: "))
+	require.True(t, i >= 0, "no error event")
+	var re rpc_pb.RestError
+	line := bytes.TrimPrefix(b[i:], []byte("event: error\ndata: "))
+	require.NoError(t, protojson.Unmarshal(bytes.TrimSpace(line), &re))
+	require.Contains(t, re.Message, "fell more than [64] events behind")
+}
+
+func TestSubscriptionOverflow(t *testing.T) {
+	ev := event.NewEmitter[*rpc_pb.GetNewAddressResponse]("test events")
+	sub := subscribe(&ev, func(*rpc_pb.GetNewAddressResponse) bool { return true })
+	for i := 0; i < subscriberDepth+1; i++ {
+		require.Nil(t, ev.TryEmit(&rpc_pb.GetNewAddressResponse{}))
+	}
+	<-sub.ended
+	require.Equal(t, 0, ev.Listeners())
+
+	// Every event which fit in the queue is still delivered
+	sent := 0
+	err := sub.run(make(chan struct{}), func(proto.Message) er.R {
+		sent++
+		return nil
+	}, nil)
+	require.NotNil(t, err)
+	require.Equal(t, subscriberDepth, sent)
+}
+
+func TestStreamOnlyGetsNoNormalCall(t *testing.T) {
+	ts := newTestStream(t)
+	// A stream-only endpoint streams even if the client did not ask for it
+	resp := ts.open(t, context.Background(), "", "application/json", []byte("{}"))
+	defer resp.Body.Close()
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+}
+
+func TestStreamAfterEndpoint(t *testing.T) {
+	a, r := New()
+	Endpoint(a, "test/both", "Call or stream",
+		func(req *rpc_pb.GetNewAddressRequest) (*rpc_pb.GetNewAddressResponse, er.R) {
+			return &rpc_pb.GetNewAddressResponse{Address: "called"}, nil
+		},
+		help_pb.F_PERM_READ,
+	)
+	ev := event.NewEmitter[*rpc_pb.GetNewAddressResponse]("test events")
+	Stream(a, "test/both", "Call or stream", &ev,
+		func(req *rpc_pb.GetNewAddressRequest) (func(*rpc_pb.GetNewAddressResponse) bool, er.R) {
+			return func(*rpc_pb.GetNewAddressResponse) bool { return true }, nil
+		},
+		help_pb.F_PERM_READ,
+	)
+	var ep *endpoint
+	a.internal.funcs.R().In(func(funcs *map[string]*endpoint) er.R {
+		ep = (*funcs)["test/both"]
+		return nil
+	})
+	require.NotNil(t, ep.f)
+	require.NotNil(t, ep.stream)
+	require.NotNil(t, ep.helpRes.StreamResponse)
+	streaming := 0
+	for _, f := range ep.helpRes.Features {
+		if f == help_pb.F_STREAMING {
+			streaming++
+		}
+	}
+	require.Equal(t, 1, streaming)
+
+	// Without asking for a stream, the endpoint is called normally
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	resp, errr := http.Post(srv.URL+"/api/v1/test/both", "application/json",
+		strings.NewReader("{}"))
+	require.NoError(t, errr)
+	defer resp.Body.Close()
+	b, errr := io.ReadAll(resp.Body)
+	require.NoError(t, errr)
+	var res rpc_pb.GetNewAddressResponse
+	require.NoError(t, protojson.Unmarshal(b, &res))
+	require.Equal(t, "called", res.Address)
+}
+
+// newFailingSource serves a source at test/source which sends the addresses
+// and then fails, or ends normally if the request is legacy.
+func newFailingSource(t *testing.T, addrs ...string) *httptest.Server {
+	a, r := New()
+	StreamSourceWithError(a, "test/source", "Events which end with an error",
+		func(req *rpc_pb.GetNewAddressRequest, stop <-chan struct{},
+			fail func(err er.R)) (<-chan *rpc_pb.GetNewAddressResponse, er.R) {
+			out := make(chan *rpc_pb.GetNewAddressResponse)
+			go func() {
+				defer close(out)
+				for _, a := range addrs {
+					select {
+					case out <- &rpc_pb.GetNewAddressResponse{Address: a}:
+					case <-stop:
+						return
+					}
+				}
+				if !req.Legacy {
+					fail(er.New("database is closed"))
+				}
+			}()
+			return out, nil
+		},
+		help_pb.F_PERM_READ,
+	)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStreamSourceError(t *testing.T) {
+	srv := newFailingSource(t, "1a", "1b")
+	for _, legacy := range []bool{false, true} {
+		body := `{"legacy":false}`
+		if legacy {
+			body = `{"legacy":true}`
+		}
+		resp, errr := http.Post(srv.URL+"/api/v1/test/source", "application/json",
+			strings.NewReader(body))
+		require.NoError(t, errr)
+		b, errr := io.ReadAll(resp.Body)
+		require.NoError(t, errr)
+		resp.Body.Close()
+		lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+		require.Len(t, lines, 2)
+		if legacy {
+			require.Empty(t, resp.Trailer.Get(streamErrorTrailer))
+		} else {
+			require.Contains(t, resp.Trailer.Get(streamErrorTrailer), "database is closed")
+		}
+	}
+
+	req, errr := http.NewRequest("GET", srv.URL+"/api/v1/test/source", nil)
+	require.NoError(t, errr)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, errr := http.DefaultClient.Do(req)
+	require.NoError(t, errr)
+	defer resp.Body.Close()
+	b, errr := io.ReadAll(resp.Body)
+	require.NoError(t, errr)
+	require.Contains(t, string(b), "event: error\ndata: ")
+	require.Contains(t, string(b), "database is closed")
+}
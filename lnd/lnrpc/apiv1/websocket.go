@@ -4,18 +4,30 @@ import (
 	"encoding/json"
 	"net/http"
 	"reflect"
+	"sync"
 
 	"github.com/gorilla/websocket"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/lock"
 	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/rest_pb"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1/apitoken"
 	"github.com/pkt-cash/pktd/pktlog/log"
 	jsonpb "google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
-type websocketConn websocket.Conn
+// websocketConn is one client's websocket session, many streams may be
+// writing to it at once so all writes must go through write().
+type websocketConn struct {
+	conn      *websocket.Conn
+	writeLock sync.Mutex
+	subs      lock.GenMutex[map[string]*subscription]
+	done      chan struct{}
+	tok       *apitoken.Token
+	req       *http.Request
+}
 
 type WebSocketJSonRequest struct {
 	Endpoint  string          `json:"endpoint,omitempty"`
@@ -33,7 +45,7 @@ type WebSocketJSonResponse struct {
 
 var upgrader = websocket.Upgrader{}
 
-func webSocketHandler(ctx *Apiv1, httpResponse http.ResponseWriter, httpRequest *http.Request) {
+func webSocketHandler(ctx *Apiv1, tok *apitoken.Token, httpResponse http.ResponseWriter, httpRequest *http.Request) {
 	//	upgrade raw HTTP connection to a websocket
 	conn, err := upgrader.Upgrade(httpResponse, httpRequest, nil)
 	if err != nil {
@@ -44,7 +56,15 @@ func webSocketHandler(ctx *Apiv1, httpResponse http.ResponseWriter, httpRequest
 	defer conn.Close()
 
 	//	webSocket communication loop
-	var wsConn websocketConn = websocketConn(*conn)
+	wsConn := &websocketConn{
+		conn: conn,
+		subs: lock.NewGenMutex(make(map[string]*subscription), "websocketConn.subs"),
+		done: make(chan struct{}),
+		tok:  tok,
+		req:  httpRequest,
+	}
+	// Once the socket is gone, every stream on it ends
+	defer close(wsConn.done)
 
 	for {
 		msgType, message, err := conn.ReadMessage()
@@ -88,6 +108,12 @@ func pWsError(err er.R) *rest_pb.WebSocketProtobufResponse_Error {
 	}
 }
 
+func (conn *websocketConn) write(msgType int, msg []byte) er.R {
+	conn.writeLock.Lock()
+	defer conn.writeLock.Unlock()
+	return er.E(conn.conn.WriteMessage(msgType, msg))
+}
+
 func (conn *websocketConn) errorClose(err er.R) {
 	resp := WebSocketJSonResponse{
 		RequestId: "FATAL ERROR",
@@ -96,10 +122,10 @@ func (conn *websocketConn) errorClose(err er.R) {
 	}
 	if respPayload, err := jsoniter.Marshal(&resp); err != nil {
 		log.Errorf("Unable to marshal error message: [%s]", err)
-	} else if err := (*websocket.Conn)(conn).WriteMessage(websocket.TextMessage, respPayload); err != nil {
+	} else if err := conn.write(websocket.TextMessage, respPayload); err != nil {
 		log.Errorf("Unable to send error message: [%s]", err)
 	}
-	if err := (*websocket.Conn)(conn).Close(); err != nil {
+	if err := conn.conn.Close(); err != nil {
 		log.Errorf("Unable to close websocket: [%s]", err)
 	}
 }
@@ -120,15 +146,63 @@ func (conn *websocketConn) handleJsonMessage(ctx *Apiv1, req []byte) {
 		HasMore:   false,
 		Payload:   nil,
 	}
-	var endpt *endpoint
-	ctx.internal.funcs.R().In(func(funcs *map[string]*endpoint) er.R {
-		if ep, ok := (*funcs)[webSocketReq.Endpoint]; ok {
-			endpt = ep
+	send := func(resp *WebSocketJSonResponse) er.R {
+		respPayload, err := jsoniter.Marshal(resp)
+		if err != nil {
+			log.Errorf("Unable to marshal response to req: [%s]: [%s]", webSocketReq.RequestId, err)
+			return er.E(err)
+		}
+		//	write the result message to the webSocket client
+		if err := conn.write(websocket.TextMessage, respPayload); err != nil {
+			log.Errorf("Cannot write error message to webSocket client: [%s]", err)
+			return err
 		}
 		return nil
-	})
-	if endpt == nil {
+	}
+	endpt, routeErr := ctx.wsEndpoint(conn.req, webSocketReq.Endpoint)
+	if webSocketReq.Endpoint == "" && !webSocketReq.HasMore {
+		// Empty request with has_more = false means "stop streaming"
+		if err := conn.stopStream(webSocketReq.RequestId); err != nil {
+			resp.Error = wsError(err)
+		} else {
+			// The stream will send the final has_more = false frame
+			return
+		}
+	} else if routeErr != nil {
+		resp.Error = wsError(routeErr)
+	} else if endpt == nil {
 		resp.Error = wsError(er.Errorf("No such endpoint: [%s]", webSocketReq.Endpoint))
+	} else if err := authorize(conn.tok, endpt); err != nil {
+		resp.Error = wsError(err)
+	} else if endpt.stream != nil && (endpt.f == nil || webSocketReq.HasMore) {
+		req := endpt.stream.mkReq()
+		if err := er.E(jsonpb.Unmarshal(webSocketReq.Payload, req)); err != nil {
+			resp.Error = wsError(err)
+		} else if err := conn.startStream(endpt, webSocketReq.RequestId, req,
+			func(m proto.Message) er.R {
+				if resBytes, err := er.E1(jsoniter.Marshal(m)); err != nil {
+					return err
+				} else {
+					return send(&WebSocketJSonResponse{
+						RequestId: webSocketReq.RequestId,
+						HasMore:   true,
+						Payload:   resBytes,
+					})
+				}
+			},
+			func(err er.R) {
+				end := WebSocketJSonResponse{RequestId: webSocketReq.RequestId}
+				if err != nil {
+					end.Error = wsError(err)
+				}
+				send(&end)
+			},
+		); err != nil {
+			resp.Error = wsError(err)
+		} else {
+			// The stream will send the final has_more = false frame
+			return
+		}
 	} else {
 		req := endpt.mkReq()
 		if err := er.E(jsonpb.Unmarshal(webSocketReq.Payload, req)); err != nil {
@@ -141,17 +215,7 @@ func (conn *websocketConn) handleJsonMessage(ctx *Apiv1, req []byte) {
 			resp.Payload = resBytes
 		}
 	}
-
-	respPayload, err := jsoniter.Marshal(&resp)
-	if err != nil {
-		log.Errorf("Unable to marshal response to req: [%s]: [%s]", webSocketReq.RequestId, err)
-		return
-	}
-	//	write the result message to the webSocket client
-	err = (*websocket.Conn)(conn).WriteMessage(websocket.TextMessage, respPayload)
-	if err != nil {
-		log.Errorf("Cannot write error message to webSocket client: [%s]", err)
-	}
+	send(&resp)
 }
 
 func (conn *websocketConn) handleProtobufMessage(ctx *Apiv1, req []byte) {
@@ -168,36 +232,152 @@ func (conn *websocketConn) handleProtobufMessage(ctx *Apiv1, req []byte) {
 		HasMore:   false,
 		Payload:   nil,
 	}
-	var endpt *endpoint
-	ctx.internal.funcs.R().In(func(funcs *map[string]*endpoint) er.R {
-		if ep, ok := (*funcs)[webSocketReq.Endpoint]; ok {
-			endpt = ep
+	send := func(resp *rest_pb.WebSocketProtobufResponse) er.R {
+		if respPayload, err := proto.Marshal(resp); err != nil {
+			log.Errorf("Unable to marshal response to req: [%s]: [%s]", webSocketReq.RequestId, err)
+			return er.E(err)
+		} else if err := conn.write(websocket.TextMessage, respPayload); err != nil {
+			log.Errorf("Cannot write error message to webSocket client: [%s]", err)
+			return err
 		}
 		return nil
-	})
-	if endpt == nil {
+	}
+	endpt, routeErr := ctx.wsEndpoint(conn.req, webSocketReq.Endpoint)
+	if webSocketReq.Endpoint == "" && !webSocketReq.HasMore {
+		// Empty request with has_more = false means "stop streaming"
+		if err := conn.stopStream(webSocketReq.RequestId); err != nil {
+			resp.Payload = pWsError(err)
+		} else {
+			// The stream will send the final has_more = false frame
+			return
+		}
+	} else if routeErr != nil {
+		resp.Payload = pWsError(routeErr)
+	} else if endpt == nil {
 		resp.Payload = pWsError(er.Errorf("No such endpoint: [%s]", webSocketReq.Endpoint))
+	} else if err := authorize(conn.tok, endpt); err != nil {
+		resp.Payload = pWsError(err)
+	} else if endpt.stream != nil && (endpt.f == nil || webSocketReq.HasMore) {
+		req := endpt.stream.mkReq()
+		if err := er.E(webSocketReq.Payload.UnmarshalTo(req)); err != nil {
+			resp.Payload = pWsError(err)
+		} else if err := conn.startStream(endpt, webSocketReq.RequestId, req,
+			func(m proto.Message) er.R {
+				if ok, err := pWsOk(m); err != nil {
+					return err
+				} else {
+					return send(&rest_pb.WebSocketProtobufResponse{
+						RequestId: webSocketReq.RequestId,
+						HasMore:   true,
+						Payload:   ok,
+					})
+				}
+			},
+			func(err er.R) {
+				end := rest_pb.WebSocketProtobufResponse{RequestId: webSocketReq.RequestId}
+				if err != nil {
+					end.Payload = pWsError(err)
+				}
+				send(&end)
+			},
+		); err != nil {
+			resp.Payload = pWsError(err)
+		} else {
+			// The stream will send the final has_more = false frame
+			return
+		}
 	} else {
 		req := endpt.mkReq()
 		if err := er.E(webSocketReq.Payload.UnmarshalTo(req)); err != nil {
 			resp.Payload = pWsError(err)
 		} else if res, err := endpt.f(req); err != nil {
 			resp.Payload = pWsError(err)
-		} else if resBytes, err := er.E1(jsoniter.Marshal(res)); err != nil {
+		} else if ok, err := pWsOk(res); err != nil {
 			resp.Payload = pWsError(err)
 		} else {
-			resp.Payload = &rest_pb.WebSocketProtobufResponse_Ok{
-				Ok: &anypb.Any{
-					TypeUrl: "github.com/pkt-cash/pktd/lnd/" + reflect.TypeOf(res).String()[1:],
-					Value:   resBytes,
-				},
-			}
+			resp.Payload = ok
+		}
+	}
+	send(&resp)
+}
+
+func pWsOk(res proto.Message) (*rest_pb.WebSocketProtobufResponse_Ok, er.R) {
+	if resBytes, err := er.E1(jsoniter.Marshal(res)); err != nil {
+		return nil, err
+	} else {
+		return &rest_pb.WebSocketProtobufResponse_Ok{
+			Ok: &anypb.Any{
+				TypeUrl: "github.com/pkt-cash/pktd/lnd/" + reflect.TypeOf(res).String()[1:],
+				Value:   resBytes,
+			},
+		}, nil
+	}
+}
+
+func (ctx *Apiv1) wsEndpoint(r *http.Request, path string) (*endpoint, er.R) {
+	var endpt *endpoint
+	path, err := ctx.route(r, path)
+	if err != nil {
+		return nil, err
+	}
+	ctx.internal.funcs.R().In(func(funcs *map[string]*endpoint) er.R {
+		if ep, ok := (*funcs)[path]; ok {
+			endpt = ep
 		}
+		return nil
+	})
+	return endpt, nil
+}
+
+// startStream subscribes to the stream of an endpoint and sends each event
+// until the stream ends, the client asks for it to be stopped, or the socket
+// is closed. When the stream ends, end is called with the error, if any.
+func (conn *websocketConn) startStream(
+	endpt *endpoint,
+	requestId string,
+	req proto.Message,
+	send func(m proto.Message) er.R,
+	end func(err er.R),
+) er.R {
+	sub, err := endpt.stream.subscribe(req)
+	if err != nil {
+		return err
 	}
+	if err := conn.subs.In(func(subs *map[string]*subscription) er.R {
+		if _, ok := (*subs)[requestId]; ok {
+			return er.Errorf("There is already a stream with request_id [%s]", requestId)
+		}
+		(*subs)[requestId] = sub
+		return nil
+	}); err != nil {
+		sub.cancel()
+		return err
+	}
+	go func() {
+		err := sub.run(conn.done, send, nil)
+		conn.subs.In(func(subs *map[string]*subscription) er.R {
+			delete(*subs, requestId)
+			return nil
+		})
+		select {
+		case <-conn.done:
+			// Nobody to tell
+		default:
+			end(err)
+		}
+	}()
+	return nil
+}
 
-	if respPayload, err := proto.Marshal(&resp); err != nil {
-		log.Errorf("Unable to marshal response to req: [%s]: [%s]", webSocketReq.RequestId, err)
-	} else if err := (*websocket.Conn)(conn).WriteMessage(websocket.TextMessage, respPayload); err != nil {
-		log.Errorf("Cannot write error message to webSocket client: [%s]", err)
+func (conn *websocketConn) stopStream(requestId string) er.R {
+	var sub *subscription
+	conn.subs.In(func(subs *map[string]*subscription) er.R {
+		sub = (*subs)[requestId]
+		return nil
+	})
+	if sub == nil {
+		return er.Errorf("No stream with request_id [%s]", requestId)
 	}
+	sub.cancel()
+	return nil
 }
@@ -0,0 +1,114 @@
+package apiv1
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func dialWs(t *testing.T, srv *httptest.Server) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/websocket"
+	conn, _, errr := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, errr)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func wsSend(t *testing.T, conn *websocket.Conn, req WebSocketJSonRequest) {
+	b, errr := json.Marshal(&req)
+	require.NoError(t, errr)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, b))
+}
+
+func wsRecv(t *testing.T, conn *websocket.Conn) *WebSocketJSonResponse {
+	_, b, errr := conn.ReadMessage()
+	require.NoError(t, errr)
+	var resp WebSocketJSonResponse
+	require.NoError(t, json.Unmarshal(b, &resp))
+	return &resp
+}
+
+func TestWebsocketSubscribe(t *testing.T) {
+	ts := newTestStream(t)
+	conn := dialWs(t, ts.srv)
+
+	wsSend(t, conn, WebSocketJSonRequest{
+		Endpoint:  "test/events",
+		RequestId: "a",
+		HasMore:   true,
+		Payload:   json.RawMessage(`{"legacy":true}`),
+	})
+	ts.waitListeners(t, 1)
+
+	// A second stream with the same request_id is refused
+	wsSend(t, conn, WebSocketJSonRequest{
+		Endpoint:  "test/events",
+		RequestId: "a",
+		HasMore:   true,
+		Payload:   json.RawMessage("{}"),
+	})
+	resp := wsRecv(t, conn)
+	require.Equal(t, "a", resp.RequestId)
+	require.False(t, resp.HasMore)
+	require.Contains(t, resp.Error.Message, "already a stream with request_id [a]")
+	require.Equal(t, 1, ts.ev.Listeners())
+
+	ts.emit(t, "pkt1qa", "1b")
+	resp = wsRecv(t, conn)
+	require.Equal(t, "a", resp.RequestId)
+	require.True(t, resp.HasMore)
+	require.Empty(t, resp.Error.Message)
+	var ev struct{ Address string }
+	require.NoError(t, json.Unmarshal(resp.Payload, &ev))
+	require.Equal(t, "1b", ev.Address)
+
+	// Unsubscribing ends the stream with a final has_more = false frame
+	wsSend(t, conn, WebSocketJSonRequest{RequestId: "a"})
+	resp = wsRecv(t, conn)
+	require.Equal(t, "a", resp.RequestId)
+	require.False(t, resp.HasMore)
+	require.Empty(t, resp.Error.Message)
+	ts.waitListeners(t, 0)
+
+	wsSend(t, conn, WebSocketJSonRequest{RequestId: "a"})
+	resp = wsRecv(t, conn)
+	require.Contains(t, resp.Error.Message, "No stream with request_id [a]")
+}
+
+func TestWebsocketClose(t *testing.T) {
+	ts := newTestStream(t)
+	conn := dialWs(t, ts.srv)
+	for _, id := range []string{"a", "b"} {
+		wsSend(t, conn, WebSocketJSonRequest{
+			Endpoint:  "test/events",
+			RequestId: id,
+			HasMore:   true,
+			Payload:   json.RawMessage("{}"),
+		})
+	}
+	ts.waitListeners(t, 2)
+
+	// Every stream ends when the socket is closed
+	require.NoError(t, conn.Close())
+	ts.waitListeners(t, 0)
+}
+
+func TestWebsocketSourceError(t *testing.T) {
+	conn := dialWs(t, newFailingSource(t, "1a"))
+	wsSend(t, conn, WebSocketJSonRequest{
+		Endpoint:  "test/source",
+		RequestId: "a",
+		HasMore:   true,
+		Payload:   json.RawMessage("{}"),
+	})
+	resp := wsRecv(t, conn)
+	require.True(t, resp.HasMore)
+	require.Empty(t, resp.Error.GetMessage())
+	resp = wsRecv(t, conn)
+	require.False(t, resp.HasMore)
+	require.Contains(t, resp.Error.Message, "database is closed")
+}
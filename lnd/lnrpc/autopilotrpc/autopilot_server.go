@@ -7,6 +7,7 @@ import (
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/util"
 	"github.com/pkt-cash/pktd/generated/proto/autopilotrpc_pb"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/autopilot"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
@@ -121,6 +122,7 @@ func Register(mgr *autopilot.Manager, lightning *apiv1.Apiv1) er.R {
 				Active: s.manager.IsActive(),
 			}, nil
 		},
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		a,
@@ -131,6 +133,7 @@ func Register(mgr *autopilot.Manager, lightning *apiv1.Apiv1) er.R {
 		func(*rpc_pb.Null) (*rpc_pb.Null, er.R) {
 			return nil, s.manager.StartAgent()
 		},
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		a,
@@ -141,6 +144,7 @@ func Register(mgr *autopilot.Manager, lightning *apiv1.Apiv1) er.R {
 		func(*rpc_pb.Null) (*rpc_pb.Null, er.R) {
 			return nil, s.manager.StopAgent()
 		},
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		a,
@@ -152,6 +156,7 @@ func Register(mgr *autopilot.Manager, lightning *apiv1.Apiv1) er.R {
 		for the scores they would give to the given nodes.
 		`,
 		s.queryScores,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		a,
@@ -162,6 +167,7 @@ func Register(mgr *autopilot.Manager, lightning *apiv1.Apiv1) er.R {
     	Only works if the external scoring heuristic is enabled.
 		`,
 		s.setScores,
+		help_pb.F_PERM_WRITE,
 	)
 
 	return nil
@@ -8,8 +8,11 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/generated/proto/invoicesrpc_pb"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/channeldb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/lnd/lntypes"
 	"github.com/pkt-cash/pktd/pktlog/log"
 )
@@ -275,3 +278,118 @@ func (s *Server) AddHoldInvoice0(
 		PaymentRequest: string(dbInvoice.PaymentRequest),
 	}, nil
 }
+
+// subscribeSingleInvoice sends the updates for one invoice to a REST stream
+// until stop is closed.
+func (s *Server) subscribeSingleInvoice(
+	req *invoicesrpc_pb.SubscribeSingleInvoiceRequest,
+	stop <-chan struct{},
+) (<-chan *rpc_pb.Invoice, er.R) {
+	hash, err := lntypes.MakeHash(req.RHash)
+	if err != nil {
+		return nil, err
+	}
+	invoiceClient, err := s.cfg.InvoiceRegistry.SubscribeSingleInvoice(hash)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *rpc_pb.Invoice)
+	go func() {
+		defer close(out)
+		defer invoiceClient.Cancel()
+		for {
+			select {
+			case newInvoice := <-invoiceClient.Updates:
+				rpcInvoice, err := CreateRPCInvoice(
+					newInvoice, s.cfg.ChainParams,
+				)
+				if err != nil {
+					log.Warnf("Unable to convert invoice [%s]: [%s]", hash, err)
+					return
+				}
+				select {
+				case out <- rpcInvoice:
+				case <-stop:
+					return
+				case <-s.quit:
+					return
+				}
+			case <-stop:
+				return
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Register adds the hold invoice endpoints to the invoice category of the REST API
+func Register(s *Server, invoice *apiv1.Apiv1) {
+	a := apiv1.DefineCategory(invoice, "hold",
+		`
+		Hold invoices, which are not settled until the preimage is given
+
+		A hold invoice is created with only the hash of the preimage, when it is
+		paid the HTLCs are accepted but not settled. The payment can then either
+		be settled by providing the preimage or canceled, which returns the funds
+		to the payer. This is useful for escrow, where the payee should only be
+		paid once some condition has been met.
+		`,
+	)
+	apiv1.Endpoint(
+		a,
+		"create",
+		`
+		Create a hold invoice using the hash of a preimage
+
+		The preimage is not needed until the invoice is settled, watch the invoice
+		using hold/subscribe to see when it has been paid and can be settled.
+		`,
+		func(req *invoicesrpc_pb.AddHoldInvoiceRequest) (*invoicesrpc_pb.AddHoldInvoiceResp, er.R) {
+			return s.AddHoldInvoice0(context.TODO(), req)
+		},
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"settle",
+		`
+		Settle an accepted hold invoice by giving the preimage
+
+		If the invoice is already settled, this call will succeed.
+		`,
+		func(req *invoicesrpc_pb.SettleInvoiceMsg) (*invoicesrpc_pb.SettleInvoiceResp, er.R) {
+			return s.SettleInvoice0(context.TODO(), req)
+		},
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		a,
+		"cancel",
+		`
+		Cancel an open or accepted hold invoice
+
+		Any HTLCs which have been accepted are failed back to the payer.
+		If the invoice is already canceled, this call will succeed. If the invoice
+		is already settled, it will fail.
+		`,
+		func(req *invoicesrpc_pb.CancelInvoiceMsg) (*invoicesrpc_pb.CancelInvoiceResp, er.R) {
+			return s.CancelInvoice0(context.TODO(), req)
+		},
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.StreamSource(
+		a,
+		"subscribe",
+		`
+		Stream the state changes of a single invoice
+
+		The current state of the invoice is sent first, followed by an update
+		each time it changes, e.g. when a hold invoice is paid it becomes ACCEPTED
+		and can then be settled or canceled.
+		`,
+		s.subscribeSingleInvoice,
+		help_pb.F_PERM_READ,
+	)
+}
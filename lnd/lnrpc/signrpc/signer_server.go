@@ -9,9 +9,11 @@ import (
 	"github.com/pkt-cash/pktd/btcec"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/signrpc_pb"
 	"github.com/pkt-cash/pktd/lnd/input"
 	"github.com/pkt-cash/pktd/lnd/keychain"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/lnd/lnwire"
 	"github.com/pkt-cash/pktd/pktlog/log"
 	"github.com/pkt-cash/pktd/txscript"
@@ -552,3 +554,92 @@ func parseRawKeyBytes(rawKeyBytes []byte) (*btcec.PublicKey, er.R) {
 			"specified")
 	}
 }
+
+// Register adds the signer endpoints to the lightning category of the REST API.
+func Register(s *Server, lightning *apiv1.Apiv1) {
+	a := apiv1.DefineCategory(lightning, "signer",
+		`
+		Sign and derive keys using the keys of the lightning node
+
+		Keys are identified by a key locator, which is a key family and an index.
+		The key is derived from the wallet seed at m/1017'/coin_type'/key_family'/0/key_index
+		so external software can use the node's keys without them ever leaving
+		the node. The families from 0 to 9 are used by lightning itself, e.g. 6 is
+		the node identity key, other families may be used freely.
+		These endpoints are available once lightning has been started.
+		`,
+	)
+	apiv1.Endpoint(
+		a,
+		"outputraw",
+		`
+		Sign the inputs of a transaction which are described by sign descriptors
+
+		Each sign descriptor gives the key locator or raw public key of the key to
+		sign with, and the tweak, witness script, output and sighash type. The
+		signatures are returned without the sighash byte.
+		`,
+		func(req *signrpc_pb.SignReq) (*signrpc_pb.SignResp, er.R) {
+			return s.SignOutputRaw0(context.TODO(), req)
+		},
+		help_pb.F_PERM_SPEND,
+	)
+	apiv1.Endpoint(
+		a,
+		"inputscript",
+		`
+		Make complete input scripts for inputs which pay to the node's keys
+
+		This is only for inputs which pay to a p2wkh or np2wkh output where the
+		key is known to the wallet, unlike outputraw the whole witness and
+		signature script is produced.
+		`,
+		func(req *signrpc_pb.SignReq) (*signrpc_pb.InputScriptResp, er.R) {
+			return s.ComputeInputScript0(context.TODO(), req)
+		},
+		help_pb.F_PERM_SPEND,
+	)
+	apiv1.Endpoint(
+		a,
+		"signmessage",
+		`
+		Sign a message with the key at a key locator
+
+		The signature is over the sha256 hash of the message and is in the
+		fixed-size 64 byte lightning wire format.
+		`,
+		func(req *signrpc_pb.SignMessageReq) (*signrpc_pb.SignMessageResp, er.R) {
+			return s.SignMessage0(context.TODO(), req)
+		},
+		help_pb.F_PERM_SPEND,
+	)
+	apiv1.Endpoint(
+		a,
+		"verifymessage",
+		`
+		Verify a signature made by signer/signmessage
+
+		The signature must be in the fixed-size 64 byte lightning wire format
+		and the public key is a 33 byte compressed key.
+		`,
+		func(req *signrpc_pb.VerifyMessageReq) (*signrpc_pb.VerifyMessageResp, er.R) {
+			return s.VerifyMessage0(context.TODO(), req)
+		},
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		a,
+		"sharedkey",
+		`
+		Derive an ECDH shared key between a public key and one of the node's keys
+
+		The node's key is given in key_desc either by a key locator or by a raw
+		public key within a key family, if it is not given then the node identity
+		key is used. The result is the sha256 of the compressed shared point.
+		`,
+		func(req *signrpc_pb.SharedKeyRequest) (*signrpc_pb.SharedKeyResponse, er.R) {
+			return s.DeriveSharedKey0(context.TODO(), req)
+		},
+		help_pb.F_PERM_SECRET,
+	)
+}
@@ -9,15 +9,20 @@ import (
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/psbt"
+	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/generated/proto/signrpc_pb"
 	"github.com/pkt-cash/pktd/generated/proto/walletrpc_pb"
+	"github.com/pkt-cash/pktd/lnd/describetxn"
 	"github.com/pkt-cash/pktd/lnd/input"
 	"github.com/pkt-cash/pktd/lnd/keychain"
 	"github.com/pkt-cash/pktd/lnd/labels"
 	"github.com/pkt-cash/pktd/lnd/lnrpc"
+	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 	"github.com/pkt-cash/pktd/lnd/lnwallet"
+	"github.com/pkt-cash/pktd/lnd/lnwallet/btcwallet"
 	"github.com/pkt-cash/pktd/lnd/lnwallet/chainfee"
 	"github.com/pkt-cash/pktd/lnd/sweep"
 	"github.com/pkt-cash/pktd/pktlog/log"
@@ -691,7 +696,6 @@ func (w *WalletKit) BumpFee0(ctx context.Context,
 	return &walletrpc_pb.BumpFeeResponse{}, nil
 }
 
-/*
 // ListSweeps returns a list of the sweeps that our node has published.
 func (w *WalletKit) ListSweeps(c context.Context,
 	req *walletrpc_pb.ListSweepsRequest) (*walletrpc_pb.ListSweepsResponse, error) {
@@ -745,9 +749,8 @@ func (w *WalletKit) ListSweeps0(ctx context.Context,
 	if in.Verbose {
 		return &walletrpc_pb.ListSweepsResponse{
 			Sweeps: &walletrpc_pb.ListSweepsResponse_TransactionDetails{
-				TransactionDetails: lnrpc.RPCTransactionDetails(
-					txDetails,
-					false,
+				TransactionDetails: rpcTransactionDetails(
+					txDetails, w.cfg.ChainParams,
 				),
 			},
 		}, nil
@@ -761,7 +764,44 @@ func (w *WalletKit) ListSweeps0(ctx context.Context,
 		},
 	}, nil
 }
-*/
+
+// rpcTransactionDetails converts wallet transactions to the RPC format, the
+// inputs are not looked up so fees may be shown as unknown.
+func rpcTransactionDetails(txs []*lnwallet.TransactionDetail,
+	chainParams *chaincfg.Params) *rpc_pb.TransactionDetails {
+
+	getTxns := func(map[string]*wire.MsgTx) er.R {
+		return nil
+	}
+	out := &rpc_pb.TransactionDetails{
+		Transactions: make([]*rpc_pb.ContextualTransaction, 0, len(txs)),
+	}
+	for _, tx := range txs {
+		var mtx wire.MsgTx
+		if err := mtx.Deserialize(bytes.NewReader(tx.RawTx)); err != nil {
+			log.Warnf("Unable to decode transaction [%s]: [%s]", tx.Hash, err)
+			continue
+		}
+		info, err := describetxn.Describe(getTxns, mtx, chainParams, false)
+		if err != nil {
+			log.Warnf("Unable to describe transaction [%s]: [%s]", tx.Hash, err)
+			continue
+		}
+		blockHash := ""
+		if tx.BlockHash != nil {
+			blockHash = tx.BlockHash.String()
+		}
+		out.Transactions = append(out.Transactions, &rpc_pb.ContextualTransaction{
+			Tx:               info,
+			TxBin:            tx.RawTx,
+			NumConfirmations: tx.NumConfirmations,
+			BlockHash:        blockHash,
+			BlockHeight:      tx.BlockHeight,
+			Time:             tx.Timestamp,
+		})
+	}
+	return out
+}
 
 // LabelTransaction adds a label to a transaction.
 func (w *WalletKit) LabelTransaction(ctx context.Context,
@@ -1061,3 +1101,144 @@ func (w *WalletKit) FinalizePsbt0(_ context.Context,
 		RawFinalTx: finalTxBytes.Bytes(),
 	}, nil
 }
+
+// Register adds the PSBT and sweep endpoints to the wallet category of the
+// REST API.
+func Register(w *WalletKit, wallet *apiv1.Apiv1) {
+	psbtCat := apiv1.DefineCategory(wallet, "psbt",
+		`
+		Partially signed bitcoin transactions (PSBT, BIP-174)
+
+		PSBTs allow a transaction to be built and signed in steps, by more than one
+		party or with keys kept in cold storage. PSBTs are passed as bytes, so when
+		using JSON they are base64 encoded and when using protobuf they are binary.
+		These endpoints are available once lightning has been started.
+		`,
+	)
+	apiv1.Endpoint(
+		psbtCat,
+		"fund",
+		`
+		Fund a PSBT from the wallet
+
+		The template is either a PSBT or a list of outputs with optional inputs.
+		If there are no inputs then coins are selected to pay the outputs and fee,
+		otherwise the inputs must be unspent and unlocked outputs of the wallet.
+		A change output is added if needed. The inputs are leased so that they are
+		not used for anything else, it is up to the caller to either finalize and
+		publish the transaction or release the leases using psbt/release.
+		`,
+		func(req *walletrpc_pb.FundPsbtRequest) (*walletrpc_pb.FundPsbtResponse, er.R) {
+			return w.FundPsbt0(context.TODO(), req)
+		},
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		psbtCat,
+		"finalize",
+		`
+		Sign the wallet's inputs of a PSBT and finalize it
+
+		The wallet must be the last signer, every input which does not belong to
+		the wallet must already be signed. The transaction is not published, use
+		wallet/transaction/publish or neutrino/bcasttransaction to send it.
+		`,
+		func(req *walletrpc_pb.FinalizePsbtRequest) (*walletrpc_pb.FinalizePsbtResponse, er.R) {
+			return w.FinalizePsbt0(context.TODO(), req)
+		},
+		help_pb.F_PERM_SPEND,
+	)
+	apiv1.Endpoint(
+		psbtCat,
+		"lease",
+		`
+		Lease an unspent output so that it is not used by coin selection
+
+		The id is 32 random bytes which is needed to release the lease early.
+		A lease can be extended by leasing the same output again with the same id.
+		`,
+		func(req *walletrpc_pb.LeaseOutputRequest) (*walletrpc_pb.LeaseOutputResponse, er.R) {
+			return w.LeaseOutput0(context.TODO(), req)
+		},
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		psbtCat,
+		"release",
+		`
+		Release a leased output so that it can be used by coin selection
+
+		The id must be the same as the one used to lease the output.
+		`,
+		func(req *walletrpc_pb.ReleaseOutputRequest) (*walletrpc_pb.ReleaseOutputResponse, er.R) {
+			return w.ReleaseOutput0(context.TODO(), req)
+		},
+		help_pb.F_PERM_WRITE,
+	)
+	apiv1.Endpoint(
+		wallet.Category("transaction"),
+		"label",
+		`
+		Add a label to a transaction of the wallet
+
+		Labels are limited to 500 characters, an existing label is only replaced
+		if overwrite is set.
+		`,
+		func(req *walletrpc_pb.LabelTransactionRequest) (*walletrpc_pb.LabelTransactionResponse, er.R) {
+			return w.LabelTransaction0(context.TODO(), req)
+		},
+		help_pb.F_PERM_WRITE,
+	)
+
+	sweepCat := apiv1.DefineCategory(wallet, "sweep",
+		`
+		Outputs which are being swept back into the wallet
+
+		When channels are closed, the funds are swept back into the wallet in
+		batches by the sweeper. These endpoints are available once lightning has
+		been started.
+		`,
+	)
+	apiv1.Endpoint(
+		sweepCat,
+		"",
+		`
+		List the sweep transactions which have been published
+
+		If verbose is set then the full transactions are returned, otherwise
+		only the txids. Sweeps which were replaced by fee are not included.
+		`,
+		func(req *walletrpc_pb.ListSweepsRequest) (*walletrpc_pb.ListSweepsResponse, er.R) {
+			return w.ListSweeps0(context.TODO(), req)
+		},
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		sweepCat,
+		"pending",
+		`
+		List the outputs which the sweeper is trying to sweep
+		`,
+		func(req *walletrpc_pb.PendingSweepsRequest) (*walletrpc_pb.PendingSweepsResponse, er.R) {
+			return w.PendingSweeps0(context.TODO(), req)
+		},
+		help_pb.F_PERM_READ,
+	)
+	apiv1.Endpoint(
+		sweepCat,
+		"bumpfee",
+		`
+		Raise the fee of an output which is being swept
+
+		If the sweeper is already sweeping the output then the sweep is replaced
+		with one which pays a higher fee (RBF), otherwise the output is assumed to
+		be an unconfirmed output of the wallet and is swept with a higher fee so
+		that the child pays for the parent (CPFP). The fee is given as either a
+		confirmation target or sat_per_byte.
+		`,
+		func(req *walletrpc_pb.BumpFeeRequest) (*walletrpc_pb.BumpFeeResponse, er.R) {
+			return w.BumpFee0(context.TODO(), req)
+		},
+		help_pb.F_PERM_SPEND,
+	)
+}
@@ -48,6 +48,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.ListChannelsRequest) (*rpc_pb.ListChannelsResponse, er.R) {
 			return rs.ListChannels(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -67,6 +68,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.OpenChannelRequest) (*rpc_pb.ChannelPoint, er.R) {
 			return rs.OpenChannelSync(context.TODO(), req)
 		}),
+		help_pb.F_PERM_SPEND,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -86,6 +88,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 			// TODO(cjd): streaming
 			return nil, rs.CloseChannel(req, nil)
 		}),
+		help_pb.F_PERM_SPEND,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -103,6 +106,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.AbandonChannelRequest) (*rpc_pb.AbandonChannelResponse, er.R) {
 			return rs.AbandonChannel(req)
 		}),
+		help_pb.F_PERM_SPEND,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -117,6 +121,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.Null) (*rpc_pb.ChannelBalanceResponse, er.R) {
 			return rs.ChannelBalance(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -133,6 +138,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 			return rs.PendingChannels(context.TODO(), req)
 		}),
 		help_pb.F_ALLOW_GET,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -147,6 +153,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 			return rs.ClosedChannels(context.TODO(), req)
 		}),
 		help_pb.F_ALLOW_GET,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -161,6 +168,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 			return rs.GetNetworkInfo(req)
 		}),
 		help_pb.F_ALLOW_GET,
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -174,6 +182,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.Null) (*rpc_pb.FeeReportResponse, er.R) {
 			return rs.FeeReport(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningChannel,
@@ -187,6 +196,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.PolicyUpdateRequest) (*rpc_pb.PolicyUpdateResponse, er.R) {
 			return rs.UpdateChannelPolicy(context.TODO(), req)
 		}),
+		help_pb.F_PERM_WRITE,
 	)
 
 	//	>>> lightning/channel/backup subCategory commands
@@ -213,6 +223,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.ExportChannelBackupRequest) (*rpc_pb.ChannelBackup, er.R) {
 			return rs.ExportChannelBackup(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 
 	apiv1.Endpoint(
@@ -228,6 +239,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.ChanBackupSnapshot) (*rpc_pb.VerifyChanBackupResponse, er.R) {
 			return rs.VerifyChanBackup(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 
 	apiv1.Endpoint(
@@ -244,6 +256,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.RestoreChanBackupRequest) (*rpc_pb.RestoreBackupResponse, er.R) {
 			return rs.RestoreChannelBackups(context.TODO(), req)
 		}),
+		help_pb.F_PERM_ADMIN,
 	)
 
 	//	>>> lightning/graph subCategory commands
@@ -266,6 +279,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.ChannelGraphRequest) (*rpc_pb.ChannelGraph, er.R) {
 			return rs.DescribeGraph(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningGraph,
@@ -279,6 +293,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.NodeMetricsRequest) (*rpc_pb.NodeMetricsResponse, er.R) {
 			return rs.GetNodeMetrics(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningGraph,
@@ -294,6 +309,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.ChanInfoRequest) (*rpc_pb.ChannelEdge, er.R) {
 			return rs.GetChanInfo(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningGraph,
@@ -307,6 +323,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.NodeInfoRequest) (*rpc_pb.NodeInfo, er.R) {
 			return rs.GetNodeInfo(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 
 	//	>>> lightning/invoice subCategory commands
@@ -325,6 +342,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.Invoice) (*rpc_pb.AddInvoiceResponse, er.R) {
 			return rs.AddInvoice(context.TODO(), req)
 		}),
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		lightningInvoice,
@@ -339,6 +357,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.PaymentHash) (*rpc_pb.Invoice, er.R) {
 			return rs.LookupInvoice(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningInvoice,
@@ -357,6 +376,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(cc *LightningRPCServer, req *rpc_pb.ListInvoiceRequest) (*rpc_pb.ListInvoiceResponse, er.R) {
 			return cc.ListInvoices(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningInvoice,
@@ -371,6 +391,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(cc *LightningRPCServer, req *rpc_pb.PayReqString) (*rpc_pb.PayReq, er.R) {
 			return cc.DecodePayReq(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 
 	//	>>> lightning/payment subCategory command
@@ -385,6 +406,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(cc *LightningRPCServer, req *rpc_pb.SendRequest) (*rpc_pb.SendResponse, er.R) {
 			return cc.SendPaymentSync(context.TODO(), req)
 		}),
+		help_pb.F_PERM_SPEND,
 	)
 	// TODO(cjd): Streaming
 	// apiv1.Register(
@@ -416,6 +438,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRouter(c, func(rs *routerrpc.Server, req *routerrpc_pb.SendToRouteRequest) (*rpc_pb.HTLCAttempt, er.R) {
 			return rs.SendToRouteV2(context.TODO(), req)
 		}),
+		help_pb.F_PERM_SPEND,
 	)
 	apiv1.Endpoint(
 		lightningPayment,
@@ -428,6 +451,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(cc *LightningRPCServer, req *rpc_pb.ListPaymentsRequest) (*rpc_pb.ListPaymentsResponse, er.R) {
 			return cc.ListPayments(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	// TODO(cjd): Streaming only
 	// apiv1.Register(
@@ -459,6 +483,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(cc *LightningRPCServer, req *rpc_pb.QueryRoutesRequest) (*rpc_pb.QueryRoutesResponse, er.R) {
 			return cc.QueryRoutes(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningPayment,
@@ -478,6 +503,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(cc *LightningRPCServer, req *rpc_pb.ForwardingHistoryRequest) (*rpc_pb.ForwardingHistoryResponse, er.R) {
 			return cc.ForwardingHistory(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningPayment,
@@ -491,6 +517,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRouter(c, func(rs *routerrpc.Server, req *rpc_pb.Null) (*routerrpc_pb.QueryMissionControlResponse, er.R) {
 			return rs.QueryMissionControl(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningPayment,
@@ -504,6 +531,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRouter(c, func(rs *routerrpc.Server, req *routerrpc_pb.QueryProbabilityRequest) (*routerrpc_pb.QueryProbabilityResponse, er.R) {
 			return rs.QueryProbability(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningPayment,
@@ -516,6 +544,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRouter(c, func(rs *routerrpc.Server, req *rpc_pb.Null) (*rpc_pb.Null, er.R) {
 			return rs.ResetMissionControl(context.TODO(), req)
 		}),
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		lightningPayment,
@@ -530,6 +559,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRouter(c, func(rs *routerrpc.Server, req *routerrpc_pb.BuildRouteRequest) (*routerrpc_pb.BuildRouteResponse, er.R) {
 			return rs.BuildRoute(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 
 	//	>>> lightning/peer subCategory command
@@ -548,6 +578,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.ConnectPeerRequest) (*rpc_pb.Null, er.R) {
 			return rs.ConnectPeer(context.TODO(), req)
 		}),
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		lightningPeer,
@@ -562,6 +593,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.DisconnectPeerRequest) (*rpc_pb.Null, er.R) {
 			return rs.DisconnectPeer(context.TODO(), req)
 		}),
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		lightningPeer,
@@ -574,6 +606,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withRpc(c, func(rs *LightningRPCServer, req *rpc_pb.ListPeersRequest) (*rpc_pb.ListPeersResponse, er.R) {
 			return rs.ListPeers(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 
 	lightningWatchtower := apiv1.DefineCategory(lightning, "watchtower",
@@ -589,6 +622,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withWtclient(c, func(rs *wtclientrpc.WatchtowerClient, req *wtclientrpc_pb.ListTowersRequest) (*wtclientrpc_pb.ListTowersResponse, er.R) {
 			return rs.ListTowers(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningWatchtower,
@@ -601,6 +635,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withWtclient(c, func(rs *wtclientrpc.WatchtowerClient, req *rpc_pb.Null) (*wtclientrpc_pb.StatsResponse, er.R) {
 			return rs.Stats(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningWatchtower,
@@ -616,6 +651,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withWtclient(c, func(rs *wtclientrpc.WatchtowerClient, req *wtclientrpc_pb.AddTowerRequest) (*rpc_pb.Null, er.R) {
 			return rs.AddTower(context.TODO(), req)
 		}),
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		lightningWatchtower,
@@ -631,6 +667,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withWtclient(c, func(rs *wtclientrpc.WatchtowerClient, req *wtclientrpc_pb.RemoveTowerRequest) (*rpc_pb.Null, er.R) {
 			return rs.RemoveTower(context.TODO(), req)
 		}),
+		help_pb.F_PERM_WRITE,
 	)
 	apiv1.Endpoint(
 		lightningWatchtower,
@@ -641,6 +678,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withWtclient(c, func(rs *wtclientrpc.WatchtowerClient, req *wtclientrpc_pb.GetTowerInfoRequest) (*wtclientrpc_pb.Tower, er.R) {
 			return rs.GetTowerInfo(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Endpoint(
 		lightningWatchtower,
@@ -651,6 +689,7 @@ func (c *RpcContext) RegisterFunctions(a *apiv1.Apiv1) {
 		withWtclient(c, func(rs *wtclientrpc.WatchtowerClient, req *wtclientrpc_pb.PolicyRequest) (*wtclientrpc_pb.PolicyResponse, er.R) {
 			return rs.Policy(context.TODO(), req)
 		}),
+		help_pb.F_PERM_READ,
 	)
 
 	// We're not doing estimatefee because it is unreliable and a bad API
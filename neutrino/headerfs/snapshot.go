@@ -0,0 +1,265 @@
+package headerfs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"io"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+)
+
+/**
+ * Snapshot layout:
+ *
+ *    magic "pkthdrs1" (8 bytes)
+ *    genesis block hash (32 bytes)
+ *    height of the last header, big endian (4 bytes)
+ *    chunks until every header from genesis to the last one is given:
+ *        number of headers in the chunk, 1 to snapshotChunkSize (4 bytes)
+ *        block header and filter header of each height (112 bytes each)
+ *        sha256 of the previous checksum, the number and the headers (32 bytes)
+ *
+ * The checksum before the first chunk is the sha256 of the first 44 bytes, so
+ * each checksum covers everything in the file before it.
+ */
+var snapshotMagic = []byte("pkthdrs1")
+
+const snapshotHeadLen = 8 + 32 + 4
+const snapshotChunkSize = 10000
+
+func chunkSum(prev *[32]byte, chunk []byte) [32]byte {
+	h := sha256.New()
+	h.Write(prev[:])
+	h.Write(chunk)
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// ExportSnapshot writes the block and filter headers from genesis up to and
+// including height to w, so that another node can import them with
+// ImportSnapshot rather than syncing them from peers. If height is 0 then
+// the filter header tip is used. It returns the height of the last header.
+func (h *NeutrinoDBStore) ExportSnapshot(w io.Writer, height uint32) (uint32, er.R) {
+	err := walletdb.View(h.Db, func(tx walletdb.ReadTx) er.R {
+		_, filterTip, err := h.FilterChainTip1(tx)
+		if err != nil {
+			return err
+		}
+		if height == 0 {
+			height = filterTip
+		} else if height > filterTip {
+			return er.Errorf("Height [%d] is beyond the filter header tip [%d]",
+				height, filterTip)
+		}
+		gen, err := h.readHeader(tx, 0)
+		if err != nil {
+			return err
+		}
+		genHash := gen.Header.blockHeader.BlockHash()
+
+		head := make([]byte, 0, snapshotHeadLen)
+		head = append(head, snapshotMagic...)
+		head = append(head, genHash[:]...)
+		head = append(head, heightBin(height)...)
+		if _, err := w.Write(head); err != nil {
+			return er.E(err)
+		}
+		sum := sha256.Sum256(head)
+
+		for start := uint32(0); start <= height; start += snapshotChunkSize {
+			end := start + snapshotChunkSize - 1
+			if end > height {
+				end = height
+			}
+			chunk := bytes.NewBuffer(make([]byte, 0, 4+(end-start+1)*TotalSize+32))
+			chunk.Write(heightBin(end - start + 1))
+			for ht := start; ht <= end; ht++ {
+				he, err := h.readHeader(tx, ht)
+				if err != nil {
+					return err
+				} else if he.Header.filterHeader == nil {
+					return er.Errorf("No filter header at height [%d]", ht)
+				}
+				chunk.Write(he.Header.Bytes())
+			}
+			sum = chunkSum(&sum, chunk.Bytes())
+			chunk.Write(sum[:])
+			if _, err := w.Write(chunk.Bytes()); err != nil {
+				return er.E(err)
+			}
+		}
+		return nil
+	})
+	return height, err
+}
+
+// ImportSnapshot adds the headers from a snapshot which was made by
+// ExportSnapshot. The checksums in the file only detect damage, so before
+// anything is read the sha256 of the whole file must be sum, which the user
+// got from whoever they trust to have made the snapshot. Every header must
+// then connect to the one before it and match the checkpoints of netParams,
+// and there must be a filter header assertion at or below the end of the
+// snapshot which the filter headers match, because nothing else checks them.
+// Headers which are already in the store must be the same as those in the
+// snapshot. Each chunk of the snapshot is checked before it is written, so a
+// damaged file leaves the store with the valid part of it. If the filter
+// headers are already synced to the end of the snapshot then nothing is done.
+// It returns the height of the last header in the snapshot.
+func (h *NeutrinoDBStore) ImportSnapshot(
+	rs io.ReadSeeker,
+	netParams *chaincfg.Params,
+	assert *FilterHeader,
+	sum [32]byte,
+) (uint32, er.R) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, rs); err != nil {
+		return 0, er.E(err)
+	}
+	if fileSum := hash.Sum(nil); !bytes.Equal(fileSum, sum[:]) {
+		return 0, er.Errorf("The sha256 of the snapshot is [%x] but [%x] is expected",
+			fileSum, sum[:])
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return 0, er.E(err)
+	}
+	r := bufio.NewReader(rs)
+
+	head := make([]byte, snapshotHeadLen)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return 0, er.E(err)
+	}
+	if !bytes.Equal(head[:8], snapshotMagic) {
+		return 0, er.New("Not a header snapshot file")
+	} else if !bytes.Equal(head[8:40], netParams.GenesisHash[:]) {
+		return 0, er.Errorf("The snapshot is not of the [%s] chain", netParams.Name)
+	}
+	tip := binHeight(head[40:])
+	if assert == nil || assert.Height > tip {
+		return 0, er.Errorf("A filter header assertion at or below the end of "+
+			"the snapshot [%d] is needed to import it", tip)
+	}
+	if _, filterTip, err := h.FilterChainTip(); err != nil {
+		return 0, err
+	} else if filterTip >= tip {
+		log.Infof("Headers are already synced to [%d], beyond the snapshot at [%d]",
+			filterTip, tip)
+		return tip, nil
+	}
+
+	checkpoints := make(map[uint32]*chainhash.Hash, len(netParams.Checkpoints))
+	for _, cp := range netParams.Checkpoints {
+		checkpoints[uint32(cp.Height)] = cp.Hash
+	}
+	chainSum := sha256.Sum256(head)
+	var prev chainhash.Hash
+	for height := uint32(0); height <= tip; {
+		var countBin [4]byte
+		if _, err := io.ReadFull(r, countBin[:]); err != nil {
+			return 0, er.E(err)
+		}
+		count := binHeight(countBin[:])
+		if count == 0 || count > snapshotChunkSize || count-1 > tip-height {
+			return 0, er.Errorf("Invalid snapshot: chunk at height [%d] has [%d] headers",
+				height, count)
+		}
+		chunk := make([]byte, 4+count*TotalSize+32)
+		copy(chunk, countBin[:])
+		if _, err := io.ReadFull(r, chunk[4:]); err != nil {
+			return 0, er.E(err)
+		}
+		chainSum = chunkSum(&chainSum, chunk[:len(chunk)-32])
+		if !bytes.Equal(chainSum[:], chunk[len(chunk)-32:]) {
+			return 0, er.Errorf("Invalid snapshot: checksum mismatch in chunk at height [%d]",
+				height)
+		}
+
+		entries := make([]headerEntryWithHeight, 0, count)
+		for i := uint32(0); i < count; i++ {
+			ht := height + i
+			he, err := decodeHeaderEntry(chunk[4+i*TotalSize : 4+(i+1)*TotalSize])
+			if err != nil {
+				return 0, err
+			}
+			hash := he.blockHeader.BlockHash()
+			if ht == 0 {
+				if !hash.IsEqual(netParams.GenesisHash) {
+					return 0, er.New("Invalid snapshot: wrong genesis block")
+				}
+			} else if !he.blockHeader.PrevBlock.IsEqual(&prev) {
+				return 0, er.Errorf("Invalid snapshot: header at height [%d] does not connect", ht)
+			}
+			if cp, ok := checkpoints[ht]; ok && !hash.IsEqual(cp) {
+				return 0, er.Errorf("Invalid snapshot: header at height [%d] is [%s] "+
+					"but the checkpoint is [%s]", ht, hash, cp)
+			}
+			if assert.Height == ht && assert.FilterHash != *he.filterHeader {
+				return 0, er.Errorf("Invalid snapshot: filter header at height [%d] is [%s] "+
+					"but [%s] is asserted", ht, he.filterHeader, assert.FilterHash)
+			}
+			prev = hash
+			entries = append(entries, headerEntryWithHeight{Header: he, Height: ht})
+		}
+		if err := walletdb.Update(h.Db, func(tx walletdb.ReadWriteTx) er.R {
+			return h.importEntries(tx, entries)
+		}); err != nil {
+			return 0, err
+		}
+		height += count
+	}
+	return tip, nil
+}
+
+// importEntries writes the headers from a snapshot which are beyond the tips
+// of the store and checks that the others match the ones which are stored.
+func (h *NeutrinoDBStore) importEntries(tx walletdb.ReadWriteTx, entries []headerEntryWithHeight) er.R {
+	_, blockTip, err := h.BlockChainTip1(tx)
+	if err != nil {
+		return err
+	}
+	_, filterTip, err := h.FilterChainTip1(tx)
+	if err != nil {
+		return err
+	}
+	var blocks headerWithHeightBatch
+	var filters filterHeaderBatch
+	for _, e := range entries {
+		hash := e.Header.blockHeader.BlockHash()
+		if e.Height <= blockTip {
+			stored, err := h.readHeader(tx, e.Height)
+			if err != nil {
+				return err
+			}
+			if storedHash := stored.Header.blockHeader.BlockHash(); !storedHash.IsEqual(&hash) {
+				return er.Errorf("The snapshot has block [%s] at height [%d] but [%s] is synced",
+					hash, e.Height, storedHash)
+			}
+			if e.Height <= filterTip && (stored.Header.filterHeader == nil ||
+				*stored.Header.filterHeader != *e.Header.filterHeader) {
+				return er.Errorf("The snapshot has a different filter header at height [%d]",
+					e.Height)
+			}
+		} else {
+			blocks = append(blocks, headerEntryWithHeight{
+				Header: &headerEntry{blockHeader: e.Header.blockHeader},
+				Height: e.Height,
+			})
+		}
+		if e.Height > filterTip {
+			filters = append(filters, FilterHeader{
+				HeaderHash: hash,
+				FilterHash: *e.Header.filterHeader,
+				Height:     e.Height,
+			})
+		}
+	}
+	if err := h.addBlockHeaders(tx, blocks, false); err != nil {
+		return err
+	}
+	return h.addFilterHeaders(tx, filters, false)
+}
@@ -0,0 +1,184 @@
+package headerfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+)
+
+// createTestSnapshot makes a store with numHeaders block and filter headers
+// and returns a snapshot of it along with the filter headers which were
+// written.
+func createTestSnapshot(t *testing.T, numHeaders uint32) ([]byte, []FilterHeader) {
+	cleanUp, _, _, hs, err := createTestBlockHeaderStore()
+	if cleanUp != nil {
+		defer cleanUp()
+	}
+	if err != nil {
+		t.Fatalf("unable to create new block header store: %v", err)
+	}
+
+	blockHeaders := createTestBlockHeaderChain(numHeaders)
+	filterHeaders := make([]FilterHeader, numHeaders)
+	for i, bh := range blockHeaders {
+		filterHeaders[i] = FilterHeader{
+			HeaderHash: bh.BlockHash(),
+			FilterHash: sha256.Sum256(heightBin(bh.Height)),
+			Height:     bh.Height,
+		}
+	}
+	if err := walletdb.Update(hs.Db, func(tx walletdb.ReadWriteTx) er.R {
+		if err := hs.WriteBlockHeaders(tx, blockHeaders...); err != nil {
+			return err
+		}
+		return hs.WriteFilterHeaders(tx, filterHeaders...)
+	}); err != nil {
+		t.Fatalf("unable to write headers: %v", err)
+	}
+
+	var buf bytes.Buffer
+	height, err := hs.ExportSnapshot(&buf, 0)
+	if err != nil {
+		t.Fatalf("unable to export snapshot: %v", err)
+	}
+	if height != numHeaders {
+		t.Fatalf("expected snapshot to height %d, got %d", numHeaders, height)
+	}
+	return buf.Bytes(), filterHeaders
+}
+
+func importTestSnapshot(t *testing.T, snapshot []byte, params *chaincfg.Params,
+	assert *FilterHeader) (*NeutrinoDBStore, uint32, func(), er.R) {
+
+	cleanUp, _, _, hs, err := createTestBlockHeaderStore()
+	if err != nil {
+		if cleanUp != nil {
+			cleanUp()
+		}
+		t.Fatalf("unable to create new block header store: %v", err)
+	}
+	height, err := hs.ImportSnapshot(bytes.NewReader(snapshot), params, assert,
+		sha256.Sum256(snapshot))
+	return hs, height, cleanUp, err
+}
+
+func TestSnapshotExportImport(t *testing.T) {
+	// More than one chunk, so the chaining of checksums is tested.
+	const numHeaders = snapshotChunkSize + 100
+	snapshot, filterHeaders := createTestSnapshot(t, numHeaders)
+	last := filterHeaders[len(filterHeaders)-1]
+
+	hs, height, cleanUp, err := importTestSnapshot(t, snapshot, &chaincfg.SimNetParams, &last)
+	defer cleanUp()
+	if err != nil {
+		t.Fatalf("unable to import snapshot: %v", err)
+	}
+	if height != numHeaders {
+		t.Fatalf("expected import to height %d, got %d", numHeaders, height)
+	}
+	blockTip, blockHeight, err := hs.BlockChainTip()
+	if err != nil {
+		t.Fatalf("unable to fetch block tip: %v", err)
+	}
+	if blockHeight != numHeaders || blockTip.BlockHash() != last.HeaderHash {
+		t.Fatalf("block tip is %v at %d, expected %v at %d",
+			blockTip.BlockHash(), blockHeight, last.HeaderHash, numHeaders)
+	}
+	filterTip, filterHeight, err := hs.FilterChainTip()
+	if err != nil {
+		t.Fatalf("unable to fetch filter tip: %v", err)
+	}
+	if filterHeight != numHeaders || *filterTip != last.FilterHash {
+		t.Fatalf("filter tip is %v at %d, expected %v at %d",
+			filterTip, filterHeight, last.FilterHash, numHeaders)
+	}
+	if err := walletdb.View(hs.Db, hs.CheckConnectivity); err != nil {
+		t.Fatalf("imported headers don't connect: %v", err)
+	}
+
+	// Importing again does nothing.
+	if _, err := hs.ImportSnapshot(bytes.NewReader(snapshot), &chaincfg.SimNetParams,
+		&last, sha256.Sum256(snapshot)); err != nil {
+		t.Fatalf("unable to import snapshot again: %v", err)
+	}
+}
+
+func TestSnapshotImportInvalid(t *testing.T) {
+	const numHeaders = 100
+	snapshot, filterHeaders := createTestSnapshot(t, numHeaders)
+	last := filterHeaders[len(filterHeaders)-1]
+
+	// A snapshot which is not the one which was expected.
+	cleanUp, _, _, hs, err := createTestBlockHeaderStore()
+	if err != nil {
+		t.Fatalf("unable to create new block header store: %v", err)
+	}
+	_, err = hs.ImportSnapshot(bytes.NewReader(snapshot), &chaincfg.SimNetParams,
+		&last, [32]byte{1})
+	cleanUp()
+	if err == nil {
+		t.Fatalf("expected a snapshot with the wrong sha256 to be rejected")
+	}
+
+	// No filter header assertion, or one which is beyond the snapshot.
+	_, _, cleanUp, err = importTestSnapshot(t, snapshot, &chaincfg.SimNetParams, nil)
+	cleanUp()
+	if err == nil {
+		t.Fatalf("expected a snapshot without an assertion to be rejected")
+	}
+	beyond := FilterHeader{Height: numHeaders + 1}
+	_, _, cleanUp, err = importTestSnapshot(t, snapshot, &chaincfg.SimNetParams, &beyond)
+	cleanUp()
+	if err == nil {
+		t.Fatalf("expected an assertion beyond the snapshot to be rejected")
+	}
+
+	// A flipped bit in a header.
+	corrupt := append([]byte{}, snapshot...)
+	corrupt[snapshotHeadLen+4+50*TotalSize] ^= 1
+	_, _, cleanUp, err = importTestSnapshot(t, corrupt, &chaincfg.SimNetParams, &last)
+	cleanUp()
+	if err == nil {
+		t.Fatalf("expected a corrupt snapshot to be rejected")
+	}
+
+	// A truncated file.
+	_, _, cleanUp, err = importTestSnapshot(t, snapshot[:len(snapshot)-10],
+		&chaincfg.SimNetParams, &last)
+	cleanUp()
+	if err == nil {
+		t.Fatalf("expected a truncated snapshot to be rejected")
+	}
+
+	// A header which does not match a checkpoint.
+	params := chaincfg.SimNetParams
+	params.Checkpoints = []chaincfg.Checkpoint{
+		{Height: 50, Hash: &chainhash.Hash{1}},
+	}
+	_, _, cleanUp, err = importTestSnapshot(t, snapshot, &params, &last)
+	cleanUp()
+	if err == nil {
+		t.Fatalf("expected a snapshot which fails a checkpoint to be rejected")
+	}
+
+	// A filter header which does not match the assertion.
+	assert := filterHeaders[60]
+	assert.FilterHash = chainhash.Hash{2}
+	hs, _, cleanUp, err = importTestSnapshot(t, snapshot, &chaincfg.SimNetParams, &assert)
+	defer cleanUp()
+	if err == nil {
+		t.Fatalf("expected a snapshot which fails the assertion to be rejected")
+	}
+
+	// Nothing from the chunk which failed is written.
+	if _, height, err := hs.FilterChainTip(); err != nil {
+		t.Fatalf("unable to fetch filter tip: %v", err)
+	} else if height != 0 {
+		t.Fatalf("expected nothing to be imported, filter tip is %d", height)
+	}
+}
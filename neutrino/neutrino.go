@@ -18,6 +18,7 @@ import (
 	"github.com/pkt-cash/pktd/btcutil/util/mailbox"
 	"github.com/pkt-cash/pktd/connmgr/banmgr"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
+	"github.com/pkt-cash/pktd/lnd/tor"
 	"github.com/pkt-cash/pktd/pktlog/log"
 	"github.com/pkt-cash/pktd/txscript"
 	"github.com/pkt-cash/pktd/wire/protocol"
@@ -585,6 +586,11 @@ type Config struct {
 	// instead.
 	NameResolver func(host string) ([]net.IP, er.R)
 
+	// Onion must be set if Dialer connects through Tor, it allows peers
+	// with .onion addresses. These addresses are passed to Dialer without
+	// being resolved.
+	Onion bool
+
 	// FilterCacheSize indicates the size (in bytes) of filters the cache will
 	// hold in memory at most.
 	FilterCacheSize uint64
@@ -650,6 +656,7 @@ type ChainService struct {
 
 	nameResolver func(string) ([]net.IP, er.R)
 	dialer       func(net.Addr) (net.Conn, er.R)
+	onion        bool
 
 	reqNum     uint32
 	queries    map[uint32]*Query
@@ -722,6 +729,7 @@ func NewChainService(cfg Config, napi *apiv1.Apiv1) (*ChainService, er.R) {
 		userAgentVersion:  UserAgentVersion,
 		nameResolver:      nameResolver,
 		dialer:            nil,
+		onion:             cfg.Onion,
 		pendingFilters:    make(map[*pendingFiltersReq]struct{}),
 		queries:           make(map[uint32]*Query),
 		invListeners:      make(map[chainhash.Hash][]chan *ServerPeer),
@@ -733,9 +741,12 @@ func NewChainService(cfg Config, napi *apiv1.Apiv1) (*ChainService, er.R) {
 
 	s.dialer = func(na net.Addr) (net.Conn, er.R) {
 		log.Infof("Attempting connection to [%v]", log.IpAddr(na.String()))
-		_, err := s.addrManager.DeserializeNetAddress(na.String(), 0)
-		if err != nil {
-			return nil, er.Errorf("Unable to parse address [%v]", log.IpAddr(na.String()))
+		// Onion addresses are only known to Tor, so they are not checked.
+		if _, ok := na.(*tor.OnionAddr); !ok {
+			_, err := s.addrManager.DeserializeNetAddress(na.String(), 0)
+			if err != nil {
+				return nil, er.Errorf("Unable to parse address [%v]", log.IpAddr(na.String()))
+			}
 		}
 
 		if cfg.Dialer != nil {
@@ -1044,6 +1055,18 @@ func (s *ChainService) BanMgr() *banmgr.BanMgr {
 	return &s.banMgr
 }
 
+// BanPeer bans the IP address of addr for the given duration and disconnects
+// every peer which is connected from that address.
+func (s *ChainService) BanPeer(addr string, duration time.Duration, reason string) {
+	s.banMgr.Ban(addr, duration, reason)
+	ip := banmgr.TrimAddress(addr)
+	s.ForAllPeers(func(sp *ServerPeer) {
+		if banmgr.TrimAddress(sp.Addr()) == ip {
+			sp.Disconnect()
+		}
+	})
+}
+
 // AddPeer adds a new peer that has already been connected to the server.
 func (s *ChainService) AddPeer(sp *ServerPeer) {
 	select {
@@ -1213,6 +1236,19 @@ func (s *ChainService) addrStringToNetAddr(addr string) (net.Addr, er.R) {
 		}
 	}
 
+	port, errr := strconv.Atoi(strPort)
+	if errr != nil {
+		return nil, er.E(errr)
+	}
+
+	// Onion addresses can not be resolved, Tor connects to them by name.
+	if tor.IsOnionHost(host) {
+		if !s.onion {
+			return nil, er.Errorf("cannot connect to [%s] without Tor", host)
+		}
+		return &tor.OnionAddr{OnionService: host, Port: port}, nil
+	}
+
 	// Attempt to look up an IP address associated with the parsed host.
 	ips, err := s.nameResolver(host)
 	if err != nil {
@@ -1223,17 +1259,23 @@ func (s *ChainService) addrStringToNetAddr(addr string) (net.Addr, er.R) {
 		return nil, er.Errorf("no addresses found for %s", host)
 	}
 
-	port, errr := strconv.Atoi(strPort)
-	if errr != nil {
-		return nil, er.E(errr)
-	}
-
 	return &net.TCPAddr{
 		IP:   ips[0],
 		Port: port,
 	}, nil
 }
 
+// hostToNetAddress is the HostToNetAddress of the address manager, except that
+// onion hosts, which have no IP address, are given the unspecified address.
+func (s *ChainService) hostToNetAddress(host string, port uint16,
+	services protocol.ServiceFlag) (*wire.NetAddress, er.R) {
+
+	if tor.IsOnionHost(host) {
+		return wire.NewNetAddressIPPort(net.IPv4zero, port, services), nil
+	}
+	return s.addrManager.HostToNetAddress(host, port, services)
+}
+
 // handleUpdatePeerHeight updates the heights of all peers who were known to
 // announce a block we recently accepted.
 func (s *ChainService) handleUpdatePeerHeights(state *peerState, umsg updatePeerHeightsMsg) {
@@ -1406,7 +1448,7 @@ func newPeerConfig(sp *ServerPeer) *peer.Config {
 			OnTx:        sp.OnTx,
 		},
 		NewestBlock:      sp.newestBlock,
-		HostToNetAddress: sp.server.addrManager.HostToNetAddress,
+		HostToNetAddress: sp.server.hostToNetAddress,
 		UserAgentName:    sp.server.userAgentName,
 		UserAgentVersion: sp.server.userAgentVersion,
 		ChainParams:      &sp.server.chainParams,
@@ -1557,6 +1599,12 @@ func (s *ChainService) Stop() {
 	s.wg.Wait()
 }
 
+// Quit returns a channel which is closed when the ChainService is stopped, so
+// that long running work which uses it can stop as well.
+func (s *ChainService) Quit() <-chan struct{} {
+	return s.quit
+}
+
 // IsCurrent lets the caller know whether the chain service's block manager
 // thinks its view of the network is current.
 func (s *ChainService) IsCurrent() bool {
@@ -7,6 +7,7 @@ import (
 	"github.com/pkt-cash/pktd/btcutil/event"
 	"github.com/pkt-cash/pktd/btcutil/lock"
 	"github.com/pkt-cash/pktd/btcutil/util"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
 )
@@ -131,6 +132,7 @@ func RegisterNew(a *apiv1.Apiv1) *SendTxStatus {
 			}
 			return &out, nil
 		},
+		help_pb.F_PERM_READ,
 	)
 	apiv1.Stream(
 		a,
@@ -144,6 +146,7 @@ func RegisterNew(a *apiv1.Apiv1) *SendTxStatus {
 				return true
 			}, nil
 		},
+		help_pb.F_PERM_READ,
 	)
 	return &sts
 }
@@ -5,8 +5,11 @@
 package main
 
 import (
+	"bufio"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -18,9 +21,14 @@ import (
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/btcutil/util"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/neutrino/headerfs"
 	"github.com/pkt-cash/pktd/pktconfig/version"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txexport"
 	"github.com/pkt-cash/pktd/pktwallet/walletdb"
 	_ "github.com/pkt-cash/pktd/pktwallet/walletdb/bdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
 )
 
 const defaultNet = "pkt"
@@ -30,8 +38,21 @@ var datadir = btcutil.AppDataDir("pktwallet", false)
 // Flags.
 var opts = struct {
 	DbPath string `long:"db" description:"Path to wallet database"`
+
+	// Export flags.
+	Net         string   `long:"net" description:"Network of the wallet, pkt or pkttest"`
+	Format      string   `long:"format" description:"Export format, csv, ofx or jsonl"`
+	Start       string   `long:"start" description:"Export transactions from this date, YYYY-MM-DD"`
+	End         string   `long:"end" description:"Export transactions before this date, YYYY-MM-DD"`
+	Address     []string `long:"address" description:"Export only this address, may be given more than once"`
+	Unconfirmed bool     `long:"unconfirmed" description:"Also export unconfirmed transactions"`
+
+	// Export headers flags.
+	Height uint32 `long:"height" description:"Export headers up to this height, default is the filter header tip"`
 }{
 	DbPath: filepath.Join(datadir, defaultNet, "wallet.db"),
+	Net:    defaultNet,
+	Format: txexport.FormatCSV,
 }
 
 func main() {
@@ -157,9 +178,99 @@ func repair(db walletdb.DB) er.R {
 	return err
 }
 
+func parseDate(s string) (time.Time, er.R) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, er.E(err)
+}
+
+func netParams() (*chaincfg.Params, er.R) {
+	var params *chaincfg.Params
+	switch opts.Net {
+	case chaincfg.PktMainNetParams.Name:
+		params = &chaincfg.PktMainNetParams
+	case chaincfg.PktTestNetParams.Name:
+		params = &chaincfg.PktTestNetParams
+	default:
+		return nil, er.Errorf("unknown network [%s]", opts.Net)
+	}
+	globalcfg.SelectConfig(params.GlobalConf)
+	return params, nil
+}
+
+func export(db walletdb.DB) er.R {
+	params, err := netParams()
+	if err != nil {
+		return err
+	}
+
+	f := &txexport.Filter{
+		Addresses:          opts.Address,
+		IncludeUnconfirmed: opts.Unconfirmed,
+	}
+	if f.Start, err = parseDate(opts.Start); err != nil {
+		return err
+	}
+	if f.End, err = parseDate(opts.End); err != nil {
+		return err
+	}
+	w, err := txexport.NewWriter(opts.Format, os.Stdout, f)
+	if err != nil {
+		return err
+	}
+	err = walletdb.View(db, func(tx walletdb.ReadTx) er.R {
+		ns := tx.ReadBucket([]byte("wtxmgr"))
+		if ns == nil {
+			return er.New("the wallet has no transaction store")
+		}
+		store, err := wtxmgr.Open(ns, params)
+		if err != nil {
+			return err
+		}
+		return txexport.Rows(ns, store, params, f, w.Write)
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// exportHeaders writes a snapshot of the block and filter headers in a
+// neutrino.db to stdout, pld can import it with --neutrino.headersnapshot.
+// The sha256 of the snapshot is printed because pld needs it to import it.
+func exportHeaders(db walletdb.DB) er.R {
+	params, err := netParams()
+	if err != nil {
+		return err
+	}
+	store, err := headerfs.NewNeutrinoDBStore(db, params, false)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(os.Stdout)
+	sum := sha256.New()
+	height, err := store.ExportSnapshot(io.MultiWriter(w, sum), opts.Height)
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return er.E(err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported headers up to height [%d]\n", height)
+	fmt.Fprintf(os.Stderr, "Import with --neutrino.headersnapshothash=%x\n", sum.Sum(nil))
+	return nil
+}
+
 var ops = map[string]func(db walletdb.DB) er.R{
-	"print":  print,
-	"repair": repair,
+	"print":         print,
+	"repair":        repair,
+	"export":        export,
+	"exportheaders": exportHeaders,
 }
 
 func mainInt() int {
@@ -171,6 +282,8 @@ func mainInt() int {
 		fmt.Println("Usage: wallettool [--db <path_to_wallet.db>] COMMAND")
 		fmt.Println("    print             # print some of the decodable keys from the wallet")
 		fmt.Println("    repair            # attempt to repair the wallet")
+		fmt.Println("    export            # print the transaction history, see --help for the options")
+		fmt.Println("    exportheaders     # write a header snapshot of a neutrino.db to stdout, see --height")
 		return 1
 	}
 
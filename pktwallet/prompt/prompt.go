@@ -247,7 +247,7 @@ func Seed(reader *bufio.Reader, passphrase []byte) ([]byte, *seedwords.Seed, er.
 	}
 
 	for {
-		fmt.Print("Enter existing wallet seed: ")
+		fmt.Print("Enter existing wallet seed, or the first share of it: ")
 		seedStr, err := reader.ReadString('\n')
 		if err != nil {
 			return nil, nil, er.E(err)
@@ -261,7 +261,7 @@ func Seed(reader *bufio.Reader, passphrase []byte) ([]byte, *seedwords.Seed, er.
 			return []byte(seedStr), nil, nil
 		}
 
-		if sw, err := seedwords.SeedFromWords(seedStr); err != nil {
+		if sw, err := seedFromInput(reader, seedStr); err != nil {
 			fmt.Printf("Invalid seed specified [%s]", err.Message())
 		} else if sw.NeedsPassphrase() {
 			fmt.Println("This seed was taken from a wallet protected by a password.")
@@ -286,3 +286,36 @@ func Seed(reader *bufio.Reader, passphrase []byte) ([]byte, *seedwords.Seed, er.
 		}
 	}
 }
+
+// seedFromInput decodes seed words, or if they are a share of a seed which
+// was split by /util/seed/split then it prompts for the other shares which are
+// needed and combines them.
+func seedFromInput(reader *bufio.Reader, seedStr string) (*seedwords.SeedEnc, er.R) {
+	if len(strings.Fields(seedStr)) != seedwords.ShareWordCount {
+		return seedwords.SeedFromWords(seedStr)
+	}
+	first, err := seedwords.ShareFromWords(seedStr)
+	if err != nil {
+		return nil, err
+	}
+	shares := []*seedwords.Share{first}
+	defer func() {
+		for _, sh := range shares {
+			sh.Zero()
+		}
+	}()
+	for len(shares) < first.Threshold() {
+		fmt.Printf("Enter share %d of %d: ", len(shares)+1, first.Threshold())
+		str, errr := reader.ReadString('\n')
+		if errr != nil {
+			return nil, er.E(errr)
+		}
+		sh, err := seedwords.ShareFromWords(strings.TrimSpace(strings.ToLower(str)))
+		if err != nil {
+			fmt.Printf("Invalid share [%s], please try again.\n", err.Message())
+			continue
+		}
+		shares = append(shares, sh)
+	}
+	return seedwords.CombineShares(shares)
+}
@@ -0,0 +1,104 @@
+package waddrmgr
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/pktd/btcutil/base58"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/chaincfg"
+)
+
+// accountKeyDepth is the depth of an account extended key in a BIP0044-like
+// derivation path: m/purpose'/coin_type'/account'
+const accountKeyDepth = 3
+
+// pubKeyPurposes maps the version bytes of the extended public keys which are
+// exported by other wallets to the BIP0044-like purpose which the version
+// implies (SLIP-0132).  The purpose is zero if the version does not imply any.
+var pubKeyPurposes = map[[4]byte]uint32{
+	{0x04, 0x88, 0xb2, 0x1e}: 0,  // xpub
+	{0x04, 0x9d, 0x7c, 0xb2}: 49, // ypub
+	{0x04, 0xb2, 0x47, 0x46}: 84, // zpub
+	{0x04, 0x35, 0x87, 0xcf}: 0,  // tpub
+	{0x04, 0x4a, 0x52, 0x62}: 49, // upub
+	{0x04, 0x5f, 0x1c, 0xf6}: 84, // vpub
+}
+
+// multisigPubKeyVersions are the versions of the extended public keys which
+// other wallets export for use as a cosigner of a multisig wallet (SLIP-0132).
+var multisigPubKeyVersions = map[[4]byte]struct{}{
+	{0x02, 0x95, 0xb4, 0x3f}: {}, // Ypub
+	{0x02, 0xaa, 0x7e, 0xd3}: {}, // Zpub
+	{0x02, 0x42, 0x89, 0xef}: {}, // Upub
+	{0x02, 0x57, 0x54, 0x83}: {}, // Vpub
+}
+
+// parsePubKey parses an extended public key and returns it with its version.
+func parsePubKey(key string) (*hdkeychain.ExtendedKey, [4]byte, er.R) {
+	var version [4]byte
+	extKey, err := hdkeychain.NewKeyFromString(key)
+	if err != nil {
+		return nil, version, err
+	}
+	if extKey.IsPrivate() {
+		str := "expected an extended public key but got a private key"
+		return nil, version, managerError(ErrKeyChain, str, nil)
+	}
+
+	// NewKeyFromString has already checked that the key decodes.
+	copy(version[:], base58.Decode(key)[:4])
+	return extKey, version, nil
+}
+
+// ParseAccountPubKey parses the extended public key of an account such as it
+// is exported by a cold storage wallet.  Keys of the wallet's own network are
+// accepted as well as xpub, ypub and zpub keys (and the testnet equivalents),
+// the returned key is converted to the network of the wallet.
+//
+// If the version of the key implies a purpose, e.g. zpub keys are for BIP0084,
+// then the purpose is returned as well, otherwise it is zero.
+func ParseAccountPubKey(key string, net *chaincfg.Params) (*hdkeychain.ExtendedKey, uint32, er.R) {
+	extKey, version, err := parsePubKey(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if extKey.Depth() != accountKeyDepth {
+		str := fmt.Sprintf("extended key has depth %d, the key of an "+
+			"account (m/purpose'/coin_type'/account') has depth %d",
+			extKey.Depth(), accountKeyDepth)
+		return nil, 0, managerError(ErrKeyChain, str, nil)
+	}
+
+	purpose, ok := pubKeyPurposes[version]
+	if !ok && !extKey.IsForNet(net) {
+		str := fmt.Sprintf("extended public key version %x is not "+
+			"known for network %s", version, net.Name)
+		return nil, 0, managerError(ErrWrongNet, str, nil)
+	}
+	extKey.SetNet(net)
+
+	return extKey, purpose, nil
+}
+
+// ParseCosignerPubKey parses the extended public key of a cosigner of a
+// multisig account.  As well as the keys which ParseAccountPubKey accepts, the
+// Ypub and Zpub keys which wallets export for multisig are accepted and the
+// key may be at any depth, e.g. m/48'/0'/0'/2'.  The returned key is
+// converted to the network of the wallet.
+func ParseCosignerPubKey(key string, net *chaincfg.Params) (*hdkeychain.ExtendedKey, er.R) {
+	extKey, version, err := parsePubKey(key)
+	if err != nil {
+		return nil, err
+	}
+	_, single := pubKeyPurposes[version]
+	_, multi := multisigPubKeyVersions[version]
+	if !single && !multi && !extKey.IsForNet(net) {
+		str := fmt.Sprintf("extended public key version %x is not "+
+			"known for network %s", version, net.Name)
+		return nil, managerError(ErrWrongNet, str, nil)
+	}
+	extKey.SetNet(net)
+
+	return extKey, nil
+}
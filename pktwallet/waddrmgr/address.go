@@ -296,6 +296,12 @@ func (a *managedAddress) PrivKey() (*btcec.PrivateKey, er.R) {
 		return nil, ErrLocked.Default()
 	}
 
+	// Addresses of a watch-only account never have a private key, even
+	// when the address manager is unlocked.
+	if a.manager.isWatchOnlyAccount(a.derivationPath.Account) {
+		return nil, ErrWatchingOnly.Default()
+	}
+
 	// Decrypt the key as needed.  Also, make sure it's a copy since the
 	// private key stored in memory can be cleared at any time.  Otherwise
 	// the returned private key could be invalidated from under the caller.
@@ -279,6 +279,12 @@ var (
 
 	// bucket containing dbNetworkStewardVote
 	networkStewardVoteName = []byte("nsvote")
+
+	// bucket containing the gap limit of each watch-only account
+	accountGapLimitName = []byte("gaplimit")
+
+	// bucket containing the multisig accounts, keyed by name
+	multisigBucketName = []byte("multisig")
 )
 
 // uint32ToBytes converts a 32 bit unsigned integer into a 4-byte slice in
@@ -1050,6 +1056,175 @@ func deleteAccountNetworkStewardVote(ns walletdb.ReadWriteBucket, scope *KeyScop
 	return bucket.Delete(uint32ToBytes(account))
 }
 
+// fetchAccountGapLimit returns the gap limit of an account, or zero if none
+// has been stored for it.
+func fetchAccountGapLimit(ns walletdb.ReadBucket, scope *KeyScope,
+	account uint32) (uint32, er.R) {
+
+	scopedBucket, err := fetchReadScopeBucket(ns, scope)
+	if err != nil {
+		return 0, err
+	}
+
+	bucket := scopedBucket.NestedReadBucket(accountGapLimitName)
+	if bucket == nil {
+		return 0, nil
+	}
+
+	v := bucket.Get(uint32ToBytes(account))
+	if len(v) != 4 {
+		return 0, nil
+	}
+	return binary.LittleEndian.Uint32(v), nil
+}
+
+func putAccountGapLimit(ns walletdb.ReadWriteBucket, scope *KeyScope,
+	account uint32, gapLimit uint32) er.R {
+
+	scopedBucket, err := fetchWriteScopeBucket(ns, scope)
+	if err != nil {
+		return err
+	}
+	bucket := scopedBucket.NestedReadWriteBucket(accountGapLimitName)
+
+	if bucket == nil {
+		bucket, err = scopedBucket.CreateBucket(accountGapLimitName)
+		if err != nil {
+			str := "failed to create a gap limit bucket"
+			return managerError(ErrDatabase, str, err)
+		}
+	}
+
+	return bucket.Put(uint32ToBytes(account), uint32ToBytes(gapLimit))
+}
+
+// dbMultisigAccount is the database representation of a multisig account.
+type dbMultisigAccount struct {
+	threshold         uint32
+	gapLimit          uint32
+	nextExternalIndex uint32
+	nextInternalIndex uint32
+	encryptedKeys     [][]byte
+}
+
+// deserializeMultisigAccountRow deserializes a multisig account from the
+// database.
+func deserializeMultisigAccountRow(name string, raw []byte) (*dbMultisigAccount, er.R) {
+	// The serialized multisig account format is:
+	//   <threshold><gaplimit><nextextidx><nextintidx><numkeys>
+	//   (<enckeylen><enckey>)*
+	//
+	// 4 bytes threshold + 4 bytes gap limit + 4 bytes next external index +
+	// 4 bytes next internal index + 1 byte number of keys, then for each
+	// key 2 bytes encrypted key len + encrypted key
+	if len(raw) < 17 {
+		str := fmt.Sprintf("malformed serialized multisig account %s", name)
+		return nil, managerError(ErrDatabase, str, nil)
+	}
+
+	row := dbMultisigAccount{
+		threshold:         binary.LittleEndian.Uint32(raw[0:4]),
+		gapLimit:          binary.LittleEndian.Uint32(raw[4:8]),
+		nextExternalIndex: binary.LittleEndian.Uint32(raw[8:12]),
+		nextInternalIndex: binary.LittleEndian.Uint32(raw[12:16]),
+	}
+	numKeys := int(raw[16])
+	offset := 17
+	for i := 0; i < numKeys; i++ {
+		if len(raw) < offset+2 {
+			str := fmt.Sprintf("malformed serialized multisig account %s", name)
+			return nil, managerError(ErrDatabase, str, nil)
+		}
+		keyLen := int(binary.LittleEndian.Uint16(raw[offset : offset+2]))
+		offset += 2
+		if len(raw) < offset+keyLen {
+			str := fmt.Sprintf("malformed serialized multisig account %s", name)
+			return nil, managerError(ErrDatabase, str, nil)
+		}
+		key := make([]byte, keyLen)
+		copy(key, raw[offset:offset+keyLen])
+		row.encryptedKeys = append(row.encryptedKeys, key)
+		offset += keyLen
+	}
+	return &row, nil
+}
+
+// serializeMultisigAccountRow returns the serialization of a multisig account.
+func serializeMultisigAccountRow(row *dbMultisigAccount) []byte {
+	size := 17
+	for _, key := range row.encryptedKeys {
+		size += 2 + len(key)
+	}
+	buf := make([]byte, size)
+	binary.LittleEndian.PutUint32(buf[0:4], row.threshold)
+	binary.LittleEndian.PutUint32(buf[4:8], row.gapLimit)
+	binary.LittleEndian.PutUint32(buf[8:12], row.nextExternalIndex)
+	binary.LittleEndian.PutUint32(buf[12:16], row.nextInternalIndex)
+	buf[16] = byte(len(row.encryptedKeys))
+	offset := 17
+	for _, key := range row.encryptedKeys {
+		binary.LittleEndian.PutUint16(buf[offset:offset+2], uint16(len(key)))
+		offset += 2
+		copy(buf[offset:], key)
+		offset += len(key)
+	}
+	return buf
+}
+
+// fetchMultisigAccount loads a multisig account from the database, it returns
+// nil if there is no multisig account with the given name.
+func fetchMultisigAccount(ns walletdb.ReadBucket, name string) (*dbMultisigAccount, er.R) {
+	bucket := ns.NestedReadBucket(multisigBucketName)
+	if bucket == nil {
+		return nil, nil
+	}
+	raw := bucket.Get([]byte(name))
+	if raw == nil {
+		return nil, nil
+	}
+	return deserializeMultisigAccountRow(name, raw)
+}
+
+// forEachMultisigAccount calls fn with each multisig account in the database.
+func forEachMultisigAccount(ns walletdb.ReadBucket,
+	fn func(name string, row *dbMultisigAccount) er.R) er.R {
+
+	bucket := ns.NestedReadBucket(multisigBucketName)
+	if bucket == nil {
+		return nil
+	}
+	return bucket.ForEach(func(k, v []byte) er.R {
+		row, err := deserializeMultisigAccountRow(string(k), v)
+		if err != nil {
+			return err
+		}
+		return fn(string(k), row)
+	})
+}
+
+// putMultisigAccount stores a multisig account in the database, replacing any
+// account with the same name.
+func putMultisigAccount(ns walletdb.ReadWriteBucket, name string,
+	row *dbMultisigAccount) er.R {
+
+	bucket := ns.NestedReadWriteBucket(multisigBucketName)
+	if bucket == nil {
+		var err er.R
+		bucket, err = ns.CreateBucket(multisigBucketName)
+		if err != nil {
+			str := "failed to create a multisig bucket"
+			return managerError(ErrDatabase, str, err)
+		}
+	}
+
+	err := bucket.Put([]byte(name), serializeMultisigAccountRow(row))
+	if err != nil {
+		str := fmt.Sprintf("failed to store multisig account %s", name)
+		return managerError(ErrDatabase, str, err)
+	}
+	return nil
+}
+
 // deleteAccountNameIndex deletes the given key from the account name index of the database.
 func deleteAccountNameIndex(ns walletdb.ReadWriteBucket, scope *KeyScope,
 	name string) er.R {
@@ -154,6 +154,11 @@ type accountInfo struct {
 	acctKeyPriv      *hdkeychain.ExtendedKey
 	acctKeyPub       *hdkeychain.ExtendedKey
 
+	// A watch-only account was imported from an extended public key, it
+	// has no private key so its keys are always derived from acctKeyPub
+	// even when the address manager is unlocked.
+	watchOnly bool
+
 	// The external branch is used for all addresses which are intended for
 	// external use.
 	nextExternalIndex uint32
@@ -173,6 +178,19 @@ type AccountProperties struct {
 	ExternalKeyCount uint32
 	InternalKeyCount uint32
 	ImportedKeyCount uint32
+
+	// WatchOnly is true if the account was imported from an extended
+	// public key and therefore can not sign.
+	WatchOnly bool
+
+	// GapLimit is the number of unused addresses which are kept derived
+	// past the last used address of a watch-only account.
+	GapLimit uint32
+
+	// AccountPubKey is the extended public key of the account, it is nil
+	// for the imported account.  It is shared with the manager and must
+	// not be zeroed.
+	AccountPubKey *hdkeychain.ExtendedKey
 }
 
 // unlockDeriveInfo houses the information needed to derive a private key for a
@@ -562,6 +580,20 @@ func (m *Manager) FetchScopedKeyManager(scope KeyScope) (*ScopedKeyManager, er.R
 	return sm, nil
 }
 
+// ActiveScopedKeyManagers returns a slice of all the active scoped key
+// managers currently known by the root key manager.
+func (m *Manager) ActiveScopedKeyManagers() []*ScopedKeyManager {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	scopedManagers := make([]*ScopedKeyManager, 0, len(m.scopedManagers))
+	for _, smgr := range m.scopedManagers {
+		scopedManagers = append(scopedManagers, smgr)
+	}
+
+	return scopedManagers
+}
+
 // NeuterRootKey is a special method that should be used once a caller is
 // *certain* that no further scoped managers are to be created. This method
 // will *delete* the encrypted master HD root private key from the database.
@@ -1081,6 +1113,9 @@ func (m *Manager) Unlock(ns walletdb.ReadBucket, passphrase []byte) er.R {
 	// extended keys.
 	for _, manager := range m.scopedManagers {
 		for account, acctInfo := range manager.acctInfo {
+			if acctInfo.watchOnly {
+				continue
+			}
 			decrypted, err := m.cryptoKeyPriv.Decrypt(acctInfo.acctKeyEncrypted)
 			if err != nil {
 				m.lock()
@@ -1103,6 +1138,15 @@ func (m *Manager) Unlock(ns walletdb.ReadBucket, passphrase []byte) er.R {
 		// We'll also derive any private keys that are pending due to
 		// them being created while the address manager was locked.
 		for _, info := range manager.deriveOnUnlock {
+			// Addresses of watch-only accounts have no private key
+			// to derive, they can be queued here if the account was
+			// loaded while the manager was locked.
+			if manager.isWatchOnlyAccount(info.managedAddr.Account()) {
+				manager.deriveOnUnlock[0] = nil
+				manager.deriveOnUnlock = manager.deriveOnUnlock[1:]
+				continue
+			}
+
 			addressKey, err := manager.deriveKeyFromPath(
 				ns, info.managedAddr.Account(), info.branch,
 				info.index, true,
@@ -1362,6 +1406,22 @@ func deriveAccountKey(coinTypeKey *hdkeychain.ExtendedKey,
 	return coinTypeKey.DeriveNonStandard(account + hdkeychain.HardenedKeyStart)
 }
 
+// DeriveAccountKey derives the extended key of an account in a scope from the
+// master key, the same way as the manager does.  This is for finding the keys
+// of a seed which does not belong to this wallet.
+//
+// In particular this is the hierarchical deterministic extended key path:
+//   m/purpose'/<coin type>'/<account>'
+func DeriveAccountKey(masterNode *hdkeychain.ExtendedKey, scope KeyScope,
+	account uint32) (*hdkeychain.ExtendedKey, er.R) {
+
+	coinTypeKey, err := deriveCoinTypeKey(masterNode, scope)
+	if err != nil {
+		return nil, err
+	}
+	return deriveAccountKey(coinTypeKey, account)
+}
+
 // checkBranchKeys ensures deriving the extended keys for the internal and
 // external branches given an account key does not result in an invalid child
 // error which means the chosen seed is not usable.  This conforms to the
@@ -15,7 +15,9 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/base58"
 	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
 	"github.com/pkt-cash/pktd/btcutil/util"
 	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
@@ -2454,3 +2456,202 @@ func TestNewRawAccount(t *testing.T) {
 			accountTargetAddr.AddrHash())
 	}
 }
+
+// TestNewAccountWatchingOnly tests that an account which is imported from an
+// extended public key derives the same addresses as the account it was
+// exported from, but is never able to give up a private key.
+func TestNewAccountWatchingOnly(t *testing.T) {
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	scopedMgr, err := mgr.FetchScopedKeyManager(KeyScopeBIP0084)
+	if err != nil {
+		t.Fatalf("unable to fetch scope %v: %v", KeyScopeBIP0084, err)
+	}
+
+	// Create a normal account to act as the cold storage wallet and take
+	// its first address and its extended public key.
+	const coldAccount = 1000
+	var coldAddr ManagedAddress
+	var xpub string
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) er.R {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		if err := mgr.Unlock(ns, privPassphrase); err != nil {
+			return err
+		}
+		if err := scopedMgr.NewRawAccount(ns, coldAccount); err != nil {
+			return err
+		}
+		addrs, err := scopedMgr.NextExternalAddresses(ns, coldAccount, 1)
+		if err != nil {
+			return err
+		}
+		coldAddr = addrs[0]
+		acctInfo, err := scopedMgr.loadAccountInfo(ns, coldAccount)
+		if err != nil {
+			return err
+		}
+		xpub = acctInfo.acctKeyPub.String()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to create cold account: %v", err)
+	}
+
+	// Export it as a zpub, the way a BIP0084 wallet would.
+	raw := base58.Decode(xpub)
+	payload := append([]byte{0x04, 0xb2, 0x47, 0x46}, raw[4:len(raw)-4]...)
+	zpub := base58.Encode(append(payload, chainhash.DoubleHashB(payload)[:4]...))
+
+	pubKey, purpose, err := ParseAccountPubKey(zpub, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to parse zpub: %v", err)
+	}
+	if purpose != 84 {
+		t.Fatalf("expected purpose 84 for a zpub, got %d", purpose)
+	}
+	if pubKey.String() != xpub {
+		t.Fatalf("parsed key is %s, expected %s", pubKey.String(), xpub)
+	}
+
+	const gapLimit = 20
+	var watchAccount uint32
+	var watchAddr ManagedAddress
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) er.R {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		var err er.R
+		watchAccount, err = scopedMgr.NewAccountWatchingOnly(ns, "cold", pubKey, gapLimit)
+		if err != nil {
+			return err
+		}
+		addrs, err := scopedMgr.NextExternalAddresses(ns, watchAccount, 1)
+		if err != nil {
+			return err
+		}
+		watchAddr = addrs[0]
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to create watch-only account: %v", err)
+	}
+
+	if watchAddr.Address().String() != coldAddr.Address().String() {
+		t.Fatalf("watch-only account derived %s, expected %s",
+			watchAddr.Address(), coldAddr.Address())
+	}
+	_, err = watchAddr.(ManagedPubKeyAddress).PrivKey()
+	if !ErrWatchingOnly.Is(err) {
+		t.Fatalf("expected ErrWatchingOnly, got %v", err)
+	}
+
+	// Locking and unlocking must skip the account which has no private
+	// key.
+	err = walletdb.View(db, func(tx walletdb.ReadTx) er.R {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		if err := mgr.Lock(); err != nil {
+			return err
+		}
+		if err := mgr.Unlock(ns, privPassphrase); err != nil {
+			return err
+		}
+		props, err := scopedMgr.AccountProperties(ns, watchAccount)
+		if err != nil {
+			return err
+		}
+		if !props.WatchOnly || props.GapLimit != gapLimit {
+			return er.Errorf("unexpected properties %+v", props)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestMultisigAccount checks that multisig accounts are stored and loaded with
+// their keys and that only the address indexes can be updated.
+func TestMultisigAccount(t *testing.T) {
+	teardown, db, mgr := setupManager(t)
+	defer teardown()
+
+	var keys []*hdkeychain.ExtendedKey
+	for i := byte(0); i < 3; i++ {
+		seed := bytes.Repeat([]byte{i + 1}, hdkeychain.RecommendedSeedLen)
+		master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("unable to make key: %v", err)
+		}
+		pub, err := master.Neuter()
+		if err != nil {
+			t.Fatalf("unable to neuter key: %v", err)
+		}
+		keys = append(keys, pub)
+	}
+
+	acct := &MultisigAccount{
+		Name:      "treasury",
+		Threshold: 2,
+		Keys:      keys,
+		GapLimit:  20,
+	}
+	err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) er.R {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		if err := mgr.NewMultisigAccount(ns, acct); err != nil {
+			return err
+		}
+		if err := mgr.NewMultisigAccount(ns, acct); !ErrDuplicateAccount.Is(err) {
+			return er.Errorf("expected ErrDuplicateAccount, got %v", err)
+		}
+		bad := *acct
+		bad.Name = "bad"
+		bad.Threshold = 4
+		if err := mgr.NewMultisigAccount(ns, &bad); !ErrInvalidAccount.Is(err) {
+			return er.Errorf("expected ErrInvalidAccount, got %v", err)
+		}
+		updated := *acct
+		updated.Threshold = 1
+		updated.NextExternalIndex = 5
+		updated.NextInternalIndex = 2
+		return mgr.UpdateMultisigAccount(ns, &updated)
+	})
+	if err != nil {
+		t.Fatalf("unable to store multisig account: %v", err)
+	}
+
+	err = walletdb.View(db, func(tx walletdb.ReadTx) er.R {
+		ns := tx.ReadBucket(waddrmgrNamespaceKey)
+		got, err := mgr.FetchMultisigAccount(ns, "treasury")
+		if err != nil {
+			return err
+		}
+		if got.Threshold != 2 || got.GapLimit != 20 ||
+			got.NextExternalIndex != 5 || got.NextInternalIndex != 2 {
+			return er.Errorf("unexpected multisig account %+v", got)
+		}
+		if len(got.Keys) != len(keys) {
+			return er.Errorf("got %d keys, expected %d", len(got.Keys), len(keys))
+		}
+		for i, key := range got.Keys {
+			if key.String() != keys[i].String() {
+				return er.Errorf("key %d is %s, expected %s", i, key, keys[i])
+			}
+		}
+		if _, err := mgr.FetchMultisigAccount(ns, "nope"); !ErrAccountNotFound.Is(err) {
+			return er.Errorf("expected ErrAccountNotFound, got %v", err)
+		}
+		count := 0
+		if err := mgr.ForEachMultisigAccount(ns, func(*MultisigAccount) er.R {
+			count++
+			return nil
+		}); err != nil {
+			return err
+		}
+		if count != 1 {
+			return er.Errorf("expected 1 multisig account, got %d", count)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
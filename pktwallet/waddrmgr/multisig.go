@@ -0,0 +1,198 @@
+package waddrmgr
+
+import (
+	"fmt"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/txscript/params"
+)
+
+// MultisigAccount is an m-of-n account whose addresses are P2WSH multisig
+// scripts.  Each address is made from the keys which are derived at the same
+// branch and index from the account key of each cosigner.
+//
+// The address manager only stores the account, the scripts are derived and
+// imported by the wallet.
+type MultisigAccount struct {
+	Name string
+
+	// Threshold is the number of signatures which are needed to spend.
+	Threshold uint32
+
+	// Keys are the extended public keys of the cosigners, in the order in
+	// which they were given.
+	Keys []*hdkeychain.ExtendedKey
+
+	// GapLimit is the number of addresses which are derived past the last
+	// address which was given out, so that payments to addresses given out
+	// by the other cosigners will be seen.
+	GapLimit uint32
+
+	// NextExternalIndex and NextInternalIndex are the indexes of the next
+	// receiving and change addresses.
+	NextExternalIndex uint32
+	NextInternalIndex uint32
+}
+
+// validate checks that a multisig account can be stored.
+func (a *MultisigAccount) validate() er.R {
+	if err := ValidateAccountName(a.Name); err != nil {
+		return err
+	}
+	if len(a.Keys) == 0 || len(a.Keys) > params.MaxPubKeysPerMultiSig {
+		str := fmt.Sprintf("a multisig account needs between 1 and %d keys, "+
+			"got %d", params.MaxPubKeysPerMultiSig, len(a.Keys))
+		return managerError(ErrInvalidAccount, str, nil)
+	}
+	if a.Threshold == 0 || a.Threshold > uint32(len(a.Keys)) {
+		str := fmt.Sprintf("threshold %d is not possible with %d keys",
+			a.Threshold, len(a.Keys))
+		return managerError(ErrInvalidAccount, str, nil)
+	}
+	seen := make(map[string]struct{}, len(a.Keys))
+	for _, key := range a.Keys {
+		if key.IsPrivate() {
+			str := "multisig accounts are made from extended public keys"
+			return managerError(ErrKeyChain, str, nil)
+		}
+		if _, ok := seen[key.String()]; ok {
+			str := fmt.Sprintf("key %s is given more than once", key.String())
+			return managerError(ErrInvalidAccount, str, nil)
+		}
+		seen[key.String()] = struct{}{}
+	}
+	return nil
+}
+
+// multisigToRow encrypts the keys of a multisig account for storage.
+//
+// This function MUST be called with the manager lock held for reads.
+func (m *Manager) multisigToRow(a *MultisigAccount) (*dbMultisigAccount, er.R) {
+	row := dbMultisigAccount{
+		threshold:         a.Threshold,
+		gapLimit:          a.GapLimit,
+		nextExternalIndex: a.NextExternalIndex,
+		nextInternalIndex: a.NextInternalIndex,
+	}
+	for _, key := range a.Keys {
+		enc, err := m.cryptoKeyPub.Encrypt([]byte(key.String()))
+		if err != nil {
+			str := "failed to encrypt multisig key"
+			return nil, managerError(ErrCrypto, str, err)
+		}
+		row.encryptedKeys = append(row.encryptedKeys, enc)
+	}
+	return &row, nil
+}
+
+// multisigFromRow decrypts a multisig account which was loaded from the
+// database.
+//
+// This function MUST be called with the manager lock held for reads.
+func (m *Manager) multisigFromRow(name string, row *dbMultisigAccount) (*MultisigAccount, er.R) {
+	a := MultisigAccount{
+		Name:              name,
+		Threshold:         row.threshold,
+		GapLimit:          row.gapLimit,
+		NextExternalIndex: row.nextExternalIndex,
+		NextInternalIndex: row.nextInternalIndex,
+	}
+	for _, enc := range row.encryptedKeys {
+		serialized, err := m.cryptoKeyPub.Decrypt(enc)
+		if err != nil {
+			str := fmt.Sprintf("failed to decrypt key of multisig account %s", name)
+			return nil, managerError(ErrCrypto, str, err)
+		}
+		key, err := hdkeychain.NewKeyFromString(string(serialized))
+		if err != nil {
+			str := fmt.Sprintf("failed to parse key of multisig account %s", name)
+			return nil, managerError(ErrKeyChain, str, err)
+		}
+		a.Keys = append(a.Keys, key)
+	}
+	return &a, nil
+}
+
+// NewMultisigAccount stores a new multisig account, the name must not be used
+// by any other multisig account.
+func (m *Manager) NewMultisigAccount(ns walletdb.ReadWriteBucket, a *MultisigAccount) er.R {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if err := a.validate(); err != nil {
+		return err
+	}
+	if existing, err := fetchMultisigAccount(ns, a.Name); err != nil {
+		return err
+	} else if existing != nil {
+		str := fmt.Sprintf("multisig account with the name '%s' already exists", a.Name)
+		return managerError(ErrDuplicateAccount, str, nil)
+	}
+	row, err := m.multisigToRow(a)
+	if err != nil {
+		return err
+	}
+	return putMultisigAccount(ns, a.Name, row)
+}
+
+// UpdateMultisigAccount stores the next address indexes of a multisig account,
+// the threshold and keys of an account can not be changed.
+func (m *Manager) UpdateMultisigAccount(ns walletdb.ReadWriteBucket, a *MultisigAccount) er.R {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	row, err := fetchMultisigAccount(ns, a.Name)
+	if err != nil {
+		return err
+	} else if row == nil {
+		str := fmt.Sprintf("no multisig account named '%s'", a.Name)
+		return managerError(ErrAccountNotFound, str, nil)
+	}
+	row.nextExternalIndex = a.NextExternalIndex
+	row.nextInternalIndex = a.NextInternalIndex
+	return putMultisigAccount(ns, a.Name, row)
+}
+
+// FetchMultisigAccount loads a multisig account by name.
+func (m *Manager) FetchMultisigAccount(ns walletdb.ReadBucket, name string) (*MultisigAccount, er.R) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	row, err := fetchMultisigAccount(ns, name)
+	if err != nil {
+		return nil, err
+	} else if row == nil {
+		str := fmt.Sprintf("no multisig account named '%s'", name)
+		return nil, managerError(ErrAccountNotFound, str, nil)
+	}
+	return m.multisigFromRow(name, row)
+}
+
+// ForEachMultisigAccount calls fn with each multisig account.
+func (m *Manager) ForEachMultisigAccount(ns walletdb.ReadBucket,
+	fn func(a *MultisigAccount) er.R) er.R {
+
+	m.mtx.RLock()
+	var accounts []*MultisigAccount
+	err := forEachMultisigAccount(ns, func(name string, row *dbMultisigAccount) er.R {
+		a, err := m.multisigFromRow(name, row)
+		if err != nil {
+			return err
+		}
+		accounts = append(accounts, a)
+		return nil
+	})
+	m.mtx.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	for _, a := range accounts {
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
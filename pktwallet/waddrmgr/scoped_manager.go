@@ -250,7 +250,7 @@ func (s *ScopedKeyManager) keyToManaged(derivedKey *hdkeychain.ExtendedKey,
 		return nil, err
 	}
 
-	if !derivedKey.IsPrivate() {
+	if !derivedKey.IsPrivate() && !s.isWatchOnlyAccount(account) {
 		// Add the managed address to the list of addresses that need
 		// their private keys derived when the address manager is next
 		// unlocked.
@@ -276,9 +276,10 @@ func (s *ScopedKeyManager) deriveKey(acctInfo *accountInfo, branch,
 
 	// Choose the public or private extended key based on whether or not
 	// the private flag was specified.  This, in turn, allows for public or
-	// private child derivation.
+	// private child derivation.  Watch-only accounts only have the public
+	// key.
 	acctKey := acctInfo.acctKeyPub
-	if private {
+	if private && !acctInfo.watchOnly {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -302,6 +303,15 @@ func (s *ScopedKeyManager) deriveKey(acctInfo *accountInfo, branch,
 	return addressKey, nil
 }
 
+// isWatchOnlyAccount returns true if the account has been loaded and it is a
+// watch-only account.
+//
+// This function MUST be called with the manager lock held for reads.
+func (s *ScopedKeyManager) isWatchOnlyAccount(account uint32) bool {
+	acctInfo, ok := s.acctInfo[account]
+	return ok && acctInfo.watchOnly
+}
+
 // loadAccountInfo attempts to load and cache information about the given
 // account from the database.   This includes what is necessary to derive new
 // keys for it and track the state of the internal and external branches.
@@ -352,9 +362,10 @@ func (s *ScopedKeyManager) loadAccountInfo(ns walletdb.ReadBucket,
 		acctKeyPub:        acctKeyPub,
 		nextExternalIndex: row.nextExternalIndex,
 		nextInternalIndex: row.nextInternalIndex,
+		watchOnly:         len(row.privKeyEncrypted) == 0 && !s.rootManager.watchOnly(),
 	}
 
-	if !s.rootManager.isLocked() {
+	if !s.rootManager.isLocked() && !acctInfo.watchOnly {
 		// Use the crypto private key to decrypt the account private
 		// extended keys.
 		decrypted, err := s.rootManager.cryptoKeyPriv.Decrypt(acctInfo.acctKeyEncrypted)
@@ -544,6 +555,15 @@ func (s *ScopedKeyManager) AccountProperties(ns walletdb.ReadBucket,
 		props.AccountName = acctInfo.acctName
 		props.ExternalKeyCount = acctInfo.nextExternalIndex
 		props.InternalKeyCount = acctInfo.nextInternalIndex
+		props.WatchOnly = acctInfo.watchOnly
+		props.AccountPubKey = acctInfo.acctKeyPub
+		if acctInfo.watchOnly {
+			gapLimit, err := fetchAccountGapLimit(ns, &s.scope, account)
+			if err != nil {
+				return nil, maybeConvertDbError(err)
+			}
+			props.GapLimit = gapLimit
+		}
 	} else {
 		props.AccountName = ImportedAddrAccountName // reserved, nonchangable
 
@@ -804,7 +824,7 @@ func (s *ScopedKeyManager) nextAddresses(ns walletdb.ReadWriteBucket,
 	// Choose the account key to used based on whether the address manager
 	// is locked.
 	acctKey := acctInfo.acctKeyPub
-	if !s.rootManager.IsLocked() {
+	if !s.rootManager.IsLocked() && !acctInfo.watchOnly {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -959,7 +979,9 @@ func (s *ScopedKeyManager) nextAddresses(ns walletdb.ReadWriteBucket,
 			// Add the new managed address to the list of addresses
 			// that need their private keys derived when the
 			// address manager is next unlocked.
-			if s.rootManager.isLocked() && !s.rootManager.watchOnly() {
+			if s.rootManager.isLocked() && !s.rootManager.watchOnly() &&
+				!acctInfo.watchOnly {
+
 				s.deriveOnUnlock = append(s.deriveOnUnlock, info)
 			}
 		}
@@ -999,7 +1021,7 @@ func (s *ScopedKeyManager) extendAddresses(ns walletdb.ReadWriteBucket,
 	// Choose the account key to used based on whether the address manager
 	// is locked.
 	acctKey := acctInfo.acctKeyPub
-	if !s.rootManager.IsLocked() {
+	if !s.rootManager.IsLocked() && !acctInfo.watchOnly {
 		acctKey = acctInfo.acctKeyPriv
 	}
 
@@ -1147,7 +1169,9 @@ func (s *ScopedKeyManager) extendAddresses(ns walletdb.ReadWriteBucket,
 		// Add the new managed address to the list of addresses that
 		// need their private keys derived when the address manager is
 		// next unlocked.
-		if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() {
+		if s.rootManager.IsLocked() && !s.rootManager.WatchOnly() &&
+			!acctInfo.watchOnly {
+
 			s.deriveOnUnlock = append(s.deriveOnUnlock, info)
 		}
 	}
@@ -1306,6 +1330,9 @@ func (s *ScopedKeyManager) GetSecret(ns walletdb.ReadBucket, account uint32, nam
 		return nil, err
 	}
 
+	if acctInfo.watchOnly {
+		return nil, ErrWatchingOnly.Default()
+	}
 	if s.rootManager.IsLocked() {
 		return nil, er.New("You need to enter your wallet passphrase before getting a secret")
 	}
@@ -1452,6 +1479,67 @@ func (s *ScopedKeyManager) newAccount(ns walletdb.ReadWriteBucket,
 	return putLastAccount(ns, &s.scope, account)
 }
 
+// NewAccountWatchingOnly creates a new account from the extended public key of
+// an account which is held elsewhere, e.g. in a cold storage wallet.  Addresses
+// of the account can be derived and watched but nothing can be signed because
+// the private key is not known.  The gap limit is stored with the account so
+// that the wallet knows how far past the last used address to look for
+// payments.  If an account with the same name already exists,
+// ErrDuplicateAccount will be returned.  The manager does not need to be
+// unlocked.
+func (s *ScopedKeyManager) NewAccountWatchingOnly(ns walletdb.ReadWriteBucket,
+	name string, pubKey *hdkeychain.ExtendedKey, gapLimit uint32) (uint32, er.R) {
+
+	if pubKey.IsPrivate() {
+		str := "a watch-only account must be created from a public key"
+		return 0, managerError(ErrKeyChain, str, nil)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// Validate the account name.
+	if err := ValidateAccountName(name); err != nil {
+		return 0, err
+	}
+
+	// Check that account with the same name does not exist
+	_, err := s.lookupAccount(ns, name)
+	if err == nil {
+		str := "account with the same name already exists"
+		return 0, managerError(ErrDuplicateAccount, str, err)
+	}
+
+	account, err := fetchLastAccount(ns, &s.scope)
+	if err != nil {
+		return 0, err
+	}
+	account++
+
+	// Only the public key is stored, the empty private key is what marks
+	// the account as watch-only.
+	acctPubEnc, err := s.rootManager.cryptoKeyPub.Encrypt(
+		[]byte(pubKey.String()),
+	)
+	if err != nil {
+		str := "failed to encrypt public key for account"
+		return 0, managerError(ErrCrypto, str, err)
+	}
+	err = putAccountInfo(ns, &s.scope, account, acctPubEnc, nil, 0, 0, name)
+	if err != nil {
+		return 0, err
+	}
+	if err := putAccountGapLimit(ns, &s.scope, account, gapLimit); err != nil {
+		return 0, err
+	}
+
+	// Save last account metadata
+	if err := putLastAccount(ns, &s.scope, account); err != nil {
+		return 0, err
+	}
+	return account, nil
+}
+
 // RenameAccount renames an account stored in the manager based on the given
 // account number with the given name.  If an account with the same name
 // already exists, ErrDuplicateAccount will be returned.
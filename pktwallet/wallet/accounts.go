@@ -0,0 +1,395 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txauthor"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+)
+
+// DefaultGapLimit is the number of unused addresses which are derived past the
+// last used address of a watch-only account if no gap limit is given.
+const DefaultGapLimit = 20
+
+// AccountResult is an account of the wallet with its balance
+type AccountResult struct {
+	Scope waddrmgr.KeyScope
+	waddrmgr.AccountProperties
+	Balances
+}
+
+// Accounts returns every account of every key scope along with the balance of
+// each.
+func (w *Wallet) Accounts(confirms int32) ([]*AccountResult, er.R) {
+	var out []*AccountResult
+	byAddr := make(map[string]*AccountResult)
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		for _, manager := range w.Manager.ActiveScopedKeyManagers() {
+			if err := manager.ForEachAccount(addrmgrNs, func(account uint32) er.R {
+				props, err := manager.AccountProperties(addrmgrNs, account)
+				if err != nil {
+					return err
+				}
+				ar := &AccountResult{Scope: manager.Scope(), AccountProperties: *props}
+				out = append(out, ar)
+				return manager.ForEachAccountAddress(addrmgrNs, account,
+					func(maddr waddrmgr.ManagedAddress) er.R {
+						byAddr[maddr.Address().String()] = ar
+						return nil
+					})
+			}); err != nil {
+				return err
+			}
+		}
+
+		syncBlock := w.Manager.SyncedTo()
+		_, err := w.TxStore.ForEachUnspentOutput(txmgrNs, nil, nil, func(_ []byte, uns *dbstructs.Unspent) er.R {
+			ar := byAddr[uns.Address]
+			if ar == nil {
+				return nil
+			}
+			ar.Total += btcutil.Amount(uns.Value)
+			ar.OutputCount++
+			if uns.FromCoinBase && !confirmed(int32(w.chainParams.CoinbaseMaturity),
+				uns.Block.Height, syncBlock.Height) {
+				ar.ImmatureReward += btcutil.Amount(uns.Value)
+			} else if confirmed(confirms, uns.Block.Height, syncBlock.Height) {
+				ar.Spendable += btcutil.Amount(uns.Value)
+			} else {
+				ar.Unconfirmed += btcutil.Amount(uns.Value)
+			}
+			return nil
+		})
+		return err
+	})
+	return out, err
+}
+
+// ScopeForPurpose returns the key scope which this wallet uses for the given
+// purpose, so that callers which only know the purpose get the wallet's own
+// coin type.
+func (w *Wallet) ScopeForPurpose(purpose uint32) (waddrmgr.KeyScope, er.R) {
+	for _, scope := range waddrmgr.DefaultKeyScopes {
+		if scope.Purpose == purpose {
+			return scope, nil
+		}
+	}
+	var found []waddrmgr.KeyScope
+	for _, manager := range w.Manager.ActiveScopedKeyManagers() {
+		if manager.Scope().Purpose == purpose {
+			found = append(found, manager.Scope())
+		}
+	}
+	if len(found) == 0 {
+		return waddrmgr.KeyScope{}, er.Errorf("the wallet has no key scope for purpose [%d]", purpose)
+	} else if len(found) > 1 {
+		return waddrmgr.KeyScope{}, er.Errorf("the wallet has more than one key scope "+
+			"for purpose [%d], the coin must be given", purpose)
+	}
+	return found[0], nil
+}
+
+// LookupAccount finds an account by name, if scope is nil then every key scope
+// is searched and an error is returned if more than one has an account with
+// that name.
+func (w *Wallet) LookupAccount(name string, scope *waddrmgr.KeyScope) (waddrmgr.KeyScope, uint32, er.R) {
+	var managers []*waddrmgr.ScopedKeyManager
+	if scope != nil {
+		manager, err := w.Manager.FetchScopedKeyManager(*scope)
+		if err != nil {
+			return waddrmgr.KeyScope{}, 0, err
+		}
+		managers = append(managers, manager)
+	} else {
+		managers = w.Manager.ActiveScopedKeyManagers()
+	}
+	var found []waddrmgr.KeyScope
+	var account uint32
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		for _, manager := range managers {
+			acct, err := manager.LookupAccount(addrmgrNs, name)
+			if waddrmgr.ErrAccountNotFound.Is(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			found = append(found, manager.Scope())
+			account = acct
+		}
+		return nil
+	})
+	if err != nil {
+		return waddrmgr.KeyScope{}, 0, err
+	}
+	if len(found) == 0 {
+		return waddrmgr.KeyScope{}, 0, waddrmgr.ErrAccountNotFound.New(
+			"no account named ["+name+"]", nil)
+	} else if len(found) > 1 {
+		return waddrmgr.KeyScope{}, 0, er.Errorf("There are accounts named [%s] in "+
+			"scopes %v, the scope must be specified", name, found)
+	}
+	return found[0], account, nil
+}
+
+// ImportAccountWatchingOnly adds an account which is known only by its extended
+// public key, e.g. the account of a cold storage wallet.  The balance of the
+// account is tracked and unsigned transactions can be made from it but it
+// can never sign.  gapLimit addresses are derived on both the external and
+// internal branch and each time an address is used, more are derived so that
+// there are always gapLimit unused addresses past it.
+//
+// The account only knows about payments which are seen after it is imported,
+// to find earlier payments use ResyncChain.
+func (w *Wallet) ImportAccountWatchingOnly(scope waddrmgr.KeyScope, name string,
+	accountKey *hdkeychain.ExtendedKey, gapLimit uint32) (*waddrmgr.AccountProperties, er.R) {
+
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return nil, err
+	}
+	if gapLimit == 0 {
+		gapLimit = DefaultGapLimit
+	}
+
+	var props *waddrmgr.AccountProperties
+	var addrs []btcutil.Address
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		account, err := manager.NewAccountWatchingOnly(addrmgrNs, name, accountKey, gapLimit)
+		if err != nil {
+			return err
+		}
+		if err := manager.ExtendExternalAddresses(addrmgrNs, account, gapLimit-1); err != nil {
+			return err
+		}
+		if err := manager.ExtendInternalAddresses(addrmgrNs, account, gapLimit-1); err != nil {
+			return err
+		}
+		if err := manager.ForEachAccountAddress(addrmgrNs, account,
+			func(maddr waddrmgr.ManagedAddress) er.R {
+				addrs = append(addrs, maddr.Address())
+				return nil
+			},
+		); err != nil {
+			return err
+		}
+		props, err = manager.AccountProperties(addrmgrNs, account)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w.watch.WatchAddrs(addrs)
+	log.Infof("Imported watch-only account [%s] watching [%s] addresses",
+		name, log.Int(len(addrs)))
+	return props, nil
+}
+
+// extendGap is called when an address is paid, if the address belongs to a
+// watch-only or multisig account then addresses are derived so that there are
+// still the account's gap limit of unused addresses past it.  The new addresses
+// are returned so that they can be watched.
+func (w *Wallet) extendGap(addrmgrNs walletdb.ReadWriteBucket,
+	ma waddrmgr.ManagedAddress) ([]btcutil.Address, er.R) {
+
+	if _, ok := ma.(waddrmgr.ManagedScriptAddress); ok {
+		return w.extendMultisigGap(addrmgrNs, ma.Address())
+	}
+	mpka, ok := ma.(waddrmgr.ManagedPubKeyAddress)
+	if !ok || ma.Imported() {
+		return nil, nil
+	}
+	scope, path, ok := mpka.DerivationInfo()
+	if !ok {
+		return nil, nil
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return nil, err
+	}
+	props, err := manager.AccountProperties(addrmgrNs, path.Account)
+	if err != nil {
+		return nil, err
+	}
+	if !props.WatchOnly {
+		return nil, nil
+	}
+
+	internal := path.Branch == waddrmgr.InternalBranch
+	next := props.ExternalKeyCount
+	if internal {
+		next = props.InternalKeyCount
+	}
+	last := path.Index + props.GapLimit
+	if last < next {
+		return nil, nil
+	}
+	if internal {
+		err = manager.ExtendInternalAddresses(addrmgrNs, path.Account, last)
+	} else {
+		err = manager.ExtendExternalAddresses(addrmgrNs, path.Account, last)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]btcutil.Address, 0, last-next+1)
+	for i := next; i <= last; i++ {
+		maddr, err := manager.DeriveFromKeyPath(addrmgrNs, waddrmgr.DerivationPath{
+			Account: path.Account,
+			Branch:  path.Branch,
+			Index:   i,
+		})
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, maddr.Address())
+	}
+	w.gapExtended.Add(1)
+	log.Debugf("Address [%s] of watch-only account [%s] was used, watching [%s] more addresses",
+		log.Address(ma.Address().String()), props.AccountName, log.Int(len(addrs)))
+	return addrs, nil
+}
+
+// unsignableAddrCache is the result of unsignableAddrs.  The key changes
+// whenever a watch-only or multisig account is imported or has more addresses
+// derived, and the addrs map is never changed once it is stored so it can be
+// read without the lock.
+type unsignableAddrCache struct {
+	key   string
+	addrs map[string]struct{}
+}
+
+type watchOnlyAccount struct {
+	manager *waddrmgr.ScopedKeyManager
+	account uint32
+}
+
+// unsignableAddrs returns the addresses of every watch-only and multisig
+// account, these can not be spent from unless they are asked for by name.
+// Listing the addresses is slow so they are cached until the accounts change.
+func (w *Wallet) unsignableAddrs(addrmgrNs walletdb.ReadBucket) (map[string]struct{}, er.R) {
+	var key strings.Builder
+	var multisig []*waddrmgr.MultisigAccount
+	var watchOnly []watchOnlyAccount
+	if err := w.Manager.ForEachMultisigAccount(addrmgrNs, func(acct *waddrmgr.MultisigAccount) er.R {
+		multisig = append(multisig, acct)
+		fmt.Fprintf(&key, "m/%s/%s/%d/%d/%d;", acct.Name, multisigKeys(acct),
+			acct.NextExternalIndex, acct.NextInternalIndex, acct.GapLimit)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, manager := range w.Manager.ActiveScopedKeyManagers() {
+		scope := manager.Scope()
+		if err := manager.ForEachAccount(addrmgrNs, func(account uint32) er.R {
+			if account == waddrmgr.ImportedAddrAccount {
+				return nil
+			}
+			props, err := manager.AccountProperties(addrmgrNs, account)
+			if err != nil {
+				return err
+			} else if !props.WatchOnly {
+				return nil
+			}
+			watchOnly = append(watchOnly, watchOnlyAccount{manager: manager, account: account})
+			fmt.Fprintf(&key, "w/%s/%d/%d/%d/%d;", scope.String(), account,
+				props.ExternalKeyCount, props.InternalKeyCount, props.ImportedKeyCount)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	var out map[string]struct{}
+	err := w.unsignableCache.In(func(c *unsignableAddrCache) er.R {
+		if c.addrs != nil && c.key == key.String() {
+			out = c.addrs
+			return nil
+		}
+		addrs := make(map[string]struct{})
+		for _, acct := range multisig {
+			maddrs, err := w.multisigAddrs(acct)
+			if err != nil {
+				return err
+			}
+			for addr := range maddrs {
+				addrs[addr] = struct{}{}
+			}
+		}
+		for _, wo := range watchOnly {
+			if err := wo.manager.ForEachAccountAddress(addrmgrNs, wo.account,
+				func(maddr waddrmgr.ManagedAddress) er.R {
+					addrs[maddr.Address().String()] = struct{}{}
+					return nil
+				}); err != nil {
+				return err
+			}
+		}
+		*c = unsignableAddrCache{key: key.String(), addrs: addrs}
+		out = addrs
+		return nil
+	})
+	return out, err
+}
+
+// CreateWatchOnlyTx makes an unsigned transaction which spends from the
+// addresses of a watch-only account, change is paid to a new internal address
+// of the account.  The transaction must then be signed by the wallet which has
+// the private key of the account.
+func (w *Wallet) CreateWatchOnlyTx(scope waddrmgr.KeyScope, account uint32,
+	txr CreateTxReq) (*txauthor.AuthoredTx, er.R) {
+
+	manager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return nil, err
+	}
+	var change btcutil.Address
+	err = walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		props, err := manager.AccountProperties(addrmgrNs, account)
+		if err != nil {
+			return err
+		} else if !props.WatchOnly {
+			return er.Errorf("Account [%s] is not a watch-only account", props.AccountName)
+		}
+		txr.InputAddresses = nil
+		if err := manager.ForEachAccountAddress(addrmgrNs, account,
+			func(maddr waddrmgr.ManagedAddress) er.R {
+				txr.InputAddresses = append(txr.InputAddresses, maddr.Address())
+				return nil
+			},
+		); err != nil {
+			return err
+		}
+		if txr.ChangeAddress != nil {
+			return nil
+		}
+		addrs, err := manager.NextInternalAddresses(addrmgrNs, account, 1)
+		if err != nil {
+			return err
+		}
+		change = addrs[0].Address()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if change != nil {
+		w.watch.WatchAddr(change)
+		txr.ChangeAddress = &change
+	}
+	txr.SendMode = SendModeUnsigned
+	return w.CreateSimpleTx(txr)
+}
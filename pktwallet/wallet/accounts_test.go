@@ -0,0 +1,141 @@
+package wallet
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+)
+
+// TestImportAccountWatchingOnly imports an account key under the scope which
+// the wallet uses for its purpose and checks that it can be found by name.
+func TestImportAccountWatchingOnly(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	if _, err := w.ScopeForPurpose(1234); err == nil {
+		t.Fatalf("expected an unknown purpose to be an error")
+	}
+	scope, err := w.ScopeForPurpose(waddrmgr.KeyScopeBIP0084.Purpose)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scope != waddrmgr.KeyScopeBIP0084 {
+		t.Fatalf("expected scope %v, got %v", waddrmgr.KeyScopeBIP0084, scope)
+	}
+
+	seed, err := hdkeychain.GenerateSeed(hdkeychain.MinSeedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, err := hdkeychain.NewMaster(seed, w.chainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	props, err := w.ImportAccountWatchingOnly(scope, "cold", key, 5)
+	if err != nil {
+		t.Fatalf("unable to import account: %v", err)
+	}
+	if !props.WatchOnly || props.ExternalKeyCount != 5 {
+		t.Fatalf("expected a watch-only account with 5 addresses, got %+v", props)
+	}
+	gotScope, account, err := w.LookupAccount("cold", nil)
+	if err != nil {
+		t.Fatalf("unable to look up account: %v", err)
+	}
+	if gotScope != scope || account != props.AccountNumber {
+		t.Fatalf("expected account %d in %v, got %d in %v",
+			props.AccountNumber, scope, account, gotScope)
+	}
+}
+
+// TestUnsignableAddrsCache checks that the addresses of watch-only accounts are
+// cached, and that the cache is refreshed when an account is imported or has
+// more addresses derived.
+func TestUnsignableAddrsCache(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	unsignable := func() map[string]struct{} {
+		var out map[string]struct{}
+		if err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+			var err er.R
+			out, err = w.unsignableAddrs(tx.ReadBucket(waddrmgrNamespaceKey))
+			return err
+		}); err != nil {
+			t.Fatalf("unable to get unsignable addresses: %v", err)
+		}
+		return out
+	}
+	if len(unsignable()) != 0 {
+		t.Fatalf("expected no unsignable addresses before importing")
+	}
+
+	seed, err := hdkeychain.GenerateSeed(hdkeychain.MinSeedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, err := hdkeychain.NewMaster(seed, w.chainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	props, err := w.ImportAccountWatchingOnly(waddrmgr.KeyScopeBIP0084, "cold", key, 5)
+	if err != nil {
+		t.Fatalf("unable to import account: %v", err)
+	}
+	manager, err := w.Manager.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := unsignable()
+	if len(first) == 0 {
+		t.Fatalf("expected the imported account to be unsignable")
+	}
+	second := unsignable()
+	if reflect.ValueOf(first).Pointer() != reflect.ValueOf(second).Pointer() {
+		t.Fatalf("expected the cached addresses to be used")
+	}
+
+	// Paying the last address derives more, which must then be unsignable
+	var added []btcutil.Address
+	if err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		ns := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		ma, err := manager.DeriveFromKeyPath(ns, waddrmgr.DerivationPath{
+			Account: props.AccountNumber,
+			Branch:  waddrmgr.ExternalBranch,
+			Index:   props.ExternalKeyCount - 1,
+		})
+		if err != nil {
+			return err
+		}
+		added, err = w.extendGap(ns, ma)
+		return err
+	}); err != nil {
+		t.Fatalf("unable to extend the gap: %v", err)
+	}
+	if len(added) == 0 {
+		t.Fatalf("expected more addresses to be derived")
+	}
+	third := unsignable()
+	for _, addr := range added {
+		if _, ok := third[addr.String()]; !ok {
+			t.Fatalf("derived address %s is not unsignable", addr)
+		}
+	}
+	if _, ok := first[added[0].String()]; ok {
+		t.Fatalf("the cached addresses were changed")
+	}
+}
@@ -0,0 +1,184 @@
+package wallet
+
+import (
+	"encoding/binary"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+var (
+	// addrbookNamespaceKey is the top level bucket of the address book, it
+	// has one bucket of address labels keyed by the encoded address and one
+	// of outpoint labels keyed by txid and index.
+	addrbookNamespaceKey = []byte("addrbook")
+	addrbookAddressesKey = []byte("a")
+	addrbookOutpointsKey = []byte("o")
+)
+
+// AddressBookEntry is a label for an address, the address may be one of the
+// wallet's or a counterparty's.
+type AddressBookEntry struct {
+	Address string
+	Label   string
+
+	// Mine is true if the address belongs to the wallet.
+	Mine bool
+}
+
+// OutpointLabel is a label for an unspent output.
+type OutpointLabel struct {
+	OutPoint wire.OutPoint
+	Label    string
+}
+
+func outpointKey(op *wire.OutPoint) []byte {
+	k := make([]byte, chainhash.HashSize+4)
+	copy(k, op.Hash[:])
+	binary.BigEndian.PutUint32(k[chainhash.HashSize:], op.Index)
+	return k
+}
+
+func outpointFromKey(k []byte) (wire.OutPoint, er.R) {
+	var op wire.OutPoint
+	if len(k) != chainhash.HashSize+4 {
+		return op, er.New("malformed outpoint label key")
+	}
+	copy(op.Hash[:], k)
+	op.Index = binary.BigEndian.Uint32(k[chainhash.HashSize:])
+	return op, nil
+}
+
+func checkLabel(label string) er.R {
+	if len(label) > wtxmgr.TxLabelLimit {
+		return er.Errorf("label is [%d] bytes long, the limit is [%d]",
+			len(label), wtxmgr.TxLabelLimit)
+	}
+	return nil
+}
+
+// putLabel stores a label in one of the address book buckets, an empty label
+// deletes it.
+func putLabel(tx walletdb.ReadWriteTx, bucket, k []byte, label string) er.R {
+	ns, err := tx.CreateTopLevelBucket(addrbookNamespaceKey)
+	if err != nil {
+		return err
+	}
+	b, err := ns.CreateBucketIfNotExists(bucket)
+	if err != nil {
+		return err
+	}
+	if label == "" {
+		return b.Delete(k)
+	}
+	return b.Put(k, []byte(label))
+}
+
+func forEachLabel(tx walletdb.ReadTx, bucket []byte, fn func(k []byte, label string) er.R) er.R {
+	ns := tx.ReadBucket(addrbookNamespaceKey)
+	if ns == nil {
+		return nil
+	}
+	b := ns.NestedReadBucket(bucket)
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(k, v []byte) er.R {
+		return fn(k, string(v))
+	})
+}
+
+// SetAddressLabel labels an address in the address book, the address may be
+// one of the wallet's or any other address.  An empty label removes it.
+func (w *Wallet) SetAddressLabel(address, label string) er.R {
+	if err := checkLabel(label); err != nil {
+		return err
+	}
+	addr, err := btcutil.DecodeAddress(address, w.chainParams)
+	if err != nil {
+		return err
+	}
+	address = addr.EncodeAddress()
+	if err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		return putLabel(tx, addrbookAddressesKey, []byte(address), label)
+	}); err != nil {
+		return err
+	}
+	log.Debugf("Labeled address [%s] as [%s]", log.Address(address), label)
+	return nil
+}
+
+// SetOutpointLabel labels an output in the address book, unlike the name of
+// a locked outpoint the label is kept when the wallet restarts.  An empty
+// label removes it.
+func (w *Wallet) SetOutpointLabel(op wire.OutPoint, label string) er.R {
+	if err := checkLabel(label); err != nil {
+		return err
+	}
+	return walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		return putLabel(tx, addrbookOutpointsKey, outpointKey(&op), label)
+	})
+}
+
+// AddressLabels returns the label of every address in the address book, keyed
+// by the encoded address.
+func (w *Wallet) AddressLabels() (map[string]string, er.R) {
+	out := make(map[string]string)
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		return forEachLabel(tx, addrbookAddressesKey, func(k []byte, label string) er.R {
+			out[string(k)] = label
+			return nil
+		})
+	})
+	return out, err
+}
+
+// OutpointLabels returns the label of every output in the address book.
+func (w *Wallet) OutpointLabels() (map[wire.OutPoint]string, er.R) {
+	out := make(map[wire.OutPoint]string)
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		return forEachLabel(tx, addrbookOutpointsKey, func(k []byte, label string) er.R {
+			op, err := outpointFromKey(k)
+			if err != nil {
+				return err
+			}
+			out[op] = label
+			return nil
+		})
+	})
+	return out, err
+}
+
+// AddressBook returns every address and output label in the address book.
+func (w *Wallet) AddressBook() ([]AddressBookEntry, []OutpointLabel, er.R) {
+	var addrs []AddressBookEntry
+	var ops []OutpointLabel
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		if err := forEachLabel(tx, addrbookAddressesKey, func(k []byte, label string) er.R {
+			e := AddressBookEntry{Address: string(k), Label: label}
+			if addr, err := btcutil.DecodeAddress(e.Address, w.chainParams); err == nil {
+				_, err := w.Manager.Address(addrmgrNs, addr)
+				e.Mine = err == nil
+			}
+			addrs = append(addrs, e)
+			return nil
+		}); err != nil {
+			return err
+		}
+		return forEachLabel(tx, addrbookOutpointsKey, func(k []byte, label string) er.R {
+			op, err := outpointFromKey(k)
+			if err != nil {
+				return err
+			}
+			ops = append(ops, OutpointLabel{OutPoint: op, Label: label})
+			return nil
+		})
+	})
+	return addrs, ops, err
+}
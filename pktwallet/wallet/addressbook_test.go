@@ -0,0 +1,37 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/wire"
+)
+
+func TestOutpointKey(t *testing.T) {
+	op := wire.OutPoint{Index: 0x01020304}
+	op.Hash[0] = 0xaa
+	op.Hash[31] = 0xbb
+	k := outpointKey(&op)
+	if len(k) != 36 || k[32] != 1 || k[35] != 4 {
+		t.Fatalf("unexpected key %x", k)
+	}
+	op2, err := outpointFromKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op2 != op {
+		t.Fatalf("expected %v, got %v", op, op2)
+	}
+	if _, err := outpointFromKey(k[:35]); err == nil {
+		t.Fatal("expected a short key to be an error")
+	}
+}
+
+func TestCheckLabel(t *testing.T) {
+	if err := checkLabel("rent"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkLabel(strings.Repeat("x", 501)); err == nil {
+		t.Fatal("expected a label over the limit to be an error")
+	}
+}
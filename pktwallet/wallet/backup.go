@@ -0,0 +1,685 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/snacl"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/seedwords"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+var (
+	// backupMagic begins every backup file.
+	backupMagic = []byte("PKTWBKUP")
+
+	// backupNamespaceKey is the top level bucket which holds the outpoint
+	// locks of a restored backup until the wallet is next opened, locks are
+	// only kept in memory so they can not be restored directly.
+	backupNamespaceKey = []byte("backup")
+	backupLocksKey     = []byte("locks")
+)
+
+const (
+	backupVersion = 1
+
+	// backupParamsLen is the length of the marshalled scrypt parameters
+	// which follow the version, see snacl.SecretKey.Marshal.
+	backupParamsLen = snacl.KeySize + sha256.Size + 24
+)
+
+// WalletBackup is everything which is lost if a wallet is restored from its
+// seed alone.  It is stored as JSON in an encrypted backup file.
+type WalletBackup struct {
+	Version int    `json:"version"`
+	Network string `json:"network"`
+
+	// Seed is the seed words of the wallet, encrypted with the wallet
+	// passphrase as they are by /wallet/seed.
+	Seed string `json:"seed"`
+
+	Birthday      int64             `json:"birthday"`
+	BirthdayBlock BackupBlock       `json:"birthday_block"`
+	Accounts      []BackupAccount   `json:"accounts"`
+	Multisig      []BackupMultisig  `json:"multisig,omitempty"`
+	PrivateKeys   []BackupKey       `json:"private_keys,omitempty"`
+	Scripts       []BackupScript    `json:"scripts,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Locks         []BackupLock      `json:"locks,omitempty"`
+
+	// AddressBook is the address labels of the address book, keyed by
+	// address, and OutpointLabels are its output labels.
+	AddressBook    map[string]string `json:"address_book,omitempty"`
+	OutpointLabels []BackupLock      `json:"outpoint_labels,omitempty"`
+}
+
+// BackupLock is a locked or labeled outpoint in a backup.
+type BackupLock struct {
+	Txid string `json:"txid"`
+	Vout uint32 `json:"vout"`
+	Name string `json:"name,omitempty"`
+}
+
+// BackupBlock is a block stamp in a backup.
+type BackupBlock struct {
+	Height    int32  `json:"height"`
+	Hash      string `json:"hash"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// BackupAccount is an account of one of the wallet's key scopes.  Accounts
+// which derive from the seed are restored by number, watch-only accounts from
+// their extended public key.
+type BackupAccount struct {
+	Purpose          uint32 `json:"purpose"`
+	Coin             uint32 `json:"coin"`
+	Number           uint32 `json:"number"`
+	Name             string `json:"name"`
+	ExternalKeyCount uint32 `json:"external_key_count"`
+	InternalKeyCount uint32 `json:"internal_key_count"`
+	WatchOnlyKey     string `json:"watch_only_key,omitempty"`
+	GapLimit         uint32 `json:"gap_limit,omitempty"`
+	VoteFor          string `json:"vote_for,omitempty"`
+	VoteAgainst      string `json:"vote_against,omitempty"`
+}
+
+// BackupMultisig is a multisig account in a backup.
+type BackupMultisig struct {
+	Name              string   `json:"name"`
+	Threshold         uint32   `json:"threshold"`
+	Keys              []string `json:"keys"`
+	GapLimit          uint32   `json:"gap_limit"`
+	NextExternalIndex uint32   `json:"next_external_index"`
+	NextInternalIndex uint32   `json:"next_internal_index"`
+}
+
+// BackupKey is an imported private key in a backup.
+type BackupKey struct {
+	Purpose uint32 `json:"purpose"`
+	Coin    uint32 `json:"coin"`
+	WIF     string `json:"wif"`
+}
+
+// BackupScript is an imported P2SH or P2WSH script in a backup.
+type BackupScript struct {
+	Purpose uint32 `json:"purpose"`
+	Coin    uint32 `json:"coin"`
+	Script  string `json:"script"`
+	Witness bool   `json:"witness,omitempty"`
+}
+
+func encryptBackup(payload, passphrase []byte, opts *waddrmgr.ScryptOptions) ([]byte, er.R) {
+	sk, err := snacl.NewSecretKey(&passphrase, opts.N, opts.R, opts.P)
+	if err != nil {
+		return nil, err
+	}
+	defer sk.Zero()
+	ct, err := sk.Encrypt(payload)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.Write(backupMagic)
+	b.WriteByte(backupVersion)
+	b.Write(sk.Marshal())
+	b.Write(ct)
+	return b.Bytes(), nil
+}
+
+func decryptBackup(b, passphrase []byte) ([]byte, er.R) {
+	if !bytes.HasPrefix(b, backupMagic) {
+		return nil, er.New("not a wallet backup file")
+	}
+	b = b[len(backupMagic):]
+	if len(b) < 1+backupParamsLen {
+		return nil, er.New("wallet backup file is truncated")
+	} else if b[0] != backupVersion {
+		return nil, er.Errorf("unsupported wallet backup version [%d]", b[0])
+	}
+	var sk snacl.SecretKey
+	if err := sk.Unmarshal(b[1 : 1+backupParamsLen]); err != nil {
+		return nil, err
+	}
+	if err := sk.DeriveKey(&passphrase); err != nil {
+		if snacl.ErrInvalidPassword.Is(err) {
+			return nil, er.New("wrong passphrase for wallet backup")
+		}
+		return nil, err
+	}
+	defer sk.Zero()
+	return sk.Decrypt(b[1+backupParamsLen:])
+}
+
+// DecryptBackup decrypts and parses a backup file which was made by
+// Wallet.Backup.
+func DecryptBackup(b, passphrase []byte) (*WalletBackup, er.R) {
+	payload, err := decryptBackup(b, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var out WalletBackup
+	if err := er.E(jsoniter.Unmarshal(payload, &out)); err != nil {
+		return nil, err
+	}
+	if out.Version != backupVersion {
+		return nil, er.Errorf("unsupported wallet backup version [%d]", out.Version)
+	}
+	return &out, nil
+}
+
+// DecryptSeed decrypts the seed of a backup with the passphrase which the
+// wallet had when the backup was made.
+func (b *WalletBackup) DecryptSeed(passphrase []byte) (*seedwords.Seed, er.R) {
+	seedEnc, err := seedwords.SeedFromWords(b.Seed)
+	if err != nil {
+		return nil, err
+	}
+	return seedEnc.Decrypt(passphrase, false)
+}
+
+func sortedScopedManagers(m *waddrmgr.Manager) []*waddrmgr.ScopedKeyManager {
+	out := m.ActiveScopedKeyManagers()
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Scope(), out[j].Scope()
+		if a.Purpose != b.Purpose {
+			return a.Purpose < b.Purpose
+		}
+		return a.Coin < b.Coin
+	})
+	return out
+}
+
+// Backup makes an encrypted backup of the wallet, the passphrase must be the
+// wallet passphrase because the seed in the backup is encrypted with it.  The
+// wallet must be unlocked so that imported private keys and scripts can be
+// read.
+func (w *Wallet) Backup(passphrase []byte) ([]byte, er.R) {
+	return w.backup(passphrase, &waddrmgr.DefaultScryptOptions)
+}
+
+func (w *Wallet) backup(passphrase []byte, opts *waddrmgr.ScryptOptions) ([]byte, er.R) {
+	seedEnc := w.Manager.Seed()
+	if seedEnc == nil {
+		return nil, er.New("this wallet has no seed words, it is probably a legacy wallet")
+	}
+	if w.Manager.IsLocked() {
+		return nil, waddrmgr.ErrLocked.New("the wallet must be unlocked to make a backup", nil)
+	}
+	if seed, err := seedEnc.Decrypt(passphrase, false); err != nil {
+		return nil, er.New("the passphrase must be the wallet passphrase")
+	} else {
+		seed.Zero()
+	}
+	words, err := seedEnc.Words("english")
+	if err != nil {
+		return nil, err
+	}
+
+	b := WalletBackup{
+		Version:     backupVersion,
+		Network:     w.chainParams.Name,
+		Seed:        words,
+		Birthday:    w.Manager.Birthday().Unix(),
+		Labels:      make(map[string]string),
+		AddressBook: make(map[string]string),
+	}
+	err = walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+
+		if bs, _, err := w.Manager.BirthdayBlock(addrmgrNs); err == nil {
+			b.BirthdayBlock = BackupBlock{
+				Height:    bs.Height,
+				Hash:      bs.Hash.String(),
+				Timestamp: bs.Timestamp.Unix(),
+			}
+		} else if !waddrmgr.ErrBirthdayBlockNotSet.Is(err) {
+			return err
+		}
+
+		multisig := make(map[string]struct{})
+		if err := w.Manager.ForEachMultisigAccount(addrmgrNs, func(a *waddrmgr.MultisigAccount) er.R {
+			addrs, err := w.multisigAddrs(a)
+			if err != nil {
+				return err
+			}
+			for addr := range addrs {
+				multisig[addr] = struct{}{}
+			}
+			keys := make([]string, 0, len(a.Keys))
+			for _, k := range a.Keys {
+				keys = append(keys, k.String())
+			}
+			b.Multisig = append(b.Multisig, BackupMultisig{
+				Name:              a.Name,
+				Threshold:         a.Threshold,
+				Keys:              keys,
+				GapLimit:          a.GapLimit,
+				NextExternalIndex: a.NextExternalIndex,
+				NextInternalIndex: a.NextInternalIndex,
+			})
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, manager := range sortedScopedManagers(w.Manager) {
+			if err := w.backupScope(addrmgrNs, manager, multisig, &b); err != nil {
+				return err
+			}
+		}
+
+		if err := forEachLabel(tx, addrbookAddressesKey, func(k []byte, label string) er.R {
+			b.AddressBook[string(k)] = label
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := forEachLabel(tx, addrbookOutpointsKey, func(k []byte, label string) er.R {
+			op, err := outpointFromKey(k)
+			if err != nil {
+				return err
+			}
+			b.OutpointLabels = append(b.OutpointLabels, BackupLock{
+				Txid: op.Hash.String(),
+				Vout: op.Index,
+				Name: label,
+			})
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return w.TxStore.ForEachTxLabel(txmgrNs, func(txid chainhash.Hash, label string) er.R {
+			b.Labels[txid.String()] = label
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.lockedOutpointsMtx.Lock()
+	for op, name := range w.lockedOutpoints {
+		b.Locks = append(b.Locks, BackupLock{
+			Txid: op.Hash.String(),
+			Vout: op.Index,
+			Name: name,
+		})
+	}
+	w.lockedOutpointsMtx.Unlock()
+
+	payload, err := er.E1(jsoniter.Marshal(&b))
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Backed up [%s] accounts, [%s] multisig accounts, [%s] imported keys, "+
+		"[%s] scripts and [%s] labels", log.Int(len(b.Accounts)), log.Int(len(b.Multisig)),
+		log.Int(len(b.PrivateKeys)), log.Int(len(b.Scripts)), log.Int(len(b.Labels)))
+	return encryptBackup(payload, passphrase, opts)
+}
+
+// backupScope adds the accounts and imported keys and scripts of one key scope
+// to a backup, scripts of multisig accounts are skipped because they are
+// derived again when the multisig account is restored.
+func (w *Wallet) backupScope(addrmgrNs walletdb.ReadBucket, manager *waddrmgr.ScopedKeyManager,
+	multisig map[string]struct{}, b *WalletBackup) er.R {
+
+	scope := manager.Scope()
+	if err := manager.ForEachAccount(addrmgrNs, func(account uint32) er.R {
+		if account == waddrmgr.ImportedAddrAccount {
+			return nil
+		}
+		props, err := manager.AccountProperties(addrmgrNs, account)
+		if err != nil {
+			return err
+		}
+		ba := BackupAccount{
+			Purpose:          scope.Purpose,
+			Coin:             scope.Coin,
+			Number:           account,
+			Name:             props.AccountName,
+			ExternalKeyCount: props.ExternalKeyCount,
+			InternalKeyCount: props.InternalKeyCount,
+		}
+		if props.WatchOnly {
+			ba.WatchOnlyKey = props.AccountPubKey.String()
+			ba.GapLimit = props.GapLimit
+		}
+		vote, err := manager.NetworkStewardVote(addrmgrNs, account)
+		if err != nil {
+			return err
+		} else if vote != nil {
+			ba.VoteFor = hex.EncodeToString(vote.VoteFor)
+			ba.VoteAgainst = hex.EncodeToString(vote.VoteAgainst)
+		}
+		b.Accounts = append(b.Accounts, ba)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// The keys and scripts are read after ForEachAccountAddress returns
+	// because reading them takes the lock which it holds.
+	var imported []waddrmgr.ManagedAddress
+	if err := manager.ForEachAccountAddress(addrmgrNs, waddrmgr.ImportedAddrAccount,
+		func(ma waddrmgr.ManagedAddress) er.R {
+			imported = append(imported, ma)
+			return nil
+		}); err != nil {
+		return err
+	}
+	for _, ma := range imported {
+		switch a := ma.(type) {
+		case waddrmgr.ManagedPubKeyAddress:
+			priv, err := a.PrivKey()
+			if err != nil {
+				return err
+			}
+			wif, err := btcutil.NewWIF(priv, w.chainParams, a.Compressed())
+			if err != nil {
+				return err
+			}
+			b.PrivateKeys = append(b.PrivateKeys, BackupKey{
+				Purpose: scope.Purpose,
+				Coin:    scope.Coin,
+				WIF:     wif.String(),
+			})
+		case waddrmgr.ManagedScriptAddress:
+			if _, ok := multisig[a.Address().String()]; ok {
+				continue
+			}
+			script, err := a.Script()
+			if err != nil {
+				return err
+			}
+			b.Scripts = append(b.Scripts, BackupScript{
+				Purpose: scope.Purpose,
+				Coin:    scope.Coin,
+				Script:  hex.EncodeToString(script),
+				Witness: a.AddrType() == waddrmgr.WitnessScript,
+			})
+		}
+	}
+	return nil
+}
+
+// RestoreBackup restores the accounts, imported keys and scripts, labels and
+// settings of a backup into a wallet which was just created from the seed of
+// the backup.  The wallet must be unlocked.  The wallet will sync from the
+// birthday block of the backup.
+func (w *Wallet) RestoreBackup(b *WalletBackup) er.R {
+	if b.Network != w.chainParams.Name {
+		return er.Errorf("the backup is of a wallet on [%s], not [%s]",
+			b.Network, w.chainParams.Name)
+	}
+	if w.Manager.IsLocked() {
+		return waddrmgr.ErrLocked.New("the wallet must be unlocked to restore a backup", nil)
+	}
+	bs := w.Manager.SyncedTo()
+	if b.BirthdayBlock.Hash != "" {
+		hash, err := chainhash.NewHashFromStr(b.BirthdayBlock.Hash)
+		if err != nil {
+			return err
+		}
+		bs = waddrmgr.BlockStamp{
+			Height:    b.BirthdayBlock.Height,
+			Hash:      *hash,
+			Timestamp: time.Unix(b.BirthdayBlock.Timestamp, 0),
+		}
+	}
+
+	err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		txmgrNs := tx.ReadWriteBucket(wtxmgrNamespaceKey)
+
+		if err := w.Manager.SetBirthday(addrmgrNs, time.Unix(b.Birthday, 0)); err != nil {
+			return err
+		}
+		if b.BirthdayBlock.Hash != "" {
+			if err := w.Manager.SetSyncedTo(addrmgrNs, &bs); err != nil {
+				return err
+			}
+			if err := w.Manager.SetBirthdayBlock(addrmgrNs, bs, true); err != nil {
+				return err
+			}
+		}
+
+		for _, ba := range b.Accounts {
+			if err := w.restoreAccount(addrmgrNs, &ba); err != nil {
+				return err
+			}
+		}
+		for _, k := range b.PrivateKeys {
+			manager, err := w.Manager.FetchScopedKeyManager(waddrmgr.KeyScope{
+				Purpose: k.Purpose, Coin: k.Coin})
+			if err != nil {
+				return err
+			}
+			wif, err := btcutil.DecodeWIF(k.WIF)
+			if err != nil {
+				return err
+			}
+			if _, err := manager.ImportPrivateKey(addrmgrNs, wif, &bs); err != nil &&
+				!waddrmgr.ErrDuplicateAddress.Is(err) {
+				return err
+			}
+		}
+		for _, s := range b.Scripts {
+			if err := w.restoreScript(addrmgrNs, &s, &bs); err != nil {
+				return err
+			}
+		}
+		for _, bm := range b.Multisig {
+			if err := w.restoreMultisig(addrmgrNs, &bm); err != nil {
+				return err
+			}
+		}
+		for txid, label := range b.Labels {
+			hash, err := chainhash.NewHashFromStr(txid)
+			if err != nil {
+				return err
+			}
+			if err := w.TxStore.PutTxLabel(txmgrNs, *hash, label); err != nil {
+				return err
+			}
+		}
+		for addr, label := range b.AddressBook {
+			if err := putLabel(tx, addrbookAddressesKey, []byte(addr), label); err != nil {
+				return err
+			}
+		}
+		for _, l := range b.OutpointLabels {
+			hash, err := chainhash.NewHashFromStr(l.Txid)
+			if err != nil {
+				return err
+			}
+			op := wire.OutPoint{Hash: *hash, Index: l.Vout}
+			if err := putLabel(tx, addrbookOutpointsKey, outpointKey(&op), l.Name); err != nil {
+				return err
+			}
+		}
+		if len(b.Locks) == 0 {
+			return nil
+		}
+		locks, err := er.E1(jsoniter.Marshal(b.Locks))
+		if err != nil {
+			return err
+		}
+		ns, err := tx.CreateTopLevelBucket(backupNamespaceKey)
+		if err != nil {
+			return err
+		}
+		return ns.Put(backupLocksKey, locks)
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Restored [%s] accounts, [%s] multisig accounts, [%s] imported keys, "+
+		"[%s] scripts and [%s] labels, the wallet will sync from block [%s]",
+		log.Int(len(b.Accounts)), log.Int(len(b.Multisig)), log.Int(len(b.PrivateKeys)),
+		log.Int(len(b.Scripts)), log.Int(len(b.Labels)), log.Int(int(bs.Height)))
+	return nil
+}
+
+// restoreAccount makes an account of a backup again, accounts are numbered in
+// the order they are made so the backup lists them in order.
+func (w *Wallet) restoreAccount(addrmgrNs walletdb.ReadWriteBucket, ba *BackupAccount) er.R {
+	manager, err := w.Manager.FetchScopedKeyManager(waddrmgr.KeyScope{
+		Purpose: ba.Purpose, Coin: ba.Coin})
+	if err != nil {
+		return err
+	}
+	last, err := manager.LastAccount(addrmgrNs)
+	if err != nil {
+		return err
+	}
+	account := ba.Number
+	if ba.Number > last {
+		if ba.WatchOnlyKey != "" {
+			key, err := hdkeychain.NewKeyFromString(ba.WatchOnlyKey)
+			if err != nil {
+				return err
+			}
+			account, err = manager.NewAccountWatchingOnly(addrmgrNs, ba.Name, key, ba.GapLimit)
+			if err != nil {
+				return err
+			}
+		} else if account, err = manager.NewAccount(addrmgrNs, ba.Name); err != nil {
+			return err
+		}
+		if account != ba.Number {
+			return er.Errorf("account [%s] was restored as number [%d] rather than [%d]",
+				ba.Name, account, ba.Number)
+		}
+	} else if name, err := manager.AccountName(addrmgrNs, account); err != nil {
+		return err
+	} else if name != ba.Name {
+		if err := manager.RenameAccount(addrmgrNs, account, ba.Name); err != nil {
+			return err
+		}
+	}
+
+	// Derive every address which was given out, and the gap of a watch-only
+	// account, so that payments to them are found by the sync.
+	external, internal := ba.ExternalKeyCount, ba.InternalKeyCount
+	if ba.WatchOnlyKey != "" {
+		external += ba.GapLimit
+		internal += ba.GapLimit
+	}
+	if external > 0 {
+		if err := manager.ExtendExternalAddresses(addrmgrNs, account, external-1); err != nil {
+			return err
+		}
+	}
+	if internal > 0 {
+		if err := manager.ExtendInternalAddresses(addrmgrNs, account, internal-1); err != nil {
+			return err
+		}
+	}
+
+	if ba.VoteFor == "" && ba.VoteAgainst == "" {
+		return nil
+	}
+	var vote waddrmgr.NetworkStewardVote
+	if vote.VoteFor, err = er.E1(hex.DecodeString(ba.VoteFor)); err != nil {
+		return err
+	} else if vote.VoteAgainst, err = er.E1(hex.DecodeString(ba.VoteAgainst)); err != nil {
+		return err
+	}
+	return manager.PutNetworkStewardVote(addrmgrNs, account, &vote)
+}
+
+func (w *Wallet) restoreScript(addrmgrNs walletdb.ReadWriteBucket, s *BackupScript,
+	bs *waddrmgr.BlockStamp) er.R {
+
+	manager, err := w.Manager.FetchScopedKeyManager(waddrmgr.KeyScope{
+		Purpose: s.Purpose, Coin: s.Coin})
+	if err != nil {
+		return err
+	}
+	script, err := er.E1(hex.DecodeString(s.Script))
+	if err != nil {
+		return err
+	}
+	if s.Witness {
+		_, err = manager.ImportWitnessScript(addrmgrNs, script, bs)
+	} else {
+		_, err = manager.ImportScript(addrmgrNs, script, bs)
+	}
+	if err != nil && !waddrmgr.ErrDuplicateAddress.Is(err) {
+		return err
+	}
+	return nil
+}
+
+func (w *Wallet) restoreMultisig(addrmgrNs walletdb.ReadWriteBucket, bm *BackupMultisig) er.R {
+	keys := make([]*hdkeychain.ExtendedKey, 0, len(bm.Keys))
+	for _, k := range bm.Keys {
+		key, err := hdkeychain.NewKeyFromString(k)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	acct := &waddrmgr.MultisigAccount{
+		Name:              bm.Name,
+		Threshold:         bm.Threshold,
+		Keys:              keys,
+		GapLimit:          bm.GapLimit,
+		NextExternalIndex: bm.NextExternalIndex,
+		NextInternalIndex: bm.NextInternalIndex,
+	}
+	if err := w.Manager.NewMultisigAccount(addrmgrNs, acct); err != nil {
+		return err
+	}
+	for branch, next := range []uint32{acct.NextExternalIndex, acct.NextInternalIndex} {
+		if _, err := w.importMultisigAddrs(addrmgrNs, acct, uint32(branch), 0,
+			next+acct.GapLimit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRestoredLocks locks the outpoints which were locked in a backup which was
+// restored into this wallet, this is done once when the wallet is opened after
+// the restore.
+func (w *Wallet) loadRestoredLocks() er.R {
+	return walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		ns := tx.ReadWriteBucket(backupNamespaceKey)
+		if ns == nil {
+			return nil
+		}
+		v := ns.Get(backupLocksKey)
+		if v == nil {
+			return nil
+		}
+		var locks []BackupLock
+		if err := er.E(jsoniter.Unmarshal(v, &locks)); err != nil {
+			return err
+		}
+		w.lockedOutpointsMtx.Lock()
+		for _, l := range locks {
+			hash, err := chainhash.NewHashFromStr(l.Txid)
+			if err != nil {
+				log.Warnf("Skipping invalid locked outpoint [%s] from backup", l.Txid)
+				continue
+			}
+			w.lockedOutpoints[wire.OutPoint{Hash: *hash, Index: l.Vout}] = l.Name
+		}
+		w.lockedOutpointsMtx.Unlock()
+		log.Infof("Locked [%s] outpoints from the restored backup", log.Int(len(locks)))
+		return ns.Delete(backupLocksKey)
+	})
+}
@@ -0,0 +1,172 @@
+package wallet
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktwallet/chainiface"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/seedwords"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+func TestBackupEncryption(t *testing.T) {
+	payload := []byte(`{"version":1,"network":"pkt"}`)
+	pass := []byte("hello world")
+	b, err := encryptBackup(payload, pass, &waddrmgr.FastScryptOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, payload) {
+		t.Fatal("the backup is not encrypted")
+	}
+
+	out, err := decryptBackup(b, pass)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(out, payload) {
+		t.Fatalf("decrypted %q, expected %q", out, payload)
+	}
+
+	if _, err := decryptBackup(b, []byte("wrong")); err == nil {
+		t.Fatal("expected the wrong passphrase to be an error")
+	}
+	if _, err := decryptBackup(b[:20], pass); err == nil {
+		t.Fatal("expected a truncated backup to be an error")
+	}
+	b[len(backupMagic)] = backupVersion + 1
+	if _, err := decryptBackup(b, pass); err == nil {
+		t.Fatal("expected an unknown version to be an error")
+	}
+}
+
+// seedWallet creates an unlocked wallet from seed in a temporary directory.
+func seedWallet(t *testing.T, seed *seedwords.Seed, pass []byte) (*Wallet, func()) {
+	dir, errr := ioutil.TempDir("", "test_backup")
+	if errr != nil {
+		t.Fatalf("Failed to create db dir: %v", errr)
+	}
+	loader := NewLoader(&chaincfg.TestNet3Params, dir, "wallet.db", true, 250)
+	w, err := loader.CreateNewWallet([]byte("hello"), pass, nil, time.Now(), seed, nil)
+	if err != nil {
+		t.Fatalf("unable to create wallet: %v", err)
+	}
+	w.chainClient = &chainiface.Mock{}
+	if err := w.Unlock(pass, time.After(10*time.Minute)); err != nil {
+		t.Fatalf("unable to unlock wallet: %v", err)
+	}
+	return w, func() {
+		loader.UnloadWallet()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	pass := []byte("world")
+	seed, err := seedwords.RandomSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, cleanup := seedWallet(t, seed, pass)
+	defer cleanup()
+
+	bs := &waddrmgr.BlockStamp{
+		Hash:      *chaincfg.TestNet3Params.GenesisHash,
+		Timestamp: time.Now(),
+	}
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wif, err := btcutil.NewWIF(priv, w.ChainParams(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyAddr, err := w.ImportPrivateKey(waddrmgr.KeyScopeBIP0084, wif, bs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := txscript.MultiSigScript([]*btcutil.AddressPubKey{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptAddr, err := w.ImportP2SHRedeemScript(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := chainhash.Hash{0x01}
+	if err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		return w.TxStore.PutTxLabel(tx.ReadWriteBucket(wtxmgrNamespaceKey), txid, "rent")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetAddressLabel(keyAddr, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	op := wire.OutPoint{Hash: txid, Index: 2}
+	if err := w.SetOutpointLabel(op, "change"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := w.backup(pass, &waddrmgr.FastScryptOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bk, err := DecryptBackup(b, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed2, err := bk.DecryptSeed(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2, cleanup2 := seedWallet(t, seed2, pass)
+	defer cleanup2()
+	if err := w2.RestoreBackup(bk); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range []string{keyAddr, scriptAddr.EncodeAddress()} {
+		addr, err := btcutil.DecodeAddress(a, w2.ChainParams())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := w2.HaveAddress(addr); err != nil {
+			t.Fatal(err)
+		} else if !ok {
+			t.Fatalf("imported address [%s] was not restored", a)
+		}
+	}
+	if err := walletdb.View(w2.db, func(tx walletdb.ReadTx) er.R {
+		label, err := wtxmgr.FetchTxLabel(tx.ReadBucket(wtxmgrNamespaceKey), txid)
+		if err != nil {
+			return err
+		} else if label != "rent" {
+			t.Fatalf("transaction label is [%s], expected [rent]", label)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if labels, err := w2.AddressLabels(); err != nil {
+		t.Fatal(err)
+	} else if labels[keyAddr] != "alice" {
+		t.Fatalf("address label is [%s], expected [alice]", labels[keyAddr])
+	}
+	if labels, err := w2.OutpointLabels(); err != nil {
+		t.Fatal(err)
+	} else if labels[op] != "change" {
+		t.Fatalf("outpoint label is [%s], expected [change]", labels[op])
+	}
+}
@@ -0,0 +1,330 @@
+package wallet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/internal/txsizes"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txauthor"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/constants"
+)
+
+var (
+	// feeBumpNamespaceKey is the top level bucket which holds the fee bumps
+	// which the wallet has made, keyed by the txid of the child.
+	feeBumpNamespaceKey = []byte("feebump")
+)
+
+const (
+	feeBumpVersion = 1
+	feeBumpLen     = 74
+)
+
+// FeeBumpReq is a request to speed up an unconfirmed transaction by spending
+// one or more of its outputs in a child transaction which pays enough fee for
+// both (child pays for parent).
+type FeeBumpReq struct {
+	// Txid is the unconfirmed transaction which is stuck.
+	Txid chainhash.Hash
+
+	// FeeSatPerKB is the fee rate which the parent and child together should
+	// pay.
+	FeeSatPerKB btcutil.Amount
+
+	// Outputs are the indexes of the outputs of the parent to spend, if
+	// empty then every output of the parent which the wallet can spend is
+	// used.
+	Outputs []uint32
+
+	// ToAddress is where the child pays to, if nil then it is the address of
+	// the first output which is spent.
+	ToAddress btcutil.Address
+}
+
+// FeeBump is a child pays for parent transaction which the wallet has made.
+type FeeBump struct {
+	ParentTxid string
+	ChildTxid  string
+
+	// FeeSatPerKB is the fee rate which was requested for the package.
+	FeeSatPerKB btcutil.Amount
+
+	// ParentFee is the fee paid by the parent, if ParentFeeKnown is false
+	// then the parent spends coins which are not the wallet's so its fee is
+	// unknown and it is counted as zero.
+	ParentFee      btcutil.Amount
+	ParentFeeKnown bool
+	ParentVSize    int
+
+	ChildFee   btcutil.Amount
+	ChildVSize int
+
+	// PackageFeeRate is the fee rate of the parent and child together.
+	PackageFeeRate btcutil.Amount
+
+	Created time.Time
+}
+
+// Rpc converts the fee bump to its protobuf form.
+func (b *FeeBump) Rpc() *rpc_pb.FeeBump {
+	return &rpc_pb.FeeBump{
+		ParentTxid:       b.ParentTxid,
+		ChildTxid:        b.ChildTxid,
+		SfeePerKb:        int64(b.FeeSatPerKB),
+		SparentFee:       int64(b.ParentFee),
+		ParentFeeKnown:   b.ParentFeeKnown,
+		ParentVsize:      int32(b.ParentVSize),
+		SchildFee:        int64(b.ChildFee),
+		ChildVsize:       int32(b.ChildVSize),
+		SpackageFeePerKb: int64(b.PackageFeeRate),
+		Created:          b.Created.Unix(),
+	}
+}
+
+func serializeFeeBump(b *FeeBump) ([]byte, er.R) {
+	parent, err := chainhash.NewHashFromStr(b.ParentTxid)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, feeBumpLen)
+	buf[0] = feeBumpVersion
+	copy(buf[1:33], parent[:])
+	binary.BigEndian.PutUint64(buf[33:41], uint64(b.FeeSatPerKB))
+	binary.BigEndian.PutUint64(buf[41:49], uint64(b.ParentFee))
+	if b.ParentFeeKnown {
+		buf[49] = 1
+	}
+	binary.BigEndian.PutUint32(buf[50:54], uint32(b.ParentVSize))
+	binary.BigEndian.PutUint64(buf[54:62], uint64(b.ChildFee))
+	binary.BigEndian.PutUint32(buf[62:66], uint32(b.ChildVSize))
+	binary.BigEndian.PutUint64(buf[66:74], uint64(b.Created.Unix()))
+	return buf, nil
+}
+
+func deserializeFeeBump(child, v []byte) (*FeeBump, er.R) {
+	if len(v) != feeBumpLen || v[0] != feeBumpVersion || len(child) != chainhash.HashSize {
+		return nil, er.New("malformed fee bump")
+	}
+	var parent, childHash chainhash.Hash
+	copy(parent[:], v[1:33])
+	copy(childHash[:], child)
+	b := FeeBump{
+		ParentTxid:     parent.String(),
+		ChildTxid:      childHash.String(),
+		FeeSatPerKB:    btcutil.Amount(binary.BigEndian.Uint64(v[33:41])),
+		ParentFee:      btcutil.Amount(binary.BigEndian.Uint64(v[41:49])),
+		ParentFeeKnown: v[49] == 1,
+		ParentVSize:    int(binary.BigEndian.Uint32(v[50:54])),
+		ChildFee:       btcutil.Amount(binary.BigEndian.Uint64(v[54:62])),
+		ChildVSize:     int(binary.BigEndian.Uint32(v[62:66])),
+		Created:        time.Unix(int64(binary.BigEndian.Uint64(v[66:74])), 0),
+	}
+	b.PackageFeeRate = packageFeeRate(&b)
+	return &b, nil
+}
+
+func packageFeeRate(b *FeeBump) btcutil.Amount {
+	return (b.ParentFee + b.ChildFee) * 1000 / btcutil.Amount(b.ParentVSize+b.ChildVSize)
+}
+
+// cpfpFee is the fee which a child of childVSize must pay so that it and its
+// parent pay feePerKb together, it is never less than the relay fee of the
+// child alone.
+func cpfpFee(parentFee btcutil.Amount, parentVSize, childVSize int,
+	feePerKb btcutil.Amount) btcutil.Amount {
+
+	fee := txrules.FeeForSerializeSize(feePerKb, parentVSize+childVSize) - parentFee
+	if min := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, childVSize); fee < min {
+		return min
+	}
+	return fee
+}
+
+func txVSize(tx *wire.MsgTx) int {
+	weight := blockchain.GetTransactionWeight(btcutil.NewTx(tx))
+	return int((weight + blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor)
+}
+
+// BumpFee makes and broadcasts a transaction which spends outputs of the
+// unconfirmed transaction req.Txid so that the two together pay
+// req.FeeSatPerKB.  Only the outputs of the parent are spent so they must be
+// worth more than the fee.  The wallet must be unlocked.
+func (w *Wallet) BumpFee(req FeeBumpReq) (*FeeBump, er.R) {
+	if req.FeeSatPerKB < txrules.DefaultRelayFeePerKb {
+		return nil, er.Errorf("fee rate must be at least the relay fee of %d sat per kB",
+			int64(txrules.DefaultRelayFeePerKb))
+	}
+	if w.Manager.IsLocked() {
+		return nil, waddrmgr.ErrLocked.New("the wallet must be unlocked to bump a fee", nil)
+	}
+
+	bump := FeeBump{
+		ParentTxid:  req.Txid.String(),
+		FeeSatPerKB: req.FeeSatPerKB,
+		Created:     time.Now(),
+	}
+	var tx *wire.MsgTx
+	err := walletdb.View(w.db, func(dbtx walletdb.ReadTx) er.R {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+
+		details, err := w.TxStore.TxDetails(txmgrNs, &req.Txid)
+		if err != nil {
+			return err
+		} else if details == nil {
+			return er.Errorf("transaction [%s] is not known to the wallet", bump.ParentTxid)
+		} else if details.Block.Height >= 0 {
+			return er.Errorf("transaction [%s] is already confirmed", bump.ParentTxid)
+		}
+		parent := &details.MsgTx
+
+		if len(details.Debits) == len(parent.TxIn) {
+			for _, d := range details.Debits {
+				bump.ParentFee += d.Amount
+			}
+			for _, out := range parent.TxOut {
+				bump.ParentFee -= btcutil.Amount(out.Value)
+			}
+			bump.ParentFeeKnown = true
+		}
+		bump.ParentVSize = txVSize(parent)
+		if bump.ParentFeeKnown && bump.ParentFee >=
+			txrules.FeeForSerializeSize(req.FeeSatPerKB, bump.ParentVSize) {
+			return er.Errorf("transaction [%s] already pays [%s] which is more than the "+
+				"requested fee rate", bump.ParentTxid, bump.ParentFee)
+		}
+
+		unsignable, err := w.unsignableAddrs(addrmgrNs)
+		if err != nil {
+			return err
+		}
+		wanted := make(map[uint32]bool, len(req.Outputs))
+		for _, i := range req.Outputs {
+			if int(i) >= len(parent.TxOut) {
+				return er.Errorf("transaction [%s] has no output [%d]", bump.ParentTxid, i)
+			}
+			wanted[i] = true
+		}
+
+		tx = wire.NewMsgTx(constants.TxVersion)
+		var total btcutil.Amount
+		var counts inputCounts
+		var dest btcutil.Address
+		for _, c := range details.Credits {
+			if len(wanted) > 0 && !wanted[c.Index] {
+				continue
+			}
+			op := wire.OutPoint{Hash: req.Txid, Index: c.Index}
+			pkScript := parent.TxOut[c.Index].PkScript
+			addr := txscript.PkScriptToAddress(pkScript, w.chainParams)
+			if _, ok := unsignable[addr.String()]; ok {
+				log.Debugf("Skipping output [%s] of a watch-only or multisig address",
+					op.String())
+				continue
+			} else if c.Spent || w.LockedOutpoint(op) {
+				log.Debugf("Skipping output [%s] which is spent or locked", op.String())
+				continue
+			}
+			v := int64(c.Amount)
+			tx.AddTxIn(wire.NewTxIn(&op, nil, nil))
+			tx.Additional = append(tx.Additional, wire.TxInAdditional{
+				PkScript: pkScript,
+				Value:    &v,
+			})
+			counts.add(pkScript, 1)
+			total += c.Amount
+			if dest == nil {
+				dest = addr
+			}
+		}
+		if len(tx.TxIn) == 0 {
+			return er.Errorf("transaction [%s] has no unspent outputs which the wallet "+
+				"can spend", bump.ParentTxid)
+		}
+		if req.ToAddress != nil {
+			dest = req.ToAddress
+		}
+		pkScript, err := txscript.PayToAddrScript(dest)
+		if err != nil {
+			return err
+		}
+		out := wire.NewTxOut(0, pkScript)
+		bump.ChildVSize = txsizes.EstimateVirtualSize(
+			counts.p2pkh, counts.p2wpkh, counts.nested, []*wire.TxOut{out}, false)
+		bump.ChildFee = cpfpFee(bump.ParentFee, bump.ParentVSize, bump.ChildVSize, req.FeeSatPerKB)
+		out.Value = int64(total - bump.ChildFee)
+		if out.Value <= 0 || txrules.IsDustOutput(out, txrules.DefaultRelayFeePerKb) {
+			return InsufficientFundsError.New(
+				fmt.Sprintf("the outputs of transaction [%s] are worth [%s] which is "+
+					"not enough to pay a fee of [%s]", bump.ParentTxid, total, bump.ChildFee), nil)
+		}
+		tx.AddTxOut(out)
+
+		if err := txauthor.AddAllInputScripts(tx, secretSource{w.Manager, addrmgrNs}); err != nil {
+			return err
+		}
+		return validateMsgTx1(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bump.PackageFeeRate = packageFeeRate(&bump)
+	txid, err := w.ReliablyPublishTransaction(tx, "cpfp "+bump.ParentTxid)
+	if err != nil {
+		return nil, err
+	}
+	bump.ChildTxid = txid.String()
+	v, err := serializeFeeBump(&bump)
+	if err != nil {
+		return nil, err
+	}
+	if err := walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) er.R {
+		ns, err := dbtx.CreateTopLevelBucket(feeBumpNamespaceKey)
+		if err != nil {
+			return err
+		}
+		return ns.Put(txid[:], v)
+	}); err != nil {
+		return nil, err
+	}
+	log.Infof("Bumped fee of [%s] with child [%s] paying [%s], package fee rate [%s] per kB",
+		log.Txid(bump.ParentTxid), log.Txid(bump.ChildTxid), bump.ChildFee, bump.PackageFeeRate)
+	return &bump, nil
+}
+
+// feeBumps returns every fee bump which the wallet has made, keyed by the txid
+// of the child and also by the txid of the parent so that the bump can be
+// found from either transaction. If a parent was bumped more than once then
+// it maps to the most recent bump.
+func feeBumps(dbtx walletdb.ReadTx) (map[string]*FeeBump, er.R) {
+	out := make(map[string]*FeeBump)
+	ns := dbtx.ReadBucket(feeBumpNamespaceKey)
+	if ns == nil {
+		return out, nil
+	}
+	err := ns.ForEach(func(k, v []byte) er.R {
+		b, err := deserializeFeeBump(k, v)
+		if err != nil {
+			return err
+		}
+		out[b.ChildTxid] = b
+		if prev := out[b.ParentTxid]; prev == nil || prev.Created.Before(b.Created) {
+			out[b.ParentTxid] = b
+		}
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,136 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+func TestCpfpFee(t *testing.T) {
+	// The parent paid nothing so the child pays for both at 10x the relay fee.
+	rate := txrules.DefaultRelayFeePerKb * 10
+	fee := cpfpFee(0, 200, 110, rate)
+	if fee != txrules.FeeForSerializeSize(rate, 310) {
+		t.Fatalf("unexpected child fee %s", fee)
+	}
+
+	// What the parent paid is taken off.
+	parentFee := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, 200)
+	if got := cpfpFee(parentFee, 200, 110, rate); got != fee-parentFee {
+		t.Fatalf("expected %s, got %s", fee-parentFee, got)
+	}
+
+	// The child never pays less than its own relay fee.
+	min := txrules.FeeForSerializeSize(txrules.DefaultRelayFeePerKb, 110)
+	if got := cpfpFee(btcutil.Amount(1e8), 200, 110, rate); got != min {
+		t.Fatalf("expected the relay fee %s, got %s", min, got)
+	}
+}
+
+func TestBumpFee(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	addr, err := w.CurrentAddress(0, waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatalf("unable to get current address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addUtxo(t, w, &wire.MsgTx{
+		TxIn:  []*wire.TxIn{{}},
+		TxOut: []*wire.TxOut{wire.NewTxOut(1000000, pkScript)},
+	})
+
+	// The parent only pays the relay fee and has change.
+	parent, err := w.CreateSimpleTx(CreateTxReq{
+		Outputs:     []*wire.TxOut{wire.NewTxOut(100000, testScriptP2WKH)},
+		FeeSatPerKB: txrules.DefaultRelayFeePerKb,
+		SendMode:    SendModeSigned,
+	})
+	if err != nil {
+		t.Fatalf("unable to make parent: %v", err)
+	}
+	if _, err := w.ReliablyPublishTransaction(parent.Tx, ""); err != nil {
+		t.Fatalf("unable to publish parent: %v", err)
+	}
+	parentFee := btcutil.Amount(1000000)
+	for _, out := range parent.Tx.TxOut {
+		parentFee -= btcutil.Amount(out.Value)
+	}
+
+	rate := txrules.DefaultRelayFeePerKb * 20
+	bump, err := w.BumpFee(FeeBumpReq{Txid: parent.Tx.TxHash(), FeeSatPerKB: rate})
+	if err != nil {
+		t.Fatalf("unable to bump fee: %v", err)
+	}
+	if !bump.ParentFeeKnown || bump.ParentFee != parentFee {
+		t.Fatalf("expected parent fee %s, got %s", parentFee, bump.ParentFee)
+	}
+	if bump.PackageFeeRate < rate {
+		t.Fatalf("package fee rate %s is less than %s", bump.PackageFeeRate, rate)
+	}
+
+	// The child which was made pays what was reported, and the package
+	// pays the fee rate with the real size of the child.
+	childHash, err := chainhash.NewHashFromStr(bump.ChildTxid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var child *wtxmgr.TxDetails
+	if err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		var err er.R
+		child, err = w.TxStore.TxDetails(tx.ReadBucket(wtxmgrNamespaceKey), childHash)
+		return err
+	}); err != nil || child == nil {
+		t.Fatalf("child is not in the wallet: %v", err)
+	}
+	if len(child.MsgTx.TxIn) != 1 || child.MsgTx.TxIn[0].PreviousOutPoint.Hash != parent.Tx.TxHash() {
+		t.Fatalf("child does not spend the parent")
+	}
+	childFee := btcutil.Amount(parent.Tx.TxOut[parent.ChangeIndex].Value - child.MsgTx.TxOut[0].Value)
+	if childFee != bump.ChildFee {
+		t.Fatalf("child pays %s but %s was reported", childFee, bump.ChildFee)
+	}
+	childVSize := txVSize(&child.MsgTx)
+	if childVSize > bump.ChildVSize {
+		t.Fatalf("child is %d vbytes, more than the estimate of %d", childVSize, bump.ChildVSize)
+	}
+	if got := (parentFee + childFee) * 1000 / btcutil.Amount(bump.ParentVSize+childVSize); got < rate {
+		t.Fatalf("package fee rate %s is less than %s", got, rate)
+	}
+
+	// The bump is stored and reported along with both the child and the
+	// parent.
+	txns, err := w.GetTransactions1(&rpc_pb.GetTransactionsRequest{EndHeight: -1})
+	if err != nil {
+		t.Fatalf("unable to get transactions: %v", err)
+	}
+	found := map[string]bool{}
+	for _, tx := range txns.Transactions {
+		if tx.Tx.Txid == bump.ChildTxid || tx.Tx.Txid == bump.ParentTxid {
+			found[tx.Tx.Txid] = tx.FeeBump != nil && tx.FeeBump.ParentTxid == bump.ParentTxid &&
+				tx.FeeBump.ChildTxid == bump.ChildTxid &&
+				tx.FeeBump.SpackageFeePerKb == int64(bump.PackageFeeRate)
+		} else if tx.FeeBump != nil {
+			t.Fatalf("transaction %s is not part of a fee bump", tx.Tx.Txid)
+		}
+	}
+	if !found[bump.ChildTxid] {
+		t.Fatalf("the fee bump is not reported with the child")
+	}
+	if !found[bump.ParentTxid] {
+		t.Fatalf("the fee bump is not reported with the parent")
+	}
+}
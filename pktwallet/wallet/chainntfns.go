@@ -85,6 +85,7 @@ func (w *Wallet) _rollbackBlock(dbtx walletdb.ReadWriteTx, bs waddrmgr.BlockStam
 		return err
 	}
 
+	w.NtfnServer.notifyRollback(dbtx, bs.Height)
 	err = w.TxStore.RollbackOne(txmgrNs, bs.Height)
 	if err != nil && !wtxmgr.ErrNoExists.Is(err) {
 		return err
@@ -159,6 +160,11 @@ func (w *Wallet) addRelevantTx(dbtx walletdb.ReadWriteTx, rec *wtxmgr.TxRecord,
 				if err != nil {
 					return err
 				}
+				if gapAddrs, err := w.extendGap(addrmgrNs, ma); err != nil {
+					return err
+				} else if len(gapAddrs) > 0 {
+					w.watch.WatchAddrs(gapAddrs)
+				}
 				txOutAmt := btcutil.Amount(rec.MsgTx.TxOut[i].Value)
 				if !isNew {
 					// don't log when we see the same money again
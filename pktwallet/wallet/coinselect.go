@@ -0,0 +1,469 @@
+package wallet
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/enough"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/internal/txsizes"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// CoinSelection is a strategy for choosing which unspent outputs are spent by
+// a transaction.
+type CoinSelection string
+
+const (
+	// CoinSelectionDefault spends from the address which can pay with the
+	// fewest inputs, preferring the biggest coins, and only merges the coins
+	// of several addresses if no one address has enough.
+	CoinSelectionDefault CoinSelection = ""
+
+	// CoinSelectionBranchAndBound searches for a set of coins which pays
+	// exactly, so that no change output is needed.  If there is no such set
+	// then it falls back to CoinSelectionLargestFirst.
+	CoinSelectionBranchAndBound CoinSelection = "branchandbound"
+
+	// CoinSelectionSmallestFirst spends the smallest coins first, so that
+	// dust is consolidated as the wallet is used.
+	CoinSelectionSmallestFirst CoinSelection = "smallestfirst"
+
+	// CoinSelectionLargestFirst spends the largest coins first, which gives
+	// the fewest inputs and the lowest fee.
+	CoinSelectionLargestFirst CoinSelection = "largestfirst"
+
+	// CoinSelectionPrivacy never merges the coins of different addresses in
+	// one transaction, so that the transaction does not reveal that they
+	// belong to the same wallet.
+	CoinSelectionPrivacy CoinSelection = "privacy"
+)
+
+// CoinSelections are all of the coin selection strategies, except the default.
+var CoinSelections = []CoinSelection{
+	CoinSelectionBranchAndBound,
+	CoinSelectionSmallestFirst,
+	CoinSelectionLargestFirst,
+	CoinSelectionPrivacy,
+}
+
+// ParseCoinSelection parses the name of a coin selection strategy, the empty
+// string is the default strategy.
+func ParseCoinSelection(name string) (CoinSelection, er.R) {
+	if name == "" {
+		return CoinSelectionDefault, nil
+	}
+	for _, cs := range CoinSelections {
+		if string(cs) == strings.ToLower(name) {
+			return cs, nil
+		}
+	}
+	names := make([]string, 0, len(CoinSelections))
+	for _, cs := range CoinSelections {
+		names = append(names, string(cs))
+	}
+	return "", er.Errorf("Unknown coin selection [%s], expecting one of [%s]",
+		name, strings.Join(names, ", "))
+}
+
+func coinSelectionName(cs CoinSelection) string {
+	if cs == CoinSelectionDefault {
+		return "default"
+	}
+	return string(cs)
+}
+
+// bnbMaxTries is the number of steps after which the branch and bound search
+// gives up, this is the same limit which bitcoind uses.
+const bnbMaxTries = 100000
+
+// inputCounts counts the inputs of each kind which the fee of a transaction is
+// estimated from, they are classified in the same way as by
+// txauthor.NewUnsignedTransaction.
+type inputCounts struct {
+	p2pkh, p2wpkh, nested int
+}
+
+func (c *inputCounts) add(pkScript []byte, n int) {
+	switch {
+	case txscript.IsPayToScriptHash(pkScript):
+		c.nested += n
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		c.p2wpkh += n
+	default:
+		c.p2pkh += n
+	}
+}
+
+func (c *inputCounts) count() int {
+	return c.p2pkh + c.p2wpkh + c.nested
+}
+
+// fee is the fee which txauthor.NewUnsignedTransaction will pay for a
+// transaction with these inputs.
+func (c *inputCounts) fee(outputs []*wire.TxOut, feePerKb btcutil.Amount) btcutil.Amount {
+	size := txsizes.EstimateVirtualSize(c.p2pkh, c.p2wpkh, c.nested, outputs, true)
+	return txrules.FeeForSerializeSize(feePerKb, size)
+}
+
+// coinSelector orders eligible coins for a strategy.
+type coinSelector struct {
+	outputs  []*wire.TxOut
+	feePerKb btcutil.Amount
+	needed   btcutil.Amount
+	sweeping bool
+	limit    int
+}
+
+func newCoinSelector(outputs []*wire.TxOut, feePerKb btcutil.Amount,
+	maxInputs int, credits []*dbstructs.Unspent) *coinSelector {
+
+	cs := &coinSelector{
+		outputs:  outputs,
+		feePerKb: feePerKb,
+		sweeping: enough.GetSweepOutput(outputs) != nil,
+		limit:    maxInputs,
+	}
+	for _, o := range outputs {
+		if o.Value > 0 {
+			cs.needed += btcutil.Amount(o.Value)
+		}
+	}
+	if cs.limit <= 0 {
+		cs.limit = MaxInputsPerTx
+		for _, c := range credits {
+			if !txscript.IsWitnessProgram(c.PkScript) {
+				cs.limit = MaxInputsPerTxLegacy
+				break
+			}
+		}
+	}
+	return cs
+}
+
+// isEnough is true if the coins can pay for the outputs and the fee.
+func (cs *coinSelector) isEnough(credits []*dbstructs.Unspent) bool {
+	if cs.sweeping {
+		return false
+	}
+	var counts inputCounts
+	var total btcutil.Amount
+	for _, c := range credits {
+		counts.add(c.PkScript, 1)
+		total += btcutil.Amount(c.Value)
+	}
+	return total >= cs.needed+counts.fee(cs.outputs, cs.feePerKb)
+}
+
+func (cs *coinSelector) truncate(credits []*dbstructs.Unspent) []*dbstructs.Unspent {
+	if len(credits) > cs.limit {
+		return credits[:cs.limit]
+	}
+	return credits
+}
+
+func sortLargestFirst(credits []*dbstructs.Unspent) {
+	sort.Slice(credits, func(i, j int) bool {
+		return PreferBiggest(credits[i], credits[j]) < 0
+	})
+}
+
+func sortSmallestFirst(credits []*dbstructs.Unspent) {
+	sort.Slice(credits, func(i, j int) bool {
+		return PreferBiggest(credits[i], credits[j]) > 0
+	})
+}
+
+// largestFirst spends the biggest coins first.
+func (cs *coinSelector) largestFirst(credits []*dbstructs.Unspent) []*dbstructs.Unspent {
+	sortLargestFirst(credits)
+	return cs.truncate(credits)
+}
+
+// smallestFirst spends the smallest coins first.  If the smallest coins which
+// fit in one transaction are not enough then the biggest of them are replaced
+// with the biggest coins of the wallet, one by one, until they are.
+func (cs *coinSelector) smallestFirst(credits []*dbstructs.Unspent) []*dbstructs.Unspent {
+	sortSmallestFirst(credits)
+	if len(credits) <= cs.limit || cs.sweeping {
+		return cs.truncate(credits)
+	}
+	for big := 0; big <= cs.limit; big++ {
+		sel := make([]*dbstructs.Unspent, 0, cs.limit)
+		sel = append(sel, credits[:cs.limit-big]...)
+		sel = append(sel, credits[len(credits)-big:]...)
+		if cs.isEnough(sel) {
+			return sel
+		}
+	}
+	return cs.largestFirst(credits)
+}
+
+// branchAndBound searches for a set of coins which pays for the outputs and
+// the fee with less than a dust amount left over, so that no change output is
+// made.  It returns nil if there is no such set.
+func (cs *coinSelector) branchAndBound(credits []*dbstructs.Unspent) []*dbstructs.Unspent {
+	if cs.sweeping {
+		return nil
+	}
+
+	// The effective value of a coin is its value less the fee to spend it.
+	var none inputCounts
+	baseFee := none.fee(cs.outputs, cs.feePerKb)
+	type coin struct {
+		uns       *dbstructs.Unspent
+		effective btcutil.Amount
+	}
+	coins := make([]coin, 0, len(credits))
+	var lookahead btcutil.Amount
+	for _, c := range credits {
+		var one inputCounts
+		one.add(c.PkScript, 1)
+		eff := btcutil.Amount(c.Value) - (one.fee(cs.outputs, cs.feePerKb) - baseFee)
+		if eff <= 0 {
+			continue
+		}
+		coins = append(coins, coin{uns: c, effective: eff})
+		lookahead += eff
+	}
+	sort.Slice(coins, func(i, j int) bool {
+		return coins[i].effective > coins[j].effective
+	})
+
+	dust := txrules.GetDustThreshold(txsizes.P2WPKHPkScriptSize, txrules.DefaultRelayFeePerKb)
+	target := cs.needed + baseFee
+	upper := target + dust
+
+	// exact checks a candidate against the fee which will really be paid,
+	// the sum of the fees of each input may be rounded differently.
+	exact := func(selected []bool) []*dbstructs.Unspent {
+		var sel []*dbstructs.Unspent
+		var counts inputCounts
+		var total btcutil.Amount
+		for i, s := range selected {
+			if s {
+				sel = append(sel, coins[i].uns)
+				counts.add(coins[i].uns.PkScript, 1)
+				total += btcutil.Amount(coins[i].uns.Value)
+			}
+		}
+		left := total - cs.needed - counts.fee(cs.outputs, cs.feePerKb)
+		if left < 0 || !txrules.IsDustAmount(left, txsizes.P2WPKHPkScriptSize,
+			txrules.DefaultRelayFeePerKb) {
+			return nil
+		}
+		return sel
+	}
+
+	// Depth first search which includes each coin before excluding it,
+	// lookahead is the value of the coins which are not yet decided.
+	selected := make([]bool, len(coins))
+	var value btcutil.Amount
+	count := 0
+	depth := 0
+	for tries := 0; tries < bnbMaxTries; tries++ {
+		backtrack := false
+		if value+lookahead < target || value >= upper {
+			backtrack = true
+		} else if value >= target {
+			if sel := exact(selected[:depth]); sel != nil {
+				return sel
+			}
+			backtrack = true
+		} else if depth == len(coins) || count == cs.limit {
+			backtrack = true
+		}
+
+		if !backtrack {
+			selected[depth] = true
+			value += coins[depth].effective
+			lookahead -= coins[depth].effective
+			count++
+			depth++
+			continue
+		}
+
+		// Go back to the last coin which was included and exclude it.
+		for depth > 0 && !selected[depth-1] {
+			depth--
+			lookahead += coins[depth].effective
+		}
+		if depth == 0 {
+			break
+		}
+		selected[depth-1] = false
+		value -= coins[depth-1].effective
+		count--
+	}
+	return nil
+}
+
+// privacy spends the coins of only one address, the address which can pay
+// with the least left over as change.  When sweeping, the address with the
+// most value is swept.
+func (cs *coinSelector) privacy(credits []*dbstructs.Unspent) ([]*dbstructs.Unspent, er.R) {
+	byAddr := make(map[string][]*dbstructs.Unspent)
+	for _, c := range credits {
+		byAddr[c.Address] = append(byAddr[c.Address], c)
+	}
+	var best []*dbstructs.Unspent
+	var bestTotal btcutil.Amount
+	for _, coins := range byAddr {
+		sortLargestFirst(coins)
+		coins = cs.truncate(coins)
+		var total btcutil.Amount
+		if cs.sweeping {
+			for _, c := range coins {
+				total += btcutil.Amount(c.Value)
+			}
+			if best == nil || total > bestTotal {
+				best, bestTotal = coins, total
+			}
+			continue
+		}
+		for i, c := range coins {
+			total += btcutil.Amount(c.Value)
+			if !cs.isEnough(coins[:i+1]) {
+				continue
+			}
+			if best == nil || total < bestTotal {
+				best, bestTotal = coins[:i+1], total
+			}
+			break
+		}
+	}
+	if best == nil && len(credits) > 0 {
+		return nil, InsufficientFundsError.New("no single address has enough balance "+
+			"and the privacy coin selection never spends from more than one address", nil)
+	}
+	return best, nil
+}
+
+// selectCoins orders the eligible coins for a coin selection strategy, the
+// transaction will spend them in this order until it has enough.
+func (cs *coinSelector) selectCoins(strategy CoinSelection,
+	credits []*dbstructs.Unspent) ([]*dbstructs.Unspent, er.R) {
+
+	switch strategy {
+	case CoinSelectionBranchAndBound:
+		if sel := cs.branchAndBound(credits); sel != nil {
+			return sel, nil
+		}
+		log.Debugf("No changeless set of coins found, falling back to largest first")
+		return cs.largestFirst(credits), nil
+	case CoinSelectionSmallestFirst:
+		return cs.smallestFirst(credits), nil
+	case CoinSelectionLargestFirst:
+		return cs.largestFirst(credits), nil
+	case CoinSelectionPrivacy:
+		return cs.privacy(credits)
+	}
+	return nil, er.Errorf("Unknown coin selection [%s]", string(strategy))
+}
+
+// findCoinsForStrategy collects every output which may be spent and orders them
+// for the coin selection strategy of the request.
+func (w *Wallet) findCoinsForStrategy(
+	dbtx walletdb.ReadWriteTx,
+	txr *CreateTxReq,
+	bs *waddrmgr.BlockStamp,
+) (eligibleOutputs, int, er.R) {
+	out := eligibleOutputs{}
+	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+
+	addrStrs := make(map[string]struct{})
+	for _, a := range txr.InputAddresses {
+		addrStrs[a.String()] = struct{}{}
+	}
+	filter, err := w.newOutputFilter(dbtx, addrStrs, txr.Minconf, bs, txr.InputMinHeight, &out)
+	if err != nil {
+		return out, 0, err
+	}
+
+	var credits []*dbstructs.Unspent
+	visits, err := w.TxStore.ForEachUnspentOutput(txmgrNs, nil, addrStrs,
+		func(_ []byte, uns *dbstructs.Unspent) er.R {
+			if filter.eligible(uns) {
+				credits = append(credits, uns)
+			}
+			return nil
+		})
+	if err != nil {
+		return out, visits, err
+	}
+	if err := filter.deleteBurned(dbtx); err != nil {
+		return out, visits, err
+	}
+
+	cs := newCoinSelector(txr.Outputs, txr.FeeSatPerKB, txr.MaxInputs, credits)
+	out.credits, err = cs.selectCoins(txr.CoinSelection, credits)
+	if err != nil {
+		return out, visits, err
+	}
+	selected := make(map[wire.OutPoint]struct{}, len(out.credits))
+	for _, c := range out.credits {
+		selected[c.OutPoint] = struct{}{}
+	}
+	for _, c := range credits {
+		if _, ok := selected[c.OutPoint]; !ok {
+			out.unusedCount++
+			out.unusedAmt += btcutil.Amount(c.Value)
+		}
+	}
+	return out, visits, nil
+}
+
+// SimulatedTx is what a transaction would spend if it were made.
+type SimulatedTx struct {
+	CoinSelection CoinSelection
+	InputCount    int
+	InputTotal    btcutil.Amount
+	AddressCount  int
+	Fee           btcutil.Amount
+	Change        btcutil.Amount
+	VSize         int
+}
+
+// SimulateTx selects the coins for a transaction without making it, and returns
+// the fee and change which it would have.  The database is not changed and no
+// coins are locked.
+func (w *Wallet) SimulateTx(txr CreateTxReq) (*SimulatedTx, er.R) {
+	txr.SendMode = SendModeSimulate
+	tx, err := w.CreateSimpleTx(txr)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts inputCounts
+	addrs := make(map[string]struct{})
+	for _, add := range tx.Tx.Additional {
+		counts.add(add.PkScript, 1)
+		addrs[string(add.PkScript)] = struct{}{}
+	}
+	sim := &SimulatedTx{
+		CoinSelection: txr.CoinSelection,
+		InputCount:    counts.count(),
+		InputTotal:    tx.TotalInput,
+		AddressCount:  len(addrs),
+		Fee:           tx.TotalInput,
+	}
+	outputs := make([]*wire.TxOut, 0, len(tx.Tx.TxOut))
+	for i, o := range tx.Tx.TxOut {
+		sim.Fee -= btcutil.Amount(o.Value)
+		if i == tx.ChangeIndex {
+			sim.Change = btcutil.Amount(o.Value)
+		} else {
+			outputs = append(outputs, o)
+		}
+	}
+	sim.VSize = txsizes.EstimateVirtualSize(counts.p2pkh, counts.p2wpkh, counts.nested,
+		outputs, tx.ChangeIndex >= 0)
+	return sim, nil
+}
@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+func testCoins(addr string, values ...int64) []*dbstructs.Unspent {
+	out := make([]*dbstructs.Unspent, 0, len(values))
+	for i, v := range values {
+		pkScript := make([]byte, 22)
+		pkScript[1] = 20
+		copy(pkScript[2:], addr)
+		out = append(out, &dbstructs.Unspent{
+			OutPoint: wire.OutPoint{
+				Hash:  chainhash.HashH([]byte(addr)),
+				Index: uint32(i),
+			},
+			Value:    v,
+			PkScript: pkScript,
+			Address:  addr,
+		})
+	}
+	return out
+}
+
+func sumCoins(coins []*dbstructs.Unspent) btcutil.Amount {
+	var total btcutil.Amount
+	for _, c := range coins {
+		total += btcutil.Amount(c.Value)
+	}
+	return total
+}
+
+func TestCoinSelection(t *testing.T) {
+	outputs := []*wire.TxOut{{Value: 100000, PkScript: testCoins("dest", 0)[0].PkScript}}
+	feePerKb := txrules.DefaultRelayFeePerKb
+
+	// Branch and bound finds the two coins which pay without change.
+	cs := newCoinSelector(outputs, feePerKb, 0, nil)
+	var none inputCounts
+	none.add(outputs[0].PkScript, 2)
+	exact := 100000 + int64(none.fee(outputs, feePerKb))
+	coins := testCoins("a", 500000, exact-30000, 30000, 70000, 1000)
+	sel := cs.branchAndBound(coins)
+	if len(sel) != 2 || sumCoins(sel) != btcutil.Amount(exact) {
+		t.Fatalf("branch and bound selected %d coins worth %s, expected 2 worth %d",
+			len(sel), sumCoins(sel), exact)
+	}
+
+	// Without a changeless set it falls back to largest first.
+	sel, err := cs.selectCoins(CoinSelectionBranchAndBound, testCoins("a", 300000, 900000))
+	if err != nil {
+		t.Fatal(err)
+	} else if sel[0].Value != 900000 {
+		t.Fatalf("expected fallback to largest first, got %d first", sel[0].Value)
+	}
+
+	// Smallest first replaces the biggest of the small coins when the
+	// smallest coins which fit are not enough.
+	cs = newCoinSelector(outputs, feePerKb, 3, nil)
+	sel = cs.smallestFirst(testCoins("a", 5000, 1000, 2000, 3000, 200000))
+	if len(sel) != 3 || sel[0].Value != 1000 || sel[2].Value != 200000 {
+		t.Fatalf("unexpected smallest first selection %v", sel)
+	}
+
+	// Largest first is limited to the maximum number of inputs.
+	sel = cs.largestFirst(testCoins("a", 5000, 1000, 2000, 3000, 200000))
+	if len(sel) != 3 || sel[0].Value != 200000 || sel[2].Value != 3000 {
+		t.Fatalf("unexpected largest first selection %v", sel)
+	}
+
+	// Privacy spends from the one address which can pay with the least change.
+	cs = newCoinSelector(outputs, feePerKb, 0, nil)
+	coins = append(testCoins("a", 90000, 90000), testCoins("b", 150000)...)
+	coins = append(coins, testCoins("c", 1000000)...)
+	sel, err = cs.privacy(coins)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(sel) != 1 || sel[0].Address != "b" {
+		t.Fatalf("expected the coin of address b, got %v", sel)
+	}
+
+	// And never merges addresses.
+	_, err = cs.privacy(append(testCoins("a", 60000), testCoins("b", 60000)...))
+	if !InsufficientFundsError.Is(err) {
+		t.Fatalf("expected InsufficientFundsError, got %v", err)
+	}
+
+	if _, err := ParseCoinSelection("LargestFirst"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseCoinSelection("knapsack"); err == nil {
+		t.Fatal("expected an unknown coin selection to be an error")
+	}
+}
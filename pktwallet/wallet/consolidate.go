@@ -0,0 +1,483 @@
+package wallet
+
+import (
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/enough"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+var (
+	// consolidateNamespaceKey is the top level bucket which holds the
+	// configuration of the consolidation service.
+	consolidateNamespaceKey = []byte("consolidate")
+	consolidateConfigKey    = []byte("config")
+)
+
+const (
+	// Version 1 had no MaxFeeSatPerKB.
+	consolidateConfigVersion = 2
+
+	// consolidateCheckInterval is how often the consolidation service wakes
+	// up to see whether it is time to run.
+	consolidateCheckInterval = time.Minute
+
+	consolidateLabel = "consolidation"
+)
+
+// ConsolidationConfig is the configuration of the service which folds the many
+// small coins left by mining payouts into fewer, bigger coins.
+type ConsolidationConfig struct {
+	// Enabled turns the service on.
+	Enabled bool
+
+	// Threshold is the number of coins an address must have before its
+	// coins are consolidated.
+	Threshold uint32
+
+	// BatchSize is the most coins which are spent by one consolidation
+	// transaction, this keeps the transactions under the size limit.
+	BatchSize uint32
+
+	// ToAddress, if set, is the address of this wallet which every
+	// consolidation pays to, otherwise the coins of each address are paid
+	// back to the same address.
+	ToAddress string
+
+	// FeeSatPerKB is the fee rate which consolidation transactions pay.
+	FeeSatPerKB btcutil.Amount
+
+	// MaxFeePercent is the most which a consolidation transaction may pay
+	// as fee, in percent of the value it spends.  If the coins are so small
+	// that the fee would be more, they are left alone.
+	MaxFeePercent uint32
+
+	// MaxFeeSatPerKB, if non-zero, is the highest fee estimate at which
+	// consolidation runs.  While the network asks for more, consolidation
+	// waits rather than competing with payments for block space.
+	MaxFeeSatPerKB btcutil.Amount
+
+	// MinConf is the number of confirmations a coin needs to be consolidated.
+	MinConf uint32
+
+	// Interval is the time between runs of the service.
+	Interval time.Duration
+}
+
+// DefaultConsolidationConfig is the configuration of a wallet which has never
+// configured the consolidation service.
+func DefaultConsolidationConfig() ConsolidationConfig {
+	return ConsolidationConfig{
+		Enabled:       false,
+		Threshold:     200,
+		BatchSize:     400,
+		FeeSatPerKB:   txrules.DefaultRelayFeePerKb,
+		MaxFeePercent: 1,
+		MinConf:       1,
+		Interval:      time.Hour,
+	}
+}
+
+func (c *ConsolidationConfig) validate(w *Wallet) er.R {
+	if c.Threshold < 2 {
+		return er.New("threshold must be at least 2")
+	}
+	if c.BatchSize < 2 || c.BatchSize > MaxInputsPerTx {
+		return er.Errorf("batch size must be between 2 and %d", MaxInputsPerTx)
+	}
+	if c.FeeSatPerKB < txrules.DefaultRelayFeePerKb {
+		return er.Errorf("fee rate must be at least the relay fee of %d sat per kB",
+			int64(txrules.DefaultRelayFeePerKb))
+	}
+	if c.MaxFeePercent == 0 || c.MaxFeePercent > 100 {
+		return er.New("max fee percent must be between 1 and 100")
+	}
+	if c.MaxFeeSatPerKB < 0 {
+		return er.New("max fee rate must not be negative")
+	}
+	if c.Interval < consolidateCheckInterval {
+		return er.Errorf("interval must be at least %s", consolidateCheckInterval)
+	}
+	if c.ToAddress != "" {
+		addr, err := btcutil.DecodeAddress(c.ToAddress, w.chainParams)
+		if err != nil {
+			return err
+		}
+		if ok, err := w.HaveAddress(addr); err != nil {
+			return err
+		} else if !ok {
+			return er.Errorf("address [%s] does not belong to this wallet", c.ToAddress)
+		}
+	}
+	return nil
+}
+
+func serializeConsolidationConfig(c *ConsolidationConfig) []byte {
+	buf := make([]byte, 44+len(c.ToAddress))
+	buf[0] = consolidateConfigVersion
+	if c.Enabled {
+		buf[1] = 1
+	}
+	binary.BigEndian.PutUint32(buf[2:6], c.Threshold)
+	binary.BigEndian.PutUint32(buf[6:10], c.BatchSize)
+	binary.BigEndian.PutUint64(buf[10:18], uint64(c.FeeSatPerKB))
+	binary.BigEndian.PutUint32(buf[18:22], c.MaxFeePercent)
+	binary.BigEndian.PutUint32(buf[22:26], c.MinConf)
+	binary.BigEndian.PutUint64(buf[26:34], uint64(c.Interval/time.Second))
+	binary.BigEndian.PutUint64(buf[34:42], uint64(c.MaxFeeSatPerKB))
+	binary.BigEndian.PutUint16(buf[42:44], uint16(len(c.ToAddress)))
+	copy(buf[44:], c.ToAddress)
+	return buf
+}
+
+func deserializeConsolidationConfig(b []byte) (*ConsolidationConfig, er.R) {
+	if len(b) < 36 || b[0] < 1 || b[0] > consolidateConfigVersion {
+		return nil, er.New("malformed consolidation config")
+	}
+	c := ConsolidationConfig{
+		Enabled:       b[1] == 1,
+		Threshold:     binary.BigEndian.Uint32(b[2:6]),
+		BatchSize:     binary.BigEndian.Uint32(b[6:10]),
+		FeeSatPerKB:   btcutil.Amount(binary.BigEndian.Uint64(b[10:18])),
+		MaxFeePercent: binary.BigEndian.Uint32(b[18:22]),
+		MinConf:       binary.BigEndian.Uint32(b[22:26]),
+		Interval:      time.Duration(binary.BigEndian.Uint64(b[26:34])) * time.Second,
+	}
+	off := 34
+	if b[0] >= 2 {
+		if len(b) < 44 {
+			return nil, er.New("malformed consolidation config")
+		}
+		c.MaxFeeSatPerKB = btcutil.Amount(binary.BigEndian.Uint64(b[34:42]))
+		off = 42
+	}
+	addrLen := int(binary.BigEndian.Uint16(b[off : off+2]))
+	if len(b) != off+2+addrLen {
+		return nil, er.New("malformed consolidation config")
+	}
+	c.ToAddress = string(b[off+2:])
+	return &c, nil
+}
+
+// ConsolidationConfig returns the configuration of the consolidation service.
+func (w *Wallet) ConsolidationConfig() (*ConsolidationConfig, er.R) {
+	c := DefaultConsolidationConfig()
+	out := &c
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		ns := tx.ReadBucket(consolidateNamespaceKey)
+		if ns == nil {
+			return nil
+		}
+		b := ns.Get(consolidateConfigKey)
+		if b == nil {
+			return nil
+		}
+		var err er.R
+		out, err = deserializeConsolidationConfig(b)
+		return err
+	})
+	return out, err
+}
+
+// SetConsolidationConfig stores the configuration of the consolidation service,
+// if it is enabled then it runs as soon as possible.
+func (w *Wallet) SetConsolidationConfig(c *ConsolidationConfig) er.R {
+	if err := c.validate(w); err != nil {
+		return err
+	}
+	if err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		ns, err := tx.CreateTopLevelBucket(consolidateNamespaceKey)
+		if err != nil {
+			return err
+		}
+		return ns.Put(consolidateConfigKey, serializeConsolidationConfig(c))
+	}); err != nil {
+		return err
+	}
+	log.Infof("Consolidation config updated, enabled: [%v]", c.Enabled)
+	w.TriggerConsolidation()
+	return nil
+}
+
+// ConsolidationStatus is the state of the consolidation service.
+type ConsolidationStatus struct {
+	Config ConsolidationConfig
+
+	// Running is true while consolidation transactions are being made.
+	Running bool
+
+	// LastRun and NextRun are the times of the last and the next run, they
+	// are zero if there has been no run or none is scheduled.
+	LastRun time.Time
+	NextRun time.Time
+
+	// LastError is the reason the last run failed or skipped an address.
+	LastError string
+
+	// AddressesOverThreshold is the number of addresses which had more coins
+	// than the threshold at the last run.
+	AddressesOverThreshold int
+
+	// Totals since the wallet was started.
+	Transactions      uint64
+	CoinsConsolidated uint64
+	FeesPaid          btcutil.Amount
+	LastTxid          string
+}
+
+type consolidationState struct {
+	status       ConsolidationStatus
+	trigger      bool
+	feeEstimator func() (btcutil.Amount, er.R)
+}
+
+// SetFeeEstimator sets the source of the current network fee rate, in
+// satoshis per kilobyte, which the consolidation service compares with
+// MaxFeeSatPerKB.
+func (w *Wallet) SetFeeEstimator(estimate func() (btcutil.Amount, er.R)) {
+	w.consolidation.In(func(cs *consolidationState) er.R {
+		cs.feeEstimator = estimate
+		return nil
+	})
+}
+
+// checkFeeEstimate returns an error if the current fee estimate is above
+// MaxFeeSatPerKB, or if there is a maximum and no estimate is available.
+func (w *Wallet) checkFeeEstimate(cfg *ConsolidationConfig) er.R {
+	if cfg.MaxFeeSatPerKB == 0 {
+		return nil
+	}
+	var estimate func() (btcutil.Amount, er.R)
+	w.consolidation.In(func(cs *consolidationState) er.R {
+		estimate = cs.feeEstimator
+		return nil
+	})
+	if estimate == nil {
+		return er.New("max fee rate is set but there is no fee estimate")
+	}
+	feeRate, err := estimate()
+	if err != nil {
+		return err
+	}
+	if feeRate > cfg.MaxFeeSatPerKB {
+		return er.Errorf("the fee estimate of [%d] sat per kB is more than the max of [%d]",
+			int64(feeRate), int64(cfg.MaxFeeSatPerKB))
+	}
+	return nil
+}
+
+// ConsolidationStatus returns the state of the consolidation service.
+func (w *Wallet) ConsolidationStatus() (*ConsolidationStatus, er.R) {
+	cfg, err := w.ConsolidationConfig()
+	if err != nil {
+		return nil, err
+	}
+	var out ConsolidationStatus
+	w.consolidation.In(func(cs *consolidationState) er.R {
+		out = cs.status
+		return nil
+	})
+	out.Config = *cfg
+	if !cfg.Enabled {
+		out.NextRun = time.Time{}
+	}
+	return &out, nil
+}
+
+// TriggerConsolidation makes the consolidation service run at its next check,
+// without waiting for the interval to pass.
+func (w *Wallet) TriggerConsolidation() {
+	w.consolidation.In(func(cs *consolidationState) er.R {
+		cs.trigger = true
+		return nil
+	})
+}
+
+// consolidator is the goroutine of the consolidation service.
+func (w *Wallet) consolidator() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(consolidateCheckInterval)
+	defer ticker.Stop()
+	quit := w.quitChan()
+	for {
+		select {
+		case <-ticker.C:
+			w.maybeConsolidate()
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (w *Wallet) maybeConsolidate() {
+	cfg, err := w.ConsolidationConfig()
+	if err != nil {
+		log.Warnf("Unable to load consolidation config: [%s]", err.String())
+		return
+	} else if !cfg.Enabled || !w.ChainSynced() {
+		return
+	}
+	due := false
+	now := time.Now()
+	w.consolidation.In(func(cs *consolidationState) er.R {
+		if cs.trigger || !now.Before(cs.status.LastRun.Add(cfg.Interval)) {
+			due = true
+			cs.trigger = false
+			cs.status.Running = true
+		}
+		return nil
+	})
+	if !due {
+		return
+	}
+	overThreshold, err := w.consolidate(cfg)
+	w.consolidation.In(func(cs *consolidationState) er.R {
+		cs.status.Running = false
+		cs.status.LastRun = now
+		cs.status.NextRun = now.Add(cfg.Interval)
+		cs.status.AddressesOverThreshold = overThreshold
+		if err != nil {
+			cs.status.LastError = err.Message()
+		} else {
+			cs.status.LastError = ""
+		}
+		return nil
+	})
+}
+
+// consolidatableCoins counts the coins of each address which consolidation
+// may spend, coins of watch-only and multisig accounts and locked coins are not
+// counted.
+func (w *Wallet) consolidatableCoins(minconf int32) (map[string]int, er.R) {
+	counts := make(map[string]int)
+	syncHeight := w.Manager.SyncedTo().Height
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		unsignable, err := w.unsignableAddrs(tx.ReadBucket(waddrmgrNamespaceKey))
+		if err != nil {
+			return err
+		}
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		_, err = w.TxStore.ForEachUnspentOutput(txmgrNs, nil, nil, func(_ []byte, uns *dbstructs.Unspent) er.R {
+			if _, ok := unsignable[uns.Address]; ok {
+				return nil
+			} else if uns.FromCoinBase && !confirmed(int32(w.chainParams.CoinbaseMaturity),
+				uns.Block.Height, syncHeight) {
+				return nil
+			} else if !confirmed(minconf, uns.Block.Height, syncHeight) {
+				return nil
+			} else if w.LockedOutpoint(uns.OutPoint) {
+				return nil
+			}
+			counts[uns.Address]++
+			return nil
+		})
+		return err
+	})
+	return counts, err
+}
+
+// consolidate makes one consolidation transaction for each address which has
+// more coins than the threshold.  It returns the number of such addresses.
+func (w *Wallet) consolidate(cfg *ConsolidationConfig) (int, er.R) {
+	if w.Manager.IsLocked() {
+		return 0, er.New("the wallet is locked, consolidation needs to sign")
+	}
+	if err := w.checkFeeEstimate(cfg); err != nil {
+		return 0, err
+	}
+	counts, err := w.consolidatableCoins(int32(cfg.MinConf))
+	if err != nil {
+		return 0, err
+	}
+	var addrs []string
+	for addr, n := range counts {
+		if n > int(cfg.Threshold) {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return 0, nil
+	}
+	sort.Slice(addrs, func(i, j int) bool { return counts[addrs[i]] > counts[addrs[j]] })
+	log.Infof("Consolidating the coins of [%s] addresses", log.Int(len(addrs)))
+
+	var lastErr er.R
+	for _, addrStr := range addrs {
+		if w.ShuttingDown() {
+			break
+		}
+		if err := w.consolidateAddress(cfg, addrStr); err != nil {
+			log.Infof("Unable to consolidate address [%s]: [%s]",
+				log.Address(addrStr), err.Message())
+			lastErr = err
+		}
+	}
+	return len(addrs), lastErr
+}
+
+// consolidateAddress sweeps the smallest coins of an address, up to the batch
+// size, into one coin.
+func (w *Wallet) consolidateAddress(cfg *ConsolidationConfig, addrStr string) er.R {
+	addr, err := btcutil.DecodeAddress(addrStr, w.chainParams)
+	if err != nil {
+		return err
+	}
+	to := addr
+	if cfg.ToAddress != "" {
+		if to, err = btcutil.DecodeAddress(cfg.ToAddress, w.chainParams); err != nil {
+			return err
+		}
+	}
+	pkScript, err := txscript.PayToAddrScript(to)
+	if err != nil {
+		return err
+	}
+
+	// A sweep output spends every coin it is given, so the size of the
+	// transaction is capped by MaxInputs and the smallest coins are taken
+	// first.
+	tx, err := w.CreateSimpleTx(CreateTxReq{
+		InputAddresses:  []btcutil.Address{addr},
+		Outputs:         []*wire.TxOut{wire.NewTxOut(enough.SweepOutputAmount, pkScript)},
+		Minconf:         int32(cfg.MinConf),
+		FeeSatPerKB:     cfg.FeeSatPerKB,
+		SendMode:        SendModeSigned,
+		InputComparator: PreferSmallest,
+		MaxInputs:       int(cfg.BatchSize),
+		Label:           consolidateLabel,
+	})
+	if err != nil {
+		return err
+	}
+	fee := tx.TotalInput
+	for _, out := range tx.Tx.TxOut {
+		fee -= btcutil.Amount(out.Value)
+	}
+	if fee*100 > tx.TotalInput*btcutil.Amount(cfg.MaxFeePercent) {
+		return er.Errorf("consolidating [%d] coins worth [%s] would pay a fee of [%s], "+
+			"which is more than [%d] percent", len(tx.Tx.TxIn), tx.TotalInput, fee, cfg.MaxFeePercent)
+	}
+	txid, err := w.ReliablyPublishTransaction(tx.Tx, consolidateLabel)
+	if err != nil {
+		return err
+	}
+	log.Infof("Consolidated [%s] coins of address [%s] in transaction [%s]",
+		log.Int(len(tx.Tx.TxIn)), log.Address(addrStr), log.Txid(txid.String()))
+	w.consolidation.In(func(cs *consolidationState) er.R {
+		cs.status.Transactions++
+		cs.status.CoinsConsolidated += uint64(len(tx.Tx.TxIn))
+		cs.status.FeesPaid += fee
+		cs.status.LastTxid = txid.String()
+		return nil
+	})
+	return nil
+}
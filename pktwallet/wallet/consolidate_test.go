@@ -0,0 +1,195 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+func TestConsolidationConfigSerialization(t *testing.T) {
+	c := DefaultConsolidationConfig()
+	c.Enabled = true
+	c.ToAddress = "pkt1q6hqsqhqdgqfd8t3xwgceulu7k9d9w5t2amath0qxyfjlvl3s3u4sjza2g2"
+	c.Interval = 90 * time.Minute
+	c.MaxFeeSatPerKB = 20000
+	got, err := deserializeConsolidationConfig(serializeConsolidationConfig(&c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != c {
+		t.Fatalf("config changed in serialization: got %+v, want %+v", *got, c)
+	}
+
+	b := serializeConsolidationConfig(&c)
+	if _, err := deserializeConsolidationConfig(b[:len(b)-1]); err == nil {
+		t.Fatal("expected a truncated config to be an error")
+	}
+
+	// A config stored before MaxFeeSatPerKB existed is still readable.
+	c.MaxFeeSatPerKB = 0
+	b = serializeConsolidationConfig(&c)
+	v1 := append([]byte{1}, b[1:34]...)
+	v1 = append(v1, b[42:]...)
+	if got, err := deserializeConsolidationConfig(v1); err != nil {
+		t.Fatal(err)
+	} else if *got != c {
+		t.Fatalf("version 1 config: got %+v, want %+v", *got, c)
+	}
+}
+
+// TestConsolidateAddress gives an address more coins than the batch size,
+// locks the smallest, and checks that consolidation leaves it alone, spends no
+// more than the batch size, and refuses to pay more than the fee caps.
+func TestConsolidateAddress(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	addr, err := w.NewAddress(0, waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incomingTx := &wire.MsgTx{TxIn: []*wire.TxIn{{}}}
+	for i := 0; i < 7; i++ {
+		incomingTx.AddTxOut(wire.NewTxOut(int64(100000*(i+1)), pkScript))
+	}
+	addUtxos(t, w, incomingTx)
+	incomingHash := incomingTx.TxHash()
+	locked := wire.OutPoint{Hash: incomingHash, Index: 0}
+	w.LockOutpoint(locked, "test")
+
+	counts, err := w.consolidatableCoins(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[addr.String()] != 6 {
+		t.Fatalf("expected 6 coins which may be consolidated, got %d",
+			counts[addr.String()])
+	}
+
+	cfg := DefaultConsolidationConfig()
+	cfg.BatchSize = 3
+	cfg.MinConf = 0
+	if err := w.consolidateAddress(&cfg, addr.String()); err != nil {
+		t.Fatalf("unable to consolidate: %v", err)
+	}
+	status, err := w.ConsolidationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Transactions != 1 || status.CoinsConsolidated != 3 {
+		t.Fatalf("expected 1 transaction of 3 coins, got %d of %d",
+			status.Transactions, status.CoinsConsolidated)
+	}
+	txid, err := chainhash.NewHashFromStr(status.LastTxid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var details *wtxmgr.TxDetails
+	if err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		var err er.R
+		details, err = w.TxStore.TxDetails(tx.ReadBucket(wtxmgrNamespaceKey), txid)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if details == nil || len(details.MsgTx.TxIn) != 3 || len(details.MsgTx.TxOut) != 1 {
+		t.Fatalf("expected a transaction with 3 inputs and 1 output")
+	}
+	// The smallest coins are spent first, skipping the locked one.
+	for _, in := range details.MsgTx.TxIn {
+		if in.PreviousOutPoint == locked {
+			t.Fatalf("the locked outpoint was spent")
+		}
+		if in.PreviousOutPoint.Hash != incomingHash ||
+			in.PreviousOutPoint.Index < 1 || in.PreviousOutPoint.Index > 3 {
+			t.Fatalf("unexpected input %v", in.PreviousOutPoint)
+		}
+	}
+	if status.FeesPaid*100 > 900000 {
+		t.Fatalf("fee of %v is more than 1 percent", status.FeesPaid)
+	}
+
+	// A fee rate which costs more than MaxFeePercent makes no transaction.
+	cfg.FeeSatPerKB = 1000000
+	if err := w.consolidateAddress(&cfg, addr.String()); err == nil {
+		t.Fatalf("expected the fee to be too high")
+	}
+
+	// Nor does a fee estimate above MaxFeeSatPerKB, or no estimate at all.
+	cfg = DefaultConsolidationConfig()
+	cfg.Threshold = 2
+	cfg.BatchSize = 3
+	cfg.MinConf = 0
+	cfg.MaxFeeSatPerKB = 5000
+	if _, err := w.consolidate(&cfg); err == nil {
+		t.Fatalf("expected consolidation to need a fee estimate")
+	}
+	w.SetFeeEstimator(func() (btcutil.Amount, er.R) { return 10000, nil })
+	if _, err := w.consolidate(&cfg); err == nil {
+		t.Fatalf("expected the fee estimate to be too high")
+	}
+	if status, err := w.ConsolidationStatus(); err != nil {
+		t.Fatal(err)
+	} else if status.Transactions != 1 {
+		t.Fatalf("expected no more transactions, got %d", status.Transactions)
+	}
+
+	w.SetFeeEstimator(func() (btcutil.Amount, er.R) { return 1000, nil })
+	if n, err := w.consolidate(&cfg); err != nil {
+		t.Fatalf("unable to consolidate: %v", err)
+	} else if n != 1 {
+		t.Fatalf("expected 1 address over the threshold, got %d", n)
+	}
+	if status, err := w.ConsolidationStatus(); err != nil {
+		t.Fatal(err)
+	} else if status.Transactions != 2 {
+		t.Fatalf("expected 2 transactions, got %d", status.Transactions)
+	}
+}
+
+// addUtxos adds every output of incomingTx to the wallet as a confirmed coin.
+func addUtxos(t *testing.T, w *Wallet, incomingTx *wire.MsgTx) {
+	var b bytes.Buffer
+	if err := incomingTx.Serialize(&b); err != nil {
+		t.Fatalf("unable to serialize tx: %v", err)
+	}
+	rec, err := wtxmgr.NewTxRecord(b.Bytes(), time.Now())
+	if err != nil {
+		t.Fatalf("unable to create tx record: %v", err)
+	}
+	block := &wtxmgr.BlockMeta{
+		Block: dbstructs.Block{
+			Hash:   *testBlockHash,
+			Height: testBlockHeight,
+		},
+		Time: time.Unix(1387737310, 0),
+	}
+	if err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		ns := tx.ReadWriteBucket(wtxmgrNamespaceKey)
+		if err := w.TxStore.InsertTx(ns, rec, block); err != nil {
+			return err
+		}
+		for i := range incomingTx.TxOut {
+			if err := w.TxStore.AddCredit(ns, rec, block, uint32(i), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("failed inserting tx: %v", err)
+	}
+}
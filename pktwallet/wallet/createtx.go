@@ -17,6 +17,7 @@ import (
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/chainiface"
 	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/enough"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/txauthor"
@@ -143,16 +144,22 @@ func (w *Wallet) txToOutputs(txr CreateTxReq) (tx *txauthor.AuthoredTx, err er.R
 		return nil, err
 	}
 
-	isEnough := enough.MkIsEnough(txr.Outputs, txr.FeeSatPerKB)
 	t0 := time.Now()
-	eligibleOuts, visits, err := w.findEligibleOutputs(
-		dbtx, isEnough, txr.InputAddresses, txr.Minconf, bs,
-		txr.InputMinHeight, txr.InputComparator, txr.MaxInputs)
+	var eligibleOuts eligibleOutputs
+	var visits int
+	if txr.CoinSelection == CoinSelectionDefault {
+		isEnough := enough.MkIsEnough(txr.Outputs, txr.FeeSatPerKB)
+		eligibleOuts, visits, err = w.findEligibleOutputs(
+			dbtx, isEnough, txr.InputAddresses, txr.Minconf, bs,
+			txr.InputMinHeight, txr.InputComparator, txr.MaxInputs)
+	} else {
+		eligibleOuts, visits, err = w.findCoinsForStrategy(dbtx, &txr, bs)
+	}
 	if err != nil {
 		return nil, err
 	}
-	log.Infof("findEligibleOutputs() completed in [%s], visited [%d] utxos",
-		time.Since(t0).String(), visits)
+	log.Infof("Coin selection [%s] completed in [%s], visited [%d] utxos",
+		coinSelectionName(txr.CoinSelection), time.Since(t0).String(), visits)
 
 	addrStr := "<all>"
 	if len(txr.InputAddresses) > 0 {
@@ -229,7 +236,9 @@ func (w *Wallet) txToOutputs(txr CreateTxReq) (tx *txauthor.AuthoredTx, err er.R
 	// scripts, and don't commit the database transaction. The DB will be
 	// rolled back when this method returns to ensure the dry run didn't
 	// alter the DB in any way.
-	if txr.SendMode == SendModeUnsigned {
+	if txr.SendMode == SendModeSimulate {
+		return tx, nil
+	} else if txr.SendMode == SendModeUnsigned {
 		if err := dbtx.Commit(); err != nil {
 			return nil, err
 		}
@@ -355,9 +364,9 @@ func PreferBiggest(a, b interface{}) int {
 }
 
 // PreferSmallest prefers smallest (coin value) outputs first (spend the dust)
-// func PreferSmallest(a, b interface{}) int {
-// 	return -PreferBiggest(a, b)
-// }
+func PreferSmallest(a, b interface{}) int {
+	return -PreferBiggest(a, b)
+}
 
 func convertResult(ac *amountCount) []*dbstructs.Unspent {
 	ifaces := ac.credits.Keys()
@@ -379,6 +388,124 @@ type eligibleOutputs struct {
 	unusedAmt        btcutil.Amount
 }
 
+// outputFilter decides which unspent outputs may be spent by a new
+// transaction, it counts the outputs which are only skipped because they are
+// not yet confirmed and collects burned outputs so they can be deleted.
+type outputFilter struct {
+	w              *Wallet
+	chainClient    chainiface.Interface
+	bs             *waddrmgr.BlockStamp
+	minconf        int32
+	inputMinHeight int
+	unsignable     map[string]struct{}
+	burnedOutputs  []wire.OutPoint
+	out            *eligibleOutputs
+}
+
+func (w *Wallet) newOutputFilter(
+	dbtx walletdb.ReadWriteTx,
+	fromAddresses map[string]struct{},
+	minconf int32,
+	bs *waddrmgr.BlockStamp,
+	inputMinHeight int,
+	out *eligibleOutputs,
+) (*outputFilter, er.R) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return nil, err
+	}
+	f := &outputFilter{
+		w:              w,
+		chainClient:    chainClient,
+		bs:             bs,
+		minconf:        minconf,
+		inputMinHeight: inputMinHeight,
+		out:            out,
+	}
+
+	// Coins of watch-only and multisig accounts can not be signed for by
+	// this wallet alone, so they are only selected if their addresses were
+	// asked for.
+	if len(fromAddresses) == 0 {
+		f.unsignable, err = w.unsignableAddrs(dbtx.ReadBucket(waddrmgrNamespaceKey))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (f *outputFilter) eligible(uns *dbstructs.Unspent) bool {
+	w := f.w
+	if _, ok := f.unsignable[uns.Address]; ok {
+		return false
+	}
+
+	if uns.Block.Height >= 0 && uns.Block.Height < int32(f.inputMinHeight) {
+		log.Debugf("Skipping output %s at height %d because it is below minimum %d",
+			uns.OutPoint.String(), uns.Block.Height, f.inputMinHeight)
+		return false
+	}
+
+	if uns.FromCoinBase {
+		if !confirmed(int32(w.chainParams.CoinbaseMaturity), uns.Block.Height, f.bs.Height) {
+			log.Debugf("Skipping immature coinbase output [%s] at height %d",
+				uns.OutPoint.String(), uns.Block.Height)
+			return false
+		} else if txrules.IsBurned(uns, w.chainParams, f.bs.Height+1440) {
+			log.Tracef("Skipping burned output at height %d", uns.Block.Height)
+			if len(f.burnedOutputs) < 1_000_000 {
+				f.burnedOutputs = append(f.burnedOutputs, uns.OutPoint)
+			}
+			return false
+		}
+	}
+
+	if f.minconf > 0 {
+		// Only include this output if it meets the required number of
+		// confirmations.  Coinbase transactions must have have reached
+		// maturity before their outputs may be spent.
+		if !confirmed(f.minconf, uns.Block.Height, f.bs.Height) {
+			log.Debugf("Skipping unconfirmed output [%s] at height %d [cur height: %d]",
+				uns.OutPoint.String(), uns.Block.Height, f.bs.Height)
+			f.out.unconfirmedCount++
+			f.out.unconfirmedAmt += btcutil.Amount(uns.Value)
+			return false
+		}
+	}
+
+	// Locked unspent outputs are skipped.
+	if w.LockedOutpoint(uns.OutPoint) {
+		return false
+	}
+
+	// If there is an unspent which references a block header which doesn't
+	// actually exist we've got some trouble. Lets make sure before we try to
+	// spend it.
+	if uns.Block.Height < 0 {
+	} else if _, err := f.chainClient.GetBlockHeader(&uns.Block.Hash); err != nil {
+		log.Debugf("Input [%s] references block hash [%s] which is not in chain, skipping",
+			uns.OutPoint.String(), uns.Block.Hash)
+		return false
+	}
+	return true
+}
+
+// deleteBurned removes the burned outputs which were found from the database.
+func (f *outputFilter) deleteBurned(dbtx walletdb.ReadWriteTx) er.R {
+	if len(f.burnedOutputs) == 0 {
+		return nil
+	}
+	wtxmgrBucket := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+	log.Infof("Deleting [%s] burned coins", log.Int(len(f.burnedOutputs)))
+	for _, op := range f.burnedOutputs {
+		if err := unspent.Delete(wtxmgrBucket, &op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w *Wallet) findEligibleOutputs(
 	dbtx walletdb.ReadWriteTx,
 	isEnough enough.IsEnough,
@@ -390,17 +517,11 @@ func (w *Wallet) findEligibleOutputs(
 	maxInputs int,
 ) (eligibleOutputs, int, er.R) {
 	out := eligibleOutputs{}
-	chainClient, err := w.requireChainClient()
-	if err != nil {
-		return out, 0, err
-	}
 	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
 
 	haveAmounts := make(map[string]*amountCount)
 	var winner *amountCount
 
-	var burnedOutputs []wire.OutPoint
-
 	log.Debugf("Looking for unspents to build transaction")
 
 	addrStrs := make(map[string]struct{})
@@ -408,54 +529,15 @@ func (w *Wallet) findEligibleOutputs(
 		addrStrs[a.String()] = struct{}{}
 	}
 
+	filter, err := w.newOutputFilter(dbtx, addrStrs, minconf, bs, inputMinHeight, &out)
+	if err != nil {
+		return out, 0, err
+	}
+
 	var visits int
 	if visits, err = w.TxStore.ForEachUnspentOutput(txmgrNs, nil, addrStrs, func(key []byte, uns *dbstructs.Unspent) er.R {
 
-		if uns.Block.Height >= 0 && uns.Block.Height < int32(inputMinHeight) {
-			log.Debugf("Skipping output %s at height %d because it is below minimum %d",
-				uns.OutPoint.String(), uns.Block.Height, inputMinHeight)
-			return nil
-		}
-
-		if uns.FromCoinBase {
-			if !confirmed(int32(w.chainParams.CoinbaseMaturity), uns.Block.Height, bs.Height) {
-				log.Debugf("Skipping immature coinbase output [%s] at height %d",
-					uns.OutPoint.String(), uns.Block.Height)
-				return nil
-			} else if txrules.IsBurned(uns, w.chainParams, bs.Height+1440) {
-				log.Tracef("Skipping burned output at height %d", uns.Block.Height)
-				if len(burnedOutputs) < 1_000_000 {
-					burnedOutputs = append(burnedOutputs, uns.OutPoint)
-				}
-				return nil
-			}
-		}
-
-		if minconf > 0 {
-			// Only include this output if it meets the required number of
-			// confirmations.  Coinbase transactions must have have reached
-			// maturity before their outputs may be spent.
-			if !confirmed(minconf, uns.Block.Height, bs.Height) {
-				log.Debugf("Skipping unconfirmed output [%s] at height %d [cur height: %d]",
-					uns.OutPoint.String(), uns.Block.Height, bs.Height)
-				out.unconfirmedCount++
-				out.unconfirmedAmt += btcutil.Amount(uns.Value)
-				return nil
-			}
-		}
-
-		// Locked unspent outputs are skipped.
-		if w.LockedOutpoint(uns.OutPoint) {
-			return nil
-		}
-
-		// If there is an unspent which references a block header which doesn't
-		// actually exist we've got some trouble. Lets make sure before we try to
-		// spend it.
-		if uns.Block.Height < 0 {
-		} else if _, err := chainClient.GetBlockHeader(&uns.Block.Hash); err != nil {
-			log.Debugf("Input [%s] references block hash [%s] which is not in chain, skipping",
-				uns.OutPoint.String(), uns.Block.Hash)
+		if !filter.eligible(uns) {
 			return nil
 		}
 
@@ -535,14 +617,8 @@ func (w *Wallet) findEligibleOutputs(
 
 	log.Debugf("Got unspents")
 
-	if len(burnedOutputs) > 0 {
-		wtxmgrBucket := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
-		log.Infof("Deleting [%s] burned coins", log.Int(len(burnedOutputs)))
-		for _, op := range burnedOutputs {
-			if err := unspent.Delete(wtxmgrBucket, &op); err != nil {
-				return out, visits, err
-			}
-		}
+	if err := filter.deleteBurned(dbtx); err != nil {
+		return out, visits, err
 	}
 
 	if inputComparator != nil {
@@ -0,0 +1,359 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/lock"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/txscript"
+)
+
+// Number of recent events which are kept so that a client which reconnects
+// can resume from the last sequence number that it received.
+const walletEventHistory = 4096
+
+// The sequence numbers of consecutive events in the log are this far apart, the
+// numbers between a WALLET_BLOCK event and the next event belong to the
+// WALLET_TX_CONFIRMATIONS events which the block causes.  A block can not hold
+// nearly this many transactions.
+const walletEventSeqStep = 1 << 16
+
+// Number of events which can be waiting for one subscriber, a subscriber which
+// falls further behind than this is dropped and must resume by sequence number.
+const walletEventDepth = 1024
+
+type eventLogMut struct {
+	seq         uint64
+	recent      []*rpc_pb.WalletEvent
+	subscribers map[chan *rpc_pb.WalletEvent]struct{}
+}
+
+// eventLog gives each wallet event a sequence number and passes it to every
+// subscriber, it keeps the most recent events so they can be sent again.
+type eventLog struct {
+	streamId string
+	m        lock.GenMutex[eventLogMut]
+}
+
+func newEventLog() *eventLog {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		panic("newEventLog: unable to get random bytes")
+	}
+	return &eventLog{
+		streamId: hex.EncodeToString(id[:]),
+		m: lock.NewGenMutex(eventLogMut{
+			subscribers: make(map[chan *rpc_pb.WalletEvent]struct{}),
+		}, "wallet eventLog"),
+	}
+}
+
+func (l *eventLog) emit(ev *rpc_pb.WalletEvent) {
+	l.m.In(func(m *eventLogMut) er.R {
+		m.seq += walletEventSeqStep
+		ev.Sequence = m.seq
+		ev.StreamId = l.streamId
+		if len(m.recent) >= walletEventHistory {
+			copy(m.recent, m.recent[1:])
+			m.recent = m.recent[:len(m.recent)-1]
+		}
+		m.recent = append(m.recent, ev)
+		for ch := range m.subscribers {
+			select {
+			case ch <- ev:
+			default:
+				log.Debugf("Wallet event subscriber fell behind at sequence [%d], dropping", ev.Sequence)
+				delete(m.subscribers, ch)
+				close(ch)
+			}
+		}
+		return nil
+	})
+}
+
+func (l *eventLog) head() uint64 {
+	var out uint64
+	l.m.In(func(m *eventLogMut) er.R {
+		out = m.seq
+		return nil
+	})
+	return out
+}
+
+// subscribe returns the recent events which come after afterSeq and a channel
+// which will receive every event after those.
+func (l *eventLog) subscribe(afterSeq uint64) ([]*rpc_pb.WalletEvent, chan *rpc_pb.WalletEvent, er.R) {
+	var backlog []*rpc_pb.WalletEvent
+	ch := make(chan *rpc_pb.WalletEvent, walletEventDepth)
+	if err := l.m.In(func(m *eventLogMut) er.R {
+		if afterSeq > m.seq {
+			return er.Errorf("Sequence number [%d] has not happened yet, the last event is [%d]",
+				afterSeq, m.seq)
+		}
+		if len(m.recent) > 0 && afterSeq+walletEventSeqStep < m.recent[0].Sequence {
+			return er.Errorf("Events after sequence number [%d] are no longer available, "+
+				"the oldest is [%d], use from_height to resume", afterSeq, m.recent[0].Sequence)
+		}
+		for _, ev := range m.recent {
+			if ev.Sequence > afterSeq {
+				backlog = append(backlog, ev)
+			}
+		}
+		m.subscribers[ch] = struct{}{}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	return backlog, ch, nil
+}
+
+func (l *eventLog) unsubscribe(ch chan *rpc_pb.WalletEvent) {
+	l.m.In(func(m *eventLogMut) er.R {
+		if _, ok := m.subscribers[ch]; ok {
+			delete(m.subscribers, ch)
+			close(ch)
+		}
+		return nil
+	})
+}
+
+// txEvent makes an event for a transaction, showing how it changes the balance
+// of each of the wallet's addresses and accounts.
+func txEvent(
+	dbtx walletdb.ReadTx,
+	w *Wallet,
+	details *wtxmgr.TxDetails,
+	t rpc_pb.WalletEventType,
+) *rpc_pb.WalletEvent {
+	ev := &rpc_pb.WalletEvent{
+		Type:        t,
+		Txid:        details.Hash.String(),
+		BlockHeight: details.Block.Height,
+	}
+	if details.Block.Height >= 0 {
+		ev.BlockHash = details.Block.Hash.String()
+		ev.BlockTime = details.Block.Time.Unix()
+		// The block which is being synced is not yet our tip, but it counts
+		c := confirms(details.Block.Height, w.Manager.SyncedTo().Height)
+		if c < 1 {
+			c = 1
+		}
+		ev.Confirmations = uint32(c)
+	}
+
+	addrDeltas := make(map[string]*rpc_pb.WalletBalanceDelta)
+	acctDeltas := make(map[uint32]int64)
+	add := func(addr string, acct uint32, amt btcutil.Amount) {
+		if d, ok := addrDeltas[addr]; ok {
+			d.Amount += int64(amt)
+		} else {
+			d = &rpc_pb.WalletBalanceDelta{Address: addr, Account: acct, Amount: int64(amt)}
+			addrDeltas[addr] = d
+			ev.AddressDeltas = append(ev.AddressDeltas, d)
+		}
+		acctDeltas[acct] += int64(amt)
+	}
+	for _, deb := range details.Debits {
+		addr, acct := lookupInput(dbtx, w, details, deb)
+		add(addr, acct, -deb.Amount)
+	}
+	for _, cred := range details.Credits {
+		var addr string
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			details.MsgTx.TxOut[cred.Index].PkScript, w.chainParams)
+		if err == nil && len(addrs) > 0 {
+			addr = addrs[0].EncodeAddress()
+		}
+		acct, _ := lookupOutputChain(dbtx, w, details, cred)
+		add(addr, acct, cred.Amount)
+	}
+	for acct, amt := range acctDeltas {
+		ev.AccountDeltas = append(ev.AccountDeltas, &rpc_pb.WalletAccountDelta{
+			Account: acct,
+			Amount:  amt,
+		})
+	}
+	sort.Slice(ev.AccountDeltas, func(i, j int) bool {
+		return ev.AccountDeltas[i].Account < ev.AccountDeltas[j].Account
+	})
+	return ev
+}
+
+// eventsInRange makes an event for each transaction mined between begin and
+// end, and for each unconfirmed transaction if end is -1.
+func (s *NotificationServer) eventsInRange(
+	begin, end int32,
+	t rpc_pb.WalletEventType,
+	match func(*rpc_pb.WalletEvent) bool,
+) ([]*rpc_pb.WalletEvent, er.R) {
+	var out []*rpc_pb.WalletEvent
+	if err := walletdb.View(s.wallet.db, func(dbtx walletdb.ReadTx) er.R {
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+		return s.wallet.TxStore.RangeTransactions(txmgrNs, begin, end,
+			func(details []wtxmgr.TxDetails) (bool, er.R) {
+				for i := range details {
+					tt := t
+					if details[i].Block.Height < 0 {
+						tt = rpc_pb.WalletEventType_WALLET_TX_MEMPOOL
+					}
+					if ev := txEvent(dbtx, s.wallet, &details[i], tt); match(ev) {
+						out = append(out, ev)
+					}
+				}
+				return false, nil
+			})
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WalletEvents opens a stream of events about the wallet's transactions and
+// balances, first sending anything which the request asks to be replayed.
+// The stream ends when stop is closed or if the client falls too far behind.
+func (s *NotificationServer) WalletEvents(
+	req *rpc_pb.WalletEventsRequest,
+	stop <-chan struct{},
+) (<-chan *rpc_pb.WalletEvent, er.R) {
+	if req.AfterSequence > 0 && req.FromHeight > 0 {
+		return nil, er.New("Only one of after_sequence and from_height may be given")
+	}
+	addrs := make(map[string]struct{})
+	for _, a := range req.Addresses {
+		if addr, err := btcutil.DecodeAddress(a, s.wallet.chainParams); err != nil {
+			return nil, err
+		} else {
+			addrs[addr.EncodeAddress()] = struct{}{}
+		}
+	}
+	match := func(ev *rpc_pb.WalletEvent) bool {
+		if len(addrs) == 0 || ev.Type == rpc_pb.WalletEventType_WALLET_BLOCK {
+			return true
+		}
+		for _, d := range ev.AddressDeltas {
+			if _, ok := addrs[d.Address]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	after := req.AfterSequence
+	var replay []*rpc_pb.WalletEvent
+	if after > 0 {
+		if req.StreamId != s.events.streamId {
+			return nil, er.Errorf("Stream id [%s] does not match [%s], pld has restarted "+
+				"since then so use from_height to resume", req.StreamId, s.events.streamId)
+		}
+		// If the client stopped among the confirmations of a block then
+		// the block is sent through again to get the rest of them, send
+		// skips everything which the client already has.
+		if sub := after % walletEventSeqStep; sub > 0 && after > walletEventSeqStep {
+			after -= sub + walletEventSeqStep
+		}
+	} else {
+		// Events which happen while replaying are sent again afterward,
+		// so a transaction may be seen twice but none are missed.
+		after = s.events.head()
+		if req.FromHeight > 0 {
+			var err er.R
+			replay, err = s.eventsInRange(req.FromHeight, -1,
+				rpc_pb.WalletEventType_WALLET_TX_CONFIRMED, match)
+			if err != nil {
+				return nil, err
+			}
+			for _, ev := range replay {
+				ev.Sequence = after
+				ev.StreamId = s.events.streamId
+				ev.Replay = true
+			}
+		}
+	}
+	backlog, ch, err := s.events.subscribe(after)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *rpc_pb.WalletEvent)
+	send := func(ev *rpc_pb.WalletEvent) bool {
+		if !match(ev) || ev.Sequence <= req.AfterSequence {
+			return true
+		}
+		select {
+		case out <- ev:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+	// sendConfirmations reports the transactions which have reached the
+	// requested depth because of a new block.
+	sendConfirmations := func(block *rpc_pb.WalletEvent) bool {
+		height := block.BlockHeight - int32(req.Confirmations) + 1
+		if req.Confirmations == 0 || height < 0 {
+			return true
+		}
+		// Every transaction is numbered, so the sequence numbers are the
+		// same whichever addresses the client asked for.
+		evs, err := s.eventsInRange(height, height,
+			rpc_pb.WalletEventType_WALLET_TX_CONFIRMATIONS,
+			func(*rpc_pb.WalletEvent) bool { return true })
+		if err != nil {
+			log.Warnf("Unable to get transactions at height [%d]: [%s]", height, err)
+			return true
+		}
+		for i, ev := range evs {
+			ev.Sequence = block.Sequence + uint64(i+1)
+			ev.StreamId = block.StreamId
+			ev.Confirmations = req.Confirmations
+			if !send(ev) {
+				return false
+			}
+		}
+		return true
+	}
+	sendLive := func(ev *rpc_pb.WalletEvent) bool {
+		if !send(ev) {
+			return false
+		}
+		if ev.Type == rpc_pb.WalletEventType_WALLET_BLOCK {
+			return sendConfirmations(ev)
+		}
+		return true
+	}
+	go func() {
+		defer close(out)
+		defer s.events.unsubscribe(ch)
+		for _, ev := range replay {
+			if !send(ev) {
+				return
+			}
+		}
+		for _, ev := range backlog {
+			if !sendLive(ev) {
+				return
+			}
+		}
+		for {
+			select {
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+				if !sendLive(ev) {
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
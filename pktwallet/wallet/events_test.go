@@ -0,0 +1,147 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// TestEventLogResume checks that a subscriber which resumes from a sequence
+// number gets the events it missed followed by live events.
+func TestEventLogResume(t *testing.T) {
+	l := newEventLog()
+	for i := 0; i < 3; i++ {
+		l.emit(&rpc_pb.WalletEvent{Type: rpc_pb.WalletEventType_WALLET_BLOCK, BlockHeight: int32(i)})
+	}
+	if l.head() != 3*walletEventSeqStep {
+		t.Fatalf("expected head %d, got %d", 3*walletEventSeqStep, l.head())
+	}
+
+	backlog, ch, err := l.subscribe(walletEventSeqStep)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer l.unsubscribe(ch)
+	if len(backlog) != 2 || backlog[0].Sequence != 2*walletEventSeqStep ||
+		backlog[1].Sequence != 3*walletEventSeqStep {
+		t.Fatalf("unexpected backlog %v", backlog)
+	}
+	if backlog[0].StreamId != l.streamId {
+		t.Fatalf("expected stream id %s, got %s", l.streamId, backlog[0].StreamId)
+	}
+
+	l.emit(&rpc_pb.WalletEvent{Type: rpc_pb.WalletEventType_WALLET_BLOCK})
+	if ev := <-ch; ev.Sequence != 4*walletEventSeqStep {
+		t.Fatalf("expected live event %d, got %d", 4*walletEventSeqStep, ev.Sequence)
+	}
+
+	if _, _, err := l.subscribe(5 * walletEventSeqStep); err == nil {
+		t.Fatalf("expected an error resuming from the future")
+	}
+}
+
+// TestEventLogHistory checks that events which have been dropped from the
+// history can not be resumed from.
+func TestEventLogHistory(t *testing.T) {
+	l := newEventLog()
+	for i := 0; i < walletEventHistory+10; i++ {
+		l.emit(&rpc_pb.WalletEvent{Type: rpc_pb.WalletEventType_WALLET_BLOCK})
+	}
+	if _, _, err := l.subscribe(5 * walletEventSeqStep); err == nil {
+		t.Fatalf("expected an error resuming from a forgotten event")
+	}
+	backlog, ch, err := l.subscribe(10 * walletEventSeqStep)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	l.unsubscribe(ch)
+	if len(backlog) != walletEventHistory {
+		t.Fatalf("expected %d events, got %d", walletEventHistory, len(backlog))
+	}
+}
+
+// TestWalletEventsConfirmations checks that block events are only sent once
+// they are committed and that each transaction which reaches the requested
+// depth gets its own sequence number, which a client can resume from.
+func TestWalletEventsConfirmations(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	for i := 0; i < 2; i++ {
+		addUtxo(t, w, &wire.MsgTx{
+			TxIn:  []*wire.TxIn{{}},
+			TxOut: []*wire.TxOut{wire.NewTxOut(int64(100000*(i+1)), testScriptP2WKH)},
+		})
+	}
+	attach := func(height int32, fail bool) er.R {
+		return walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) er.R {
+			w.NtfnServer.notifyAttachedBlock(dbtx, &wtxmgr.BlockMeta{
+				Block: dbstructs.Block{Hash: *testBlockHash, Height: height},
+				Time:  time.Unix(1387737310, 0),
+			})
+			if fail {
+				return er.New("rolled back")
+			}
+			return nil
+		})
+	}
+	// Resuming needs an event to resume from.
+	if err := attach(testBlockHeight+1, false); err != nil {
+		t.Fatal(err)
+	}
+	head := w.NtfnServer.events.head()
+	if err := attach(testBlockHeight+2, true); err == nil {
+		t.Fatalf("expected the update to fail")
+	}
+	if w.NtfnServer.events.head() != head {
+		t.Fatalf("an event was sent for a block which was rolled back")
+	}
+	if err := attach(testBlockHeight+2, false); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	ch, err := w.NtfnServer.WalletEvents(&rpc_pb.WalletEventsRequest{
+		AfterSequence: head,
+		StreamId:      w.NtfnServer.events.streamId,
+		Confirmations: 3,
+	}, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := <-ch
+	if block.Type != rpc_pb.WalletEventType_WALLET_BLOCK {
+		t.Fatalf("expected a block event, got %v", block.Type)
+	}
+	var confs []*rpc_pb.WalletEvent
+	for i := 0; i < 2; i++ {
+		ev := <-ch
+		if ev.Type != rpc_pb.WalletEventType_WALLET_TX_CONFIRMATIONS {
+			t.Fatalf("expected a confirmations event, got %v", ev.Type)
+		}
+		if ev.Sequence != block.Sequence+uint64(i+1) {
+			t.Fatalf("expected sequence %d, got %d", block.Sequence+uint64(i+1), ev.Sequence)
+		}
+		confs = append(confs, ev)
+	}
+
+	// Resuming after the first confirmation sends only the second.
+	ch, err = w.NtfnServer.WalletEvents(&rpc_pb.WalletEventsRequest{
+		AfterSequence: confs[0].Sequence,
+		StreamId:      w.NtfnServer.events.streamId,
+		Confirmations: 3,
+	}, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-ch; ev.Sequence != confs[1].Sequence || ev.Txid != confs[1].Txid {
+		t.Fatalf("expected to resume with %d, got %d", confs[1].Sequence, ev.Sequence)
+	}
+}
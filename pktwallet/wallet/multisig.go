@@ -6,7 +6,20 @@
 package wallet
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strings"
+
 	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/btcutil/psbt"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/txscript/params"
+	"github.com/pkt-cash/pktd/wire"
 
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
@@ -154,3 +167,741 @@ func (w *Wallet) ImportP2WSHRedeemScript(script []byte) (*btcutil.AddressWitness
 	})
 	return p2shAddr, err
 }
+
+// multisigPath is the place of a multisig address in its account.
+type multisigPath struct {
+	branch uint32
+	index  uint32
+	script []byte
+}
+
+// multisigPubKeys derives the public key of each cosigner of a multisig account
+// at a branch and index, in the order of the account keys.
+func multisigPubKeys(acct *waddrmgr.MultisigAccount, branch, index uint32) ([][]byte, er.R) {
+	out := make([][]byte, 0, len(acct.Keys))
+	for _, key := range acct.Keys {
+		child, err := key.Derive(branch)
+		if err != nil {
+			return nil, err
+		}
+		child, err = child.Derive(index)
+		if err != nil {
+			return nil, err
+		}
+		pubKey, err := child.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pubKey.SerializeCompressed())
+	}
+	return out, nil
+}
+
+// multisigScript makes the witness script of the address of a multisig account
+// at a branch and index.  The keys are sorted as in BIP0067 so every cosigner
+// makes the same script no matter what order they gave the keys in.
+func (w *Wallet) multisigScript(acct *waddrmgr.MultisigAccount, branch, index uint32) ([]byte, er.R) {
+	keys, err := multisigPubKeys(acct, branch, index)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	pubKeys := make([]*btcutil.AddressPubKey, 0, len(keys))
+	for _, k := range keys {
+		pk, err := btcutil.NewAddressPubKey(k, w.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, pk)
+	}
+	return txscript.MultiSigScript(pubKeys, int(acct.Threshold))
+}
+
+// witnessScriptAddress returns the P2WSH address which pays to a script.
+func (w *Wallet) witnessScriptAddress(script []byte) (*btcutil.AddressWitnessScriptHash, er.R) {
+	hash := sha256.Sum256(script)
+	return btcutil.NewAddressWitnessScriptHash(hash[:], w.chainParams)
+}
+
+// multisigAddrCache is the addresses of a multisig account which have already
+// been derived.  The addrs map is never changed once it is stored, a new one is
+// made when more addresses are derived, so it can be read without the lock.
+type multisigAddrCache struct {
+	keys  string
+	count [2]uint32
+	addrs map[string]multisigPath
+}
+
+// multisigKeys identifies the keys of a multisig account so that the cache is
+// not used if an account of the same name is made with different keys.
+func multisigKeys(acct *waddrmgr.MultisigAccount) string {
+	keys := make([]string, 0, len(acct.Keys))
+	for _, key := range acct.Keys {
+		keys = append(keys, key.String())
+	}
+	return strings.Join(keys, ",")
+}
+
+// multisigAddrs returns every address of a multisig account which has been
+// derived, i.e. those which were given out and those within the gap limit.
+// Deriving the addresses is slow so they are cached and only the addresses
+// which were added since the last call are derived.
+func (w *Wallet) multisigAddrs(acct *waddrmgr.MultisigAccount) (map[string]multisigPath, er.R) {
+	var out map[string]multisigPath
+	err := w.multisigCache.In(func(cache *map[string]*multisigAddrCache) er.R {
+		keys := multisigKeys(acct)
+		c := (*cache)[acct.Name]
+		if c == nil || c.keys != keys {
+			c = &multisigAddrCache{keys: keys, addrs: make(map[string]multisigPath)}
+		}
+		want := [2]uint32{
+			acct.NextExternalIndex + acct.GapLimit,
+			acct.NextInternalIndex + acct.GapLimit,
+		}
+		if want[0] <= c.count[0] && want[1] <= c.count[1] {
+			out = c.addrs
+			return nil
+		}
+		addrs := make(map[string]multisigPath, len(c.addrs)+int(want[0]+want[1]))
+		for addr, path := range c.addrs {
+			addrs[addr] = path
+		}
+		count := c.count
+		for branch := range want {
+			for ; count[branch] < want[branch]; count[branch]++ {
+				i := count[branch]
+				script, err := w.multisigScript(acct, uint32(branch), i)
+				if err != nil {
+					return err
+				}
+				addr, err := w.witnessScriptAddress(script)
+				if err != nil {
+					return err
+				}
+				addrs[addr.String()] = multisigPath{branch: uint32(branch), index: i, script: script}
+			}
+		}
+		(*cache)[acct.Name] = &multisigAddrCache{keys: keys, count: count, addrs: addrs}
+		out = addrs
+		return nil
+	})
+	return out, err
+}
+
+// importMultisigAddrs imports the scripts of a multisig account from index
+// from to index to (exclusive) so that payments to them are tracked.
+func (w *Wallet) importMultisigAddrs(addrmgrNs walletdb.ReadWriteBucket,
+	acct *waddrmgr.MultisigAccount, branch, from, to uint32) ([]btcutil.Address, er.R) {
+
+	manager, err := w.Manager.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		return nil, err
+	}
+	bs := w.Manager.SyncedTo()
+	var out []btcutil.Address
+	for i := from; i < to; i++ {
+		script, err := w.multisigScript(acct, branch, i)
+		if err != nil {
+			return nil, err
+		}
+		addr, err := w.witnessScriptAddress(script)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := manager.ImportWitnessScript(addrmgrNs, script, &bs); err != nil &&
+			!waddrmgr.ErrDuplicateAddress.Is(err) {
+			return nil, err
+		}
+		out = append(out, addr)
+	}
+	return out, nil
+}
+
+// extendMultisigGap is called when a script address is paid, if it belongs to
+// a multisig account then the account's next index is moved past it and the
+// scripts are imported so that there are still the account's gap limit of
+// unused addresses past it.  The new addresses are returned so they can be
+// watched.  If the wallet is locked, the scripts can not be imported so they
+// are imported by importMultisigGaps when it is next unlocked.
+func (w *Wallet) extendMultisigGap(addrmgrNs walletdb.ReadWriteBucket,
+	addr btcutil.Address) ([]btcutil.Address, er.R) {
+
+	var acct *waddrmgr.MultisigAccount
+	var path multisigPath
+	if err := w.Manager.ForEachMultisigAccount(addrmgrNs, func(a *waddrmgr.MultisigAccount) er.R {
+		if acct != nil {
+			return nil
+		}
+		paths, err := w.multisigAddrs(a)
+		if err != nil {
+			return err
+		}
+		if p, ok := paths[addr.String()]; ok {
+			acct, path = a, p
+		}
+		return nil
+	}); err != nil || acct == nil {
+		return nil, err
+	}
+
+	next := &acct.NextExternalIndex
+	if path.branch == waddrmgr.InternalBranch {
+		next = &acct.NextInternalIndex
+	}
+	if path.index < *next {
+		return nil, nil
+	}
+	old := *next
+	*next = path.index + 1
+	if err := w.Manager.UpdateMultisigAccount(addrmgrNs, acct); err != nil {
+		return nil, err
+	}
+	if w.Manager.IsLocked() {
+		log.Infof("Address [%s] of multisig account [%s] was used, more addresses "+
+			"will be watched when the wallet is unlocked",
+			log.Address(addr.String()), acct.Name)
+		return nil, nil
+	}
+	addrs, err := w.importMultisigAddrs(addrmgrNs, acct, path.branch,
+		old+acct.GapLimit, *next+acct.GapLimit)
+	if err != nil {
+		return nil, err
+	}
+	w.gapExtended.Add(1)
+	log.Debugf("Address [%s] of multisig account [%s] was used, watching [%s] more addresses",
+		log.Address(addr.String()), acct.Name, log.Int(len(addrs)))
+	return addrs, nil
+}
+
+// importMultisigGaps imports the scripts of multisig addresses which were
+// found to be used while the wallet was locked, see extendMultisigGap.  The
+// scripts are imported in order, so only the last of each branch needs to be
+// checked in the usual case where there is nothing to do.
+func (w *Wallet) importMultisigGaps() er.R {
+	var addrs []btcutil.Address
+	err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		return w.Manager.ForEachMultisigAccount(addrmgrNs, func(acct *waddrmgr.MultisigAccount) er.R {
+			paths, err := w.multisigAddrs(acct)
+			if err != nil {
+				return err
+			}
+			byIndex := make(map[[2]uint32]btcutil.Address, len(paths))
+			for a, p := range paths {
+				addr, err := btcutil.DecodeAddress(a, w.chainParams)
+				if err != nil {
+					return err
+				}
+				byIndex[[2]uint32{p.branch, p.index}] = addr
+			}
+			for branch, next := range []uint32{acct.NextExternalIndex, acct.NextInternalIndex} {
+				to := next + acct.GapLimit
+				from := to
+				for from > 0 {
+					addr := byIndex[[2]uint32{uint32(branch), from - 1}]
+					if _, err := w.Manager.Address(addrmgrNs, addr); err == nil {
+						break
+					} else if !waddrmgr.ErrAddressNotFound.Is(err) {
+						return err
+					}
+					from--
+				}
+				if from == to {
+					continue
+				}
+				a, err := w.importMultisigAddrs(addrmgrNs, acct, uint32(branch), from, to)
+				if err != nil {
+					return err
+				}
+				log.Infof("Multisig account [%s] was used while the wallet was locked, "+
+					"watching [%s] more addresses", acct.Name, log.Int(len(a)))
+				addrs = append(addrs, a...)
+			}
+			return nil
+		})
+	})
+	if err != nil || len(addrs) == 0 {
+		return err
+	}
+	w.watch.WatchAddrs(addrs)
+	w.gapExtended.Add(1)
+	return nil
+}
+
+// CreateMultisigAccount adds an m-of-n multisig account made from the extended
+// public keys of the cosigners.  One or more of the keys may be the account key
+// of one of this wallet's own accounts, in which case this wallet can sign as
+// that cosigner.  gapLimit addresses are derived on both the external and
+// internal branch so that payments to addresses given out by other cosigners
+// are seen, if zero then DefaultGapLimit is used.
+//
+// The wallet must be unlocked because the scripts are stored encrypted.
+func (w *Wallet) CreateMultisigAccount(name string, threshold uint32,
+	keys []*hdkeychain.ExtendedKey, gapLimit uint32) (*waddrmgr.MultisigAccount, er.R) {
+
+	if w.Manager.IsLocked() {
+		return nil, waddrmgr.ErrLocked.New("the wallet must be unlocked to create "+
+			"a multisig account", nil)
+	}
+	if gapLimit == 0 {
+		gapLimit = DefaultGapLimit
+	}
+	acct := &waddrmgr.MultisigAccount{
+		Name:      name,
+		Threshold: threshold,
+		Keys:      keys,
+		GapLimit:  gapLimit,
+	}
+	var addrs []btcutil.Address
+	err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		if err := w.Manager.NewMultisigAccount(addrmgrNs, acct); err != nil {
+			return err
+		}
+		for _, branch := range []uint32{waddrmgr.ExternalBranch, waddrmgr.InternalBranch} {
+			a, err := w.importMultisigAddrs(addrmgrNs, acct, branch, 0, gapLimit)
+			if err != nil {
+				return err
+			}
+			addrs = append(addrs, a...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w.watch.WatchAddrs(addrs)
+	log.Infof("Created [%d] of [%d] multisig account [%s] watching [%s] addresses",
+		threshold, len(keys), name, log.Int(len(addrs)))
+	return acct, nil
+}
+
+// NewMultisigAddress returns the next receiving or change address of a
+// multisig account.  The address is at the same index as it would be in every
+// other cosigner's wallet.
+//
+// The wallet must be unlocked because a new script is imported to keep the
+// gap limit.
+func (w *Wallet) NewMultisigAddress(name string, internal bool) (btcutil.Address, er.R) {
+	if w.Manager.IsLocked() {
+		return nil, waddrmgr.ErrLocked.New("the wallet must be unlocked to make "+
+			"a multisig address", nil)
+	}
+	var addr btcutil.Address
+	var watch []btcutil.Address
+	err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		acct, err := w.Manager.FetchMultisigAccount(addrmgrNs, name)
+		if err != nil {
+			return err
+		}
+		branch := uint32(waddrmgr.ExternalBranch)
+		next := &acct.NextExternalIndex
+		if internal {
+			branch = waddrmgr.InternalBranch
+			next = &acct.NextInternalIndex
+		}
+		index := *next
+		*next++
+
+		// Addresses up to index+gap-1 are already imported, import one more.
+		if watch, err = w.importMultisigAddrs(addrmgrNs, acct, branch,
+			index+acct.GapLimit, *next+acct.GapLimit); err != nil {
+			return err
+		}
+		script, err := w.multisigScript(acct, branch, index)
+		if err != nil {
+			return err
+		}
+		if addr, err = w.witnessScriptAddress(script); err != nil {
+			return err
+		}
+		return w.Manager.UpdateMultisigAccount(addrmgrNs, acct)
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.watch.WatchAddrs(watch)
+	return addr, nil
+}
+
+// MultisigAccountResult is a multisig account of the wallet with its balance
+type MultisigAccountResult struct {
+	waddrmgr.MultisigAccount
+	Balances
+}
+
+// MultisigAccounts returns every multisig account along with its balance.
+func (w *Wallet) MultisigAccounts(confirms int32) ([]*MultisigAccountResult, er.R) {
+	var out []*MultisigAccountResult
+	byAddr := make(map[string]*MultisigAccountResult)
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		if err := w.Manager.ForEachMultisigAccount(addrmgrNs, func(acct *waddrmgr.MultisigAccount) er.R {
+			mr := &MultisigAccountResult{MultisigAccount: *acct}
+			out = append(out, mr)
+			addrs, err := w.multisigAddrs(acct)
+			if err != nil {
+				return err
+			}
+			for addr := range addrs {
+				byAddr[addr] = mr
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		syncBlock := w.Manager.SyncedTo()
+		_, err := w.TxStore.ForEachUnspentOutput(txmgrNs, nil, nil, func(_ []byte, uns *dbstructs.Unspent) er.R {
+			mr := byAddr[uns.Address]
+			if mr == nil {
+				return nil
+			}
+			mr.Total += btcutil.Amount(uns.Value)
+			mr.OutputCount++
+			if confirmed(confirms, uns.Block.Height, syncBlock.Height) {
+				mr.Spendable += btcutil.Amount(uns.Value)
+			} else {
+				mr.Unconfirmed += btcutil.Amount(uns.Value)
+			}
+			return nil
+		})
+		return err
+	})
+	return out, err
+}
+
+// keyFingerprint returns the fingerprint of an extended key, the first 4 bytes
+// of the hash160 of its public key.
+func keyFingerprint(key *hdkeychain.ExtendedKey) (uint32, er.R) {
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(btcutil.Hash160(pubKey.SerializeCompressed())[:4]), nil
+}
+
+// multisigDerivations returns the BIP0032 derivation of each cosigner's key
+// of a multisig address.  The master keys of the cosigners are not known, so
+// the fingerprint is that of the cosigner's account key and the path is
+// relative to it.
+func multisigDerivations(acct *waddrmgr.MultisigAccount,
+	branch, index uint32) ([]*psbt.Bip32Derivation, er.R) {
+
+	pubKeys, err := multisigPubKeys(acct, branch, index)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*psbt.Bip32Derivation, 0, len(pubKeys))
+	for i, key := range acct.Keys {
+		fp, err := keyFingerprint(key)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &psbt.Bip32Derivation{
+			PubKey:               pubKeys[i],
+			MasterKeyFingerprint: fp,
+			Bip32Path:            []uint32{branch, index},
+		})
+	}
+	return out, nil
+}
+
+// fetchMultisigAccount loads a multisig account by name.
+func (w *Wallet) fetchMultisigAccount(name string) (*waddrmgr.MultisigAccount, er.R) {
+	var acct *waddrmgr.MultisigAccount
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		var err er.R
+		acct, err = w.Manager.FetchMultisigAccount(tx.ReadBucket(waddrmgrNamespaceKey), name)
+		return err
+	})
+	return acct, err
+}
+
+// FundMultisigPsbt makes a PSBT which spends from a multisig account to the
+// given outputs, change is paid to a new change address of the account.  Each
+// input carries its witness script and the derivation of each cosigner's key
+// so that the cosigners can sign it, see SignMultisigPsbt and FinalizePsbt.
+//
+// NOTE: Fee estimation treats each input as P2PKH, which is more than the size
+// of a 2-of-3 P2WSH input but less than that of an input with many signatures.
+func (w *Wallet) FundMultisigPsbt(name string, outputs []*wire.TxOut, minconf int32,
+	feeSatPerKB btcutil.Amount, maxInputs int) (*psbt.Packet, er.R) {
+
+	for _, output := range outputs {
+		if err := txrules.CheckOutput(output, txrules.DefaultRelayFeePerKb); err != nil {
+			return nil, err
+		}
+	}
+	change, err := w.NewMultisigAddress(name, true)
+	if err != nil {
+		return nil, err
+	}
+	acct, err := w.fetchMultisigAccount(name)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := w.multisigAddrs(acct)
+	if err != nil {
+		return nil, err
+	}
+	inputAddrs := make([]btcutil.Address, 0, len(paths))
+	for addr := range paths {
+		a, err := btcutil.DecodeAddress(addr, w.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		inputAddrs = append(inputAddrs, a)
+	}
+
+	tx, err := w.CreateSimpleTx(CreateTxReq{
+		InputAddresses: inputAddrs,
+		Outputs:        outputs,
+		Minconf:        minconf,
+		FeeSatPerKB:    feeSatPerKB,
+		SendMode:       SendModeUnsigned,
+		ChangeAddress:  &change,
+		MaxInputs:      maxInputs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	unsigned := tx.Tx.Copy()
+	unsigned.Additional = nil
+	packet, err := psbt.NewFromUnsignedTx(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		prevTx, utxo, _, err := w.FetchInputInfo(&txIn.PreviousOutPoint)
+		if err != nil {
+			return nil, er.Errorf("error fetching UTXO: %v", err)
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(utxo.PkScript, w.chainParams)
+		if err != nil || len(addrs) != 1 {
+			return nil, er.Errorf("unable to decode address of input %d", i)
+		}
+		path, ok := paths[addrs[0].String()]
+		if !ok {
+			return nil, er.Errorf("input %d is not from multisig account [%s]", i, name)
+		}
+		derivations, err := multisigDerivations(acct, path.branch, path.index)
+		if err != nil {
+			return nil, err
+		}
+		packet.Inputs[i].NonWitnessUtxo = prevTx
+		packet.Inputs[i].WitnessUtxo = utxo
+		packet.Inputs[i].SighashType = params.SigHashAll
+		packet.Inputs[i].WitnessScript = path.script
+		packet.Inputs[i].Bip32Derivation = derivations
+	}
+	if tx.ChangeIndex >= 0 {
+		path := paths[change.String()]
+		derivations, err := multisigDerivations(acct, path.branch, path.index)
+		if err != nil {
+			return nil, err
+		}
+		packet.Outputs[tx.ChangeIndex].WitnessScript = path.script
+		packet.Outputs[tx.ChangeIndex].Bip32Derivation = derivations
+	}
+	return packet, nil
+}
+
+// localAccountForKey finds the account of this wallet whose account key is
+// key, if any.
+func (w *Wallet) localAccountForKey(addrmgrNs walletdb.ReadBucket,
+	key *hdkeychain.ExtendedKey) (*waddrmgr.ScopedKeyManager, uint32, bool, er.R) {
+
+	for _, manager := range w.Manager.ActiveScopedKeyManagers() {
+		var account uint32
+		found := false
+		if err := manager.ForEachAccount(addrmgrNs, func(acct uint32) er.R {
+			if found || acct == waddrmgr.ImportedAddrAccount {
+				return nil
+			}
+			props, err := manager.AccountProperties(addrmgrNs, acct)
+			if err != nil {
+				return err
+			}
+			if !props.WatchOnly && props.AccountPubKey != nil &&
+				props.AccountPubKey.String() == key.String() {
+				account = acct
+				found = true
+			}
+			return nil
+		}); err != nil {
+			return nil, 0, false, err
+		}
+		if found {
+			return manager, account, true, nil
+		}
+	}
+	return nil, 0, false, nil
+}
+
+// findMultisigInput finds the multisig account and the place in it of the
+// address which an input of a PSBT spends from.  The derivations of the input
+// give the branch and index and the witness script must be exactly the one
+// which the account makes there.
+func (w *Wallet) findMultisigInput(addrmgrNs walletdb.ReadBucket,
+	in *psbt.PInput) (*waddrmgr.MultisigAccount, uint32, uint32, er.R) {
+
+	var accounts []*waddrmgr.MultisigAccount
+	if err := w.Manager.ForEachMultisigAccount(addrmgrNs, func(a *waddrmgr.MultisigAccount) er.R {
+		accounts = append(accounts, a)
+		return nil
+	}); err != nil {
+		return nil, 0, 0, err
+	}
+	for _, acct := range accounts {
+		for _, d := range in.Bip32Derivation {
+			if len(d.Bip32Path) != 2 {
+				continue
+			}
+			branch, index := d.Bip32Path[0], d.Bip32Path[1]
+			script, err := w.multisigScript(acct, branch, index)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			if bytes.Equal(script, in.WitnessScript) {
+				return acct, branch, index, nil
+			}
+		}
+	}
+	return nil, 0, 0, nil
+}
+
+// signMultisigInput adds this wallet's signatures to an input of a PSBT which
+// spends from one of the wallet's multisig accounts and returns the number of
+// signatures which were added.  Other inputs are skipped.
+func (w *Wallet) signMultisigInput(addrmgrNs walletdb.ReadBucket, packet *psbt.Packet,
+	idx int, sigHashes *txscript.TxSigHashes) (int, er.R) {
+
+	in := &packet.Inputs[idx]
+	if len(in.WitnessScript) == 0 || len(in.FinalScriptWitness) > 0 ||
+		txscript.GetScriptClass(in.WitnessScript) != txscript.MultiSigTy {
+		return 0, nil
+	}
+	utxo := in.WitnessUtxo
+	if utxo == nil && in.NonWitnessUtxo != nil {
+		prevIndex := packet.UnsignedTx.TxIn[idx].PreviousOutPoint.Index
+		if int(prevIndex) < len(in.NonWitnessUtxo.TxOut) {
+			utxo = in.NonWitnessUtxo.TxOut[prevIndex]
+		}
+	}
+	if utxo == nil {
+		return 0, nil
+	}
+
+	acct, branch, index, err := w.findMultisigInput(addrmgrNs, in)
+	if err != nil || acct == nil {
+		return 0, err
+	}
+	hashType := in.SighashType
+	if hashType == 0 {
+		hashType = params.SigHashAll
+	}
+
+	signed := 0
+	for _, key := range acct.Keys {
+		manager, account, ok, err := w.localAccountForKey(addrmgrNs, key)
+		if err != nil {
+			return signed, err
+		} else if !ok {
+			continue
+		}
+		maddr, err := manager.DeriveFromKeyPath(addrmgrNs, waddrmgr.DerivationPath{
+			Account: account,
+			Branch:  branch,
+			Index:   index,
+		})
+		if err != nil {
+			return signed, err
+		}
+		pka := maddr.(waddrmgr.ManagedPubKeyAddress)
+		pubKey := pka.PubKey().SerializeCompressed()
+		alreadySigned := false
+		for _, ps := range in.PartialSigs {
+			if bytes.Equal(ps.PubKey, pubKey) {
+				alreadySigned = true
+			}
+		}
+		if alreadySigned {
+			continue
+		}
+		privKey, err := pka.PrivKey()
+		if err != nil {
+			return signed, err
+		}
+		sig, err := txscript.RawTxInWitnessSignature(packet.UnsignedTx, sigHashes, idx,
+			utxo.Value, in.WitnessScript, hashType, privKey)
+		if err != nil {
+			return signed, err
+		}
+		u := psbt.Updater{Upsbt: packet}
+		if _, err := u.Sign(idx, sig, pubKey, nil, nil); err != nil {
+			return signed, er.Errorf("unable to add signature to input %d: %v", idx, err)
+		}
+		signed++
+	}
+	return signed, nil
+}
+
+// SignMultisigPsbt adds this wallet's signatures to every input of a PSBT which
+// spends from one of the wallet's multisig accounts, where this wallet holds
+// the key of one or more of the cosigners.  The PSBT can then be passed to the
+// next cosigner, the last cosigner uses FinalizePsbt instead so that the
+// transaction is complete.  The number of signatures which were added is
+// returned.
+func (w *Wallet) SignMultisigPsbt(packet *psbt.Packet) (int, er.R) {
+	if err := psbt.VerifyInputOutputLen(packet, true, true); err != nil {
+		return 0, err
+	}
+	signed := 0
+	sigHashes := txscript.NewTxSigHashes(packet.UnsignedTx)
+	err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		for idx := range packet.UnsignedTx.TxIn {
+			n, err := w.signMultisigInput(addrmgrNs, packet, idx, sigHashes)
+			if err != nil {
+				return err
+			}
+			signed += n
+		}
+		return nil
+	})
+	return signed, err
+}
+
+// finalizeMultisigInput signs a multisig input of a PSBT if it is from one of
+// the wallet's multisig accounts, then checks that it has enough signatures to
+// be finalized and drops any past the threshold, because the finalizer needs
+// exactly as many signatures as the script requires.
+func (w *Wallet) finalizeMultisigInput(packet *psbt.Packet, idx int,
+	sigHashes *txscript.TxSigHashes) er.R {
+
+	in := &packet.Inputs[idx]
+	if txscript.GetScriptClass(in.WitnessScript) != txscript.MultiSigTy {
+		return nil
+	}
+	if err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		_, err := w.signMultisigInput(tx.ReadBucket(waddrmgrNamespaceKey), packet, idx, sigHashes)
+		return err
+	}); err != nil {
+		return err
+	}
+	_, required, err := txscript.CalcMultiSigStats(in.WitnessScript)
+	if err != nil {
+		return err
+	}
+	if len(in.PartialSigs) < required {
+		return er.Errorf("input %d has [%d] of the [%d] signatures which it needs, "+
+			"it must be signed by more cosigners", idx, len(in.PartialSigs), required)
+	}
+	in.PartialSigs = in.PartialSigs[:required]
+	return nil
+}
@@ -0,0 +1,189 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/btcutil/psbt"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// accountKey returns the extended public key of the wallet's default account.
+func accountKey(t *testing.T, w *Wallet) *hdkeychain.ExtendedKey {
+	var key *hdkeychain.ExtendedKey
+	if err := walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		manager, err := w.Manager.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+		if err != nil {
+			return err
+		}
+		props, err := manager.AccountProperties(tx.ReadBucket(waddrmgrNamespaceKey), 0)
+		if err != nil {
+			return err
+		}
+		key = props.AccountPubKey
+		return nil
+	}); err != nil {
+		t.Fatalf("unable to get account key: %v", err)
+	}
+	return key
+}
+
+// TestMultisigPsbt makes a 2-of-3 multisig account in two wallets, pays to
+// it, then funds a PSBT in the first wallet, signs it there, and has the second
+// wallet add its signature and finalize it.
+func TestMultisigPsbt(t *testing.T) {
+	w1, cleanup1 := testWallet(t)
+	defer cleanup1()
+	w2, cleanup2 := testWallet(t)
+	defer cleanup2()
+
+	seed, err := hdkeychain.GenerateSeed(hdkeychain.MinSeedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, err := hdkeychain.NewMaster(seed, w1.chainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The cosigners give the keys in a different order, the scripts must
+	// still be the same.
+	k1, k2 := accountKey(t, w1), accountKey(t, w2)
+	if _, err := w1.CreateMultisigAccount("ms", 2,
+		[]*hdkeychain.ExtendedKey{k1, k2, other}, 5); err != nil {
+		t.Fatalf("unable to create multisig account: %v", err)
+	}
+	if _, err := w2.CreateMultisigAccount("ms", 2,
+		[]*hdkeychain.ExtendedKey{other, k2, k1}, 5); err != nil {
+		t.Fatalf("unable to create multisig account: %v", err)
+	}
+
+	addr, err := w1.NewMultisigAddress("ms", false)
+	if err != nil {
+		t.Fatalf("unable to make multisig address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incomingTx := &wire.MsgTx{
+		TxIn:  []*wire.TxIn{{}},
+		TxOut: []*wire.TxOut{wire.NewTxOut(1000000, pkScript)},
+	}
+	addUtxo(t, w1, incomingTx)
+
+	packet, err := w1.FundMultisigPsbt("ms", []*wire.TxOut{
+		wire.NewTxOut(500000, testScriptP2WKH),
+	}, 0, 1000, 0)
+	if err != nil {
+		t.Fatalf("unable to fund multisig psbt: %v", err)
+	}
+	if len(packet.Inputs) != 1 || len(packet.Inputs[0].WitnessScript) == 0 {
+		t.Fatalf("expected one input with a witness script")
+	}
+
+	if n, err := w1.SignMultisigPsbt(packet); err != nil {
+		t.Fatalf("unable to sign: %v", err)
+	} else if n != 1 {
+		t.Fatalf("expected 1 signature, got %d", n)
+	}
+	if n, err := w1.SignMultisigPsbt(packet); err != nil || n != 0 {
+		t.Fatalf("expected no signature to be added twice, got %d %v", n, err)
+	}
+
+	// Pass it to the other cosigner as it would be sent.
+	var buf bytes.Buffer
+	if err := packet.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	packet, err = psbt.NewFromRawBytes(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w2.FinalizePsbt(packet); err != nil {
+		t.Fatalf("unable to finalize: %v", err)
+	}
+	finalTx, err := psbt.Extract(packet)
+	if err != nil {
+		t.Fatalf("unable to extract tx: %v", err)
+	}
+	if err := validateMsgTx(finalTx, [][]byte{pkScript},
+		[]btcutil.Amount{1000000}); err != nil {
+		t.Fatalf("error validating tx: %v", err)
+	}
+}
+
+// TestMultisigGap checks that a payment to an address of a multisig account
+// which was given out by another cosigner moves the account past it.
+func TestMultisigGap(t *testing.T) {
+	w, cleanup := testWallet(t)
+	defer cleanup()
+
+	seed, err := hdkeychain.GenerateSeed(hdkeychain.MinSeedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, err := hdkeychain.NewMaster(seed, w.chainParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	acct, err := w.CreateMultisigAccount("ms", 1,
+		[]*hdkeychain.ExtendedKey{accountKey(t, w), other}, 5)
+	if err != nil {
+		t.Fatalf("unable to create multisig account: %v", err)
+	}
+	script, err := w.multisigScript(acct, waddrmgr.ExternalBranch, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := w.witnessScriptAddress(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var added []btcutil.Address
+	if err := walletdb.Update(w.db, func(tx walletdb.ReadWriteTx) er.R {
+		addrmgrNs := tx.ReadWriteBucket(waddrmgrNamespaceKey)
+		ma, err := w.Manager.Address(addrmgrNs, addr)
+		if err != nil {
+			return err
+		}
+		added, err = w.extendGap(addrmgrNs, ma)
+		if err != nil {
+			return err
+		}
+		acct, err = w.Manager.FetchMultisigAccount(addrmgrNs, "ms")
+		return err
+	}); err != nil {
+		t.Fatalf("unable to extend gap: %v", err)
+	}
+	if acct.NextExternalIndex != 4 {
+		t.Fatalf("expected next index 4, got %d", acct.NextExternalIndex)
+	}
+	if len(added) != 4 {
+		t.Fatalf("expected 4 more addresses, got %d", len(added))
+	}
+	paths, err := w.multisigAddrs(acct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range added {
+		if _, ok := paths[a.String()]; !ok {
+			t.Fatalf("address %s is not in the account", a)
+		}
+	}
+}
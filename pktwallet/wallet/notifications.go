@@ -11,6 +11,7 @@ import (
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
 	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/pktlog/log"
 	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
 	"github.com/pkt-cash/pktd/pktwallet/walletdb"
@@ -38,15 +39,20 @@ type NotificationServer struct {
 	currentTxNtfn *TransactionNotifications // coalesce this since wallet does not add mined txs together
 	mu            sync.Mutex                // Only protects registered client channels
 	wallet        *Wallet                   // smells like hacks
+	events        *eventLog                 // sequenced events for the /wallet/events stream
 }
 
 func newNotificationServer(wallet *Wallet) *NotificationServer {
 	return &NotificationServer{
 		wallet: wallet,
+		events: newEventLog(),
 	}
 }
 
-func lookupInputAccount(dbtx walletdb.ReadTx, w *Wallet, details *wtxmgr.TxDetails, deb wtxmgr.DebitRecord) uint32 {
+// lookupInput finds the address and account which a debit spends from.
+func lookupInput(dbtx walletdb.ReadTx, w *Wallet, details *wtxmgr.TxDetails,
+	deb wtxmgr.DebitRecord) (address string, account uint32) {
+
 	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
 	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
 
@@ -56,23 +62,23 @@ func lookupInputAccount(dbtx walletdb.ReadTx, w *Wallet, details *wtxmgr.TxDetai
 	prev, err := w.TxStore.TxDetails(txmgrNs, &prevOP.Hash)
 	if err != nil {
 		log.Errorf("Cannot query previous transaction details for %v: %v", prevOP.Hash, err)
-		return 0
+		return "", 0
 	}
 	if prev == nil {
 		log.Errorf("Missing previous transaction %v", prevOP.Hash)
-		return 0
+		return "", 0
 	}
 	prevOut := prev.MsgTx.TxOut[prevOP.Index]
 	_, addrs, _, err := txscript.ExtractPkScriptAddrs(prevOut.PkScript, w.chainParams)
-	var inputAcct uint32
 	if err == nil && len(addrs) > 0 {
-		_, inputAcct, err = w.Manager.AddrAccount(addrmgrNs, addrs[0])
+		address = addrs[0].EncodeAddress()
+		_, account, err = w.Manager.AddrAccount(addrmgrNs, addrs[0])
 	}
 	if err != nil {
 		log.Errorf("Cannot fetch account for previous output %v: %v", prevOP, err)
-		inputAcct = 0
+		account = 0
 	}
-	return inputAcct
+	return
 }
 
 func lookupOutputChain(dbtx walletdb.ReadTx, w *Wallet, details *wtxmgr.TxDetails,
@@ -118,9 +124,10 @@ func makeTxSummary(dbtx walletdb.ReadTx, w *Wallet, details *wtxmgr.TxDetails) T
 	if len(details.Debits) != 0 {
 		inputs = make([]TransactionSummaryInput, len(details.Debits))
 		for i, d := range details.Debits {
+			_, acct := lookupInput(dbtx, w, details, d)
 			inputs[i] = TransactionSummaryInput{
 				Index:           d.Index,
-				PreviousAccount: lookupInputAccount(dbtx, w, details, d),
+				PreviousAccount: acct,
 				PreviousAmount:  d.Amount,
 			}
 		}
@@ -171,7 +178,13 @@ func relevantAccounts(w *Wallet, m map[uint32]btcutil.Amount, txs []TransactionS
 	}
 }
 
-func (s *NotificationServer) notifyUnminedTransaction(dbtx walletdb.ReadTx, details *wtxmgr.TxDetails) {
+// emitOnCommit sends an event to the /wallet/events stream once dbtx is
+// committed, so a client never sees an event which is then rolled back.
+func (s *NotificationServer) emitOnCommit(dbtx walletdb.ReadWriteTx, ev *rpc_pb.WalletEvent) {
+	dbtx.OnCommit(func() { s.events.emit(ev) })
+}
+
+func (s *NotificationServer) notifyUnminedTransaction(dbtx walletdb.ReadWriteTx, details *wtxmgr.TxDetails) {
 	// Sanity check: should not be currently coalescing a notification for
 	// mined transactions at the same time that an unmined tx is notified.
 	if s.currentTxNtfn != nil {
@@ -179,6 +192,8 @@ func (s *NotificationServer) notifyUnminedTransaction(dbtx walletdb.ReadTx, deta
 			details.Hash)
 	}
 
+	s.emitOnCommit(dbtx, txEvent(dbtx, s.wallet, details, rpc_pb.WalletEventType_WALLET_TX_MEMPOOL))
+
 	defer s.mu.Unlock()
 	s.mu.Lock()
 	clients := s.transactions
@@ -208,6 +223,25 @@ func (s *NotificationServer) notifyUnminedTransaction(dbtx walletdb.ReadTx, deta
 	}
 }
 
+// notifyRollback must be called before the block at height is rolled back, so
+// that the transactions which are about to be unconfirmed can be reported.
+func (s *NotificationServer) notifyRollback(dbtx walletdb.ReadWriteTx, height int32) {
+	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+	err := s.wallet.TxStore.RangeTransactions(txmgrNs, height, height,
+		func(details []wtxmgr.TxDetails) (bool, er.R) {
+			for i := range details {
+				ev := txEvent(dbtx, s.wallet, &details[i], rpc_pb.WalletEventType_WALLET_TX_UNCONFIRMED)
+				ev.Confirmations = 0
+				s.emitOnCommit(dbtx, ev)
+			}
+			return false, nil
+		})
+	if err != nil {
+		log.Errorf("Cannot fetch transactions in block [%d] which is being rolled back: %v",
+			height, err)
+	}
+}
+
 func (s *NotificationServer) notifyDetachedBlock(hash *chainhash.Hash) {
 	if s.currentTxNtfn == nil {
 		s.currentTxNtfn = &TransactionNotifications{}
@@ -215,7 +249,9 @@ func (s *NotificationServer) notifyDetachedBlock(hash *chainhash.Hash) {
 	s.currentTxNtfn.DetachedBlocks = append(s.currentTxNtfn.DetachedBlocks, hash)
 }
 
-func (s *NotificationServer) notifyMinedTransaction(dbtx walletdb.ReadTx, details *wtxmgr.TxDetails, block *wtxmgr.BlockMeta) {
+func (s *NotificationServer) notifyMinedTransaction(dbtx walletdb.ReadWriteTx, details *wtxmgr.TxDetails, block *wtxmgr.BlockMeta) {
+	s.emitOnCommit(dbtx, txEvent(dbtx, s.wallet, details, rpc_pb.WalletEventType_WALLET_TX_CONFIRMED))
+
 	if s.currentTxNtfn == nil {
 		s.currentTxNtfn = &TransactionNotifications{}
 	}
@@ -233,7 +269,14 @@ func (s *NotificationServer) notifyMinedTransaction(dbtx walletdb.ReadTx, detail
 		append(txs, makeTxSummary(dbtx, s.wallet, details))
 }
 
-func (s *NotificationServer) notifyAttachedBlock(dbtx walletdb.ReadTx, block *wtxmgr.BlockMeta) {
+func (s *NotificationServer) notifyAttachedBlock(dbtx walletdb.ReadWriteTx, block *wtxmgr.BlockMeta) {
+	s.emitOnCommit(dbtx, &rpc_pb.WalletEvent{
+		Type:        rpc_pb.WalletEventType_WALLET_BLOCK,
+		BlockHeight: block.Height,
+		BlockHash:   block.Hash.String(),
+		BlockTime:   block.Time.Unix(),
+	})
+
 	if s.currentTxNtfn == nil {
 		s.currentTxNtfn = &TransactionNotifications{}
 	}
@@ -231,6 +231,10 @@ func (w *Wallet) FundPsbt(packet *psbt.Packet, account uint32,
 // will fail. If no error is returned, the PSBT is ready to be extracted and the
 // final TX within to be broadcast.
 //
+// Inputs which spend from one of the wallet's multisig accounts are signed
+// with each cosigner key which the wallet holds, they must then have enough
+// signatures from the other cosigners to be finalized, see SignMultisigPsbt.
+//
 // NOTE: This method does NOT publish the transaction after it's been finalized
 // successfully.
 func (w *Wallet) FinalizePsbt(packet *psbt.Packet) er.R {
@@ -262,6 +266,15 @@ func (w *Wallet) FinalizePsbt(packet *psbt.Packet) er.R {
 			continue
 		}
 
+		// Inputs which spend from a multisig account get our signatures
+		// added to those of the other cosigners.
+		if len(in.WitnessScript) > 0 {
+			if err := w.finalizeMultisigInput(packet, idx, sigHashes); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// We can only sign this input if it's ours, so we try to map it
 		// to a coin we own. If we can't, then we'll continue as it
 		// isn't our input.
@@ -280,18 +280,23 @@ func fromNums(nums [wordCount]int16) (*SeedEnc, er.R) {
 	}
 	s := SeedEnc{}
 	copy(s.Bytes[:], bytes)
-	var err er.R
+	if err := s.check(); err != nil {
+		s.Zero()
+		return nil, err
+	}
+	return &s, nil
+}
+
+// check verifies the bit pattern, version and checksum of the seed.
+func (s *SeedEnc) check() er.R {
 	if s.getUnused() != expectUnused {
-		err = er.New("Invalid seed: Wrong bit pattern")
+		return er.New("Invalid seed: Wrong bit pattern")
 	} else if s.getVer() != 0 {
-		err = er.Errorf("Invalid seed: Unknown version [%d]", s.getVer())
+		return er.Errorf("Invalid seed: Unknown version [%d]", s.getVer())
 	} else if s.getCsum() != s.computeCsum() {
-		err = er.New("Invalid seed: Checksum mismatch")
-	} else {
-		return &s, nil
+		return er.New("Invalid seed: Checksum mismatch")
 	}
-	s.Zero()
-	return nil, err
+	return nil
 }
 
 // SeedFromWords creates an encrypted seed from a set of words, the language
@@ -0,0 +1,294 @@
+// Copyright (c) 2020 Anode LLC
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package seedwords
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"github.com/dchest/blake2b"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/pktwallet/zero"
+)
+
+/**
+ * Share layout:
+ *     0               1               2               3
+ *     0 1 2 3 4 5 6 7 0 1 2 3 4 5 6 7 0 1 2 3 4 5 6 7 0 1 2 3 4 5 6 7
+ *    +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+ *  0 |              Id               |  K-1  |  X-1  |               |
+ *    +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+               +
+ *  4 |                                                               |
+ *    +                                                               +
+ *    |                         Value (21 bytes)                      |
+ *    +                                                               +
+ * 20 |                                                               |
+ *    +               +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+ * 24 |           Checksum            |
+ *    +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+ *
+ * Id: random number which is the same for every share of one split
+ * K: the number of shares which are needed to recover the seed, 2 to 16
+ * X: the number of this share, 1 to 16
+ * Value: the share of the encrypted seed, each byte of the SeedEnc is split
+ *        separately with Shamir's secret sharing over GF(2^8)
+ * Checksum: first 2 bytes of 32-byte blake2b digest without key of the
+ *           rest of the share
+ *
+ * The 208 bits and a guard bit are written as 19 words, so a share can not be
+ * mistaken for a seed.  Because the encrypted seed is split, the passphrase of
+ * the seed is still needed after the shares are combined.
+ */
+const shareWordCount = 19
+const shareByteLen = 26
+const maxShares = 16
+
+// ShareWordCount is the number of words of a share, a seed has 15.
+const ShareWordCount = shareWordCount
+
+// Share is one of the shares which an encrypted seed is split into by
+// SeedEnc.Split, any Threshold() of them can be combined to recover it.
+type Share struct {
+	Bytes [shareByteLen]byte
+}
+
+func (s *Share) id() uint16 {
+	return binary.BigEndian.Uint16(s.Bytes[0:2])
+}
+
+// Threshold is the number of shares which are needed to recover the seed.
+func (s *Share) Threshold() int {
+	return int(s.Bytes[2]>>4) + 1
+}
+
+// Index is the number of the share, starting from 1.
+func (s *Share) Index() int {
+	return int(s.Bytes[2]&0x0f) + 1
+}
+
+func (s *Share) computeCsum() uint16 {
+	csum := blake2b.Sum256(s.Bytes[:shareByteLen-2])
+	return binary.BigEndian.Uint16(csum[:2])
+}
+
+// Zero wipes the data of this share from memory.
+func (s *Share) Zero() {
+	zero.Bytes(s.Bytes[:])
+}
+
+// GF(2^8) with the polynomial x^8 + x^4 + x^3 + x + 1, as used by AES.
+var gfExp [510]byte
+var gfLog [256]byte
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfLog[x] = byte(i)
+		// multiply by the generator 3
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	for i := 255; i < len(gfExp); i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// Split splits the encrypted seed into count shares, any threshold of which
+// can be combined with CombineShares to recover it.  Fewer than threshold
+// shares reveal nothing about the seed.
+func (s *SeedEnc) Split(threshold, count int) ([]*Share, er.R) {
+	if threshold < 2 || threshold > maxShares {
+		return nil, er.Errorf("threshold must be between 2 and %d", maxShares)
+	} else if count < threshold || count > maxShares {
+		return nil, er.Errorf("number of shares must be between the threshold and %d", maxShares)
+	}
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, er.E(err)
+	}
+	shares := make([]*Share, count)
+	for i := range shares {
+		sh := Share{}
+		copy(sh.Bytes[0:2], id[:])
+		sh.Bytes[2] = byte(threshold-1)<<4 | byte(i)
+		shares[i] = &sh
+	}
+
+	// The unused bits are set the same as they are by SeedFromWords, so the
+	// combined seed passes check().
+	src := *s
+	defer src.Zero()
+	src.Bytes[0] = (src.Bytes[0] & 0x1f) | (expectUnused << 5)
+
+	// The polynomial of each byte is secret + c[0]*x + ... + c[k-2]*x^(k-1)
+	coeffs := make([]byte, threshold-1)
+	defer zero.Bytes(coeffs)
+	for b, secret := range src.Bytes {
+		if _, err := rand.Read(coeffs); err != nil {
+			return nil, er.E(err)
+		}
+		for i, sh := range shares {
+			x := byte(i + 1)
+			y := byte(0)
+			for j := len(coeffs) - 1; j >= 0; j-- {
+				y = gfMul(y, x) ^ coeffs[j]
+			}
+			sh.Bytes[3+b] = gfMul(y, x) ^ secret
+		}
+	}
+	for _, sh := range shares {
+		binary.BigEndian.PutUint16(sh.Bytes[shareByteLen-2:], sh.computeCsum())
+	}
+	return shares, nil
+}
+
+// CombineShares recovers an encrypted seed from shares which were made by
+// SeedEnc.Split.  Shares from different splits, duplicate shares and too few
+// shares are errors.
+func CombineShares(shares []*Share) (*SeedEnc, er.R) {
+	if len(shares) == 0 {
+		return nil, er.New("No shares were given")
+	}
+	first := shares[0]
+	k := first.Threshold()
+	seen := make(map[int]bool, len(shares))
+	for _, sh := range shares {
+		if sh.computeCsum() != binary.BigEndian.Uint16(sh.Bytes[shareByteLen-2:]) {
+			return nil, er.Errorf("Invalid share [%d]: Checksum mismatch", sh.Index())
+		} else if sh.id() != first.id() || sh.Threshold() != k {
+			return nil, er.New("The shares are not all from the same seed")
+		} else if seen[sh.Index()] {
+			return nil, er.Errorf("Share [%d] was given more than once", sh.Index())
+		}
+		seen[sh.Index()] = true
+	}
+	if len(shares) < k {
+		return nil, er.Errorf("[%d] shares are needed to recover the seed but only [%d] were given",
+			k, len(shares))
+	}
+	shares = shares[:k]
+
+	// Lagrange interpolation at x = 0, subtraction is xor.
+	out := SeedEnc{}
+	for i, sh := range shares {
+		xi := byte(sh.Index())
+		basis := byte(1)
+		for j, other := range shares {
+			if i == j {
+				continue
+			}
+			xj := byte(other.Index())
+			basis = gfMul(basis, gfDiv(xj, xj^xi))
+		}
+		for b := range out.Bytes {
+			out.Bytes[b] ^= gfMul(sh.Bytes[3+b], basis)
+		}
+	}
+	if err := out.check(); err != nil {
+		out.Zero()
+		return nil, er.Errorf("The shares did not combine into a valid seed: %s", err.Message())
+	}
+	return &out, nil
+}
+
+// Words converts the share to its representation as a list of words.
+func (s *Share) Words(lang string) (string, er.R) {
+	wd, ok := allWords[lang]
+	if !ok {
+		return "", er.Errorf("Language [%s] is not supported", lang)
+	}
+	words := make([]string, 0, shareWordCount)
+	defer zeroStr(words)
+	var buf [shareByteLen + 1]byte
+	defer zero.Bytes(buf[:])
+	// guard bit
+	buf[0] = 1
+	copy(buf[1:], s.Bytes[:])
+	b := new(big.Int).SetBytes(buf[:])
+	defer zero.BigInt(b)
+	b_ := big.NewInt(0)
+	defer zero.BigInt(b_)
+	b2047 := big.NewInt(2047)
+	for i := 0; i < shareWordCount; i++ {
+		b_.And(b, b2047)
+		words = append(words, wd.words[b_.Uint64()])
+		b.Rsh(b, 11)
+	}
+	if b.Sign() != 0 {
+		panic("Internal error: bignum should have resulted in 0")
+	}
+	return strings.Join(words, " "), nil
+}
+
+// ShareFromWords decodes a share from a set of words, the language is
+// auto-detected.
+func ShareFromWords(words string) (*Share, er.R) {
+	splitWords := strings.Fields(words)
+	defer zeroStr(splitWords)
+	if len(splitWords) != shareWordCount {
+		return nil, er.Errorf("Expected a %d word share", shareWordCount)
+	}
+	nums := [shareWordCount]int16{}
+	defer zeroNums(nums[:])
+	found := false
+LANGUAGE:
+	for _, wd := range allWords {
+		for i, word := range splitWords {
+			num, ok := wd.rwords[word]
+			if !ok {
+				continue LANGUAGE
+			}
+			nums[i] = num
+		}
+		found = true
+		break
+	}
+	if !found {
+		return nil, er.New("Could not decode the words provided, check for typos")
+	}
+
+	b := big.NewInt(0)
+	defer zero.BigInt(b)
+	b_ := big.NewInt(0)
+	defer zero.BigInt(b_)
+	for i := len(nums) - 1; i >= 0; i-- {
+		b_.SetInt64(int64(nums[i]))
+		b.Lsh(b, 11)
+		b.Add(b, b_)
+	}
+	bytes := b.Bytes()
+	defer zero.Bytes(bytes)
+	if len(bytes) != shareByteLen+1 || bytes[0] != 1 {
+		return nil, er.New("Invalid share: Wrong bit pattern")
+	}
+	s := Share{}
+	copy(s.Bytes[:], bytes[1:])
+	if s.computeCsum() != binary.BigEndian.Uint16(s.Bytes[shareByteLen-2:]) {
+		s.Zero()
+		return nil, er.New("Invalid share: Checksum mismatch")
+	}
+	return &s, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2020 Anode LLC
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package seedwords_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcutil/util"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/seedwords"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShares(t *testing.T) {
+	seed, err := seedwords.RandomSeed()
+	util.RequireNoErr(t, err)
+	seedEnc := seed.Encrypt(nil)
+	mnemonic, err := seedEnc.Words("english")
+	util.RequireNoErr(t, err)
+
+	shares, err := seedEnc.Split(3, 5)
+	util.RequireNoErr(t, err)
+	require.Len(t, shares, 5)
+
+	// Every share goes through words and back.
+	for i, sh := range shares {
+		words, err := sh.Words("english")
+		util.RequireNoErr(t, err)
+		require.Len(t, strings.Fields(words), seedwords.ShareWordCount)
+		sh2, err := seedwords.ShareFromWords(words)
+		util.RequireNoErr(t, err)
+		require.Equal(t, sh.Bytes, sh2.Bytes)
+		require.Equal(t, 3, sh2.Threshold())
+		require.Equal(t, i+1, sh2.Index())
+	}
+
+	// Any 3 of the 5 recover the seed.
+	for a := 0; a < 5; a++ {
+		for b := a + 1; b < 5; b++ {
+			for c := b + 1; c < 5; c++ {
+				combined, err := seedwords.CombineShares(
+					[]*seedwords.Share{shares[c], shares[a], shares[b]})
+				util.RequireNoErr(t, err)
+				words, err := combined.Words("english")
+				util.RequireNoErr(t, err)
+				require.Equal(t, mnemonic, words)
+				seed2, err := combined.Decrypt(nil, false)
+				util.RequireNoErr(t, err)
+				require.True(t, bytes.Equal(seed.Bytes(), seed2.Bytes()))
+			}
+		}
+	}
+
+	_, err = seedwords.CombineShares(shares[:2])
+	require.NotNil(t, err, "two shares should not be enough")
+
+	_, err = seedwords.CombineShares([]*seedwords.Share{shares[0], shares[1], shares[1]})
+	require.NotNil(t, err, "a duplicate share should be an error")
+
+	other, err := seedEnc.Split(3, 5)
+	util.RequireNoErr(t, err)
+	_, err = seedwords.CombineShares([]*seedwords.Share{shares[0], shares[1], other[2]})
+	require.NotNil(t, err, "shares of different splits should be an error")
+
+	// A wrong word is caught by the checksum.
+	words, err := shares[0].Words("english")
+	util.RequireNoErr(t, err)
+	w := strings.Fields(words)
+	if w[3] == "abandon" {
+		w[3] = "ability"
+	} else {
+		w[3] = "abandon"
+	}
+	_, err = seedwords.ShareFromWords(strings.Join(w, " "))
+	require.NotNil(t, err, "a wrong word should be an error")
+
+	_, err = seedwords.ShareFromWords(mnemonic)
+	require.NotNil(t, err, "a seed is not a share")
+
+	_, err = seedEnc.Split(1, 3)
+	require.NotNil(t, err)
+	_, err = seedEnc.Split(4, 3)
+	require.NotNil(t, err)
+	_, err = seedEnc.Split(2, 17)
+	require.NotNil(t, err)
+}
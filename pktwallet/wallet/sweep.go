@@ -0,0 +1,457 @@
+package wallet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/btcutil/hdkeychain"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/pktlog/log"
+	"github.com/pkt-cash/pktd/pktwallet/chainiface"
+	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/internal/txsizes"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/seedwords"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txauthor"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+	"github.com/pkt-cash/pktd/wire/constants"
+)
+
+const (
+	// DefaultSweepLookahead is the number of addresses of each branch of a
+	// seed which are searched when sweeping it.
+	DefaultSweepLookahead = 100
+
+	// sweepScanBatch is the number of blocks which are passed to
+	// FilterBlocks at once.
+	sweepScanBatch = 2000
+
+	sweepLabel = "sweep"
+)
+
+// SweepReq is a request to move every coin of some keys which do not belong
+// to the wallet into the wallet.
+type SweepReq struct {
+	// Keys are private keys, e.g. from a paper wallet.  A compressed key
+	// is searched for as p2pkh, p2wpkh and p2sh-p2wpkh, an uncompressed
+	// key only as p2pkh.
+	Keys []*btcutil.WIF
+
+	// Seed is the seed of another wallet, the first Lookahead addresses
+	// of each branch of the default account are searched.
+	Seed *seedwords.Seed
+
+	// Lookahead is the number of addresses of each branch of Seed which
+	// are searched, if zero then DefaultSweepLookahead.
+	Lookahead uint32
+
+	// StartHeight is the block where the search begins.  If zero and there
+	// is a seed then the search begins at the birthday of the seed,
+	// otherwise it begins at the genesis block.
+	StartHeight int32
+
+	// FeeSatPerKB is the fee rate of the sweep transaction, if zero then
+	// the relay fee.
+	FeeSatPerKB btcutil.Amount
+
+	// ToAddress is where the coins are paid, if nil then a new address of
+	// the wallet.
+	ToAddress btcutil.Address
+
+	// NoPublish makes the transaction without broadcasting it.
+	NoPublish bool
+}
+
+// SweptOutput is one of the coins which is spent by a sweep.
+type SweptOutput struct {
+	OutPoint wire.OutPoint
+	Address  string
+	Amount   btcutil.Amount
+}
+
+// SweepResult is the transaction which was made by a sweep.
+type SweepResult struct {
+	Tx        *wire.MsgTx
+	Inputs    []SweptOutput
+	Total     btcutil.Amount
+	Fee       btcutil.Amount
+	ToAddress string
+
+	// Remaining is the number of coins which were found but did not fit in
+	// the transaction, sweeping again after it confirms will take them.
+	Remaining int
+
+	Published bool
+}
+
+type sweepKey struct {
+	priv       *btcec.PrivateKey
+	compressed bool
+}
+
+// sweepKeys are the keys which are being swept, by the address which they
+// pay to, it is the txauthor.SecretsSource of the sweep transaction.
+type sweepKeys struct {
+	keys    map[string]sweepKey
+	scripts map[string]btcutil.Address
+	params  *chaincfg.Params
+}
+
+var _ txauthor.SecretsSource = (*sweepKeys)(nil)
+
+func newSweepKeys(params *chaincfg.Params) *sweepKeys {
+	return &sweepKeys{
+		keys:    make(map[string]sweepKey),
+		scripts: make(map[string]btcutil.Address),
+		params:  params,
+	}
+}
+
+func (s *sweepKeys) add(priv *btcec.PrivateKey, compressed bool, addrType waddrmgr.AddressType) er.R {
+	var pubKey []byte
+	if compressed {
+		pubKey = priv.PubKey().SerializeCompressed()
+	} else {
+		pubKey = priv.PubKey().SerializeUncompressed()
+	}
+	pubKeyHash := btcutil.Hash160(pubKey)
+
+	var addr btcutil.Address
+	var err er.R
+	switch addrType {
+	case waddrmgr.PubKeyHash:
+		addr, err = btcutil.NewAddressPubKeyHash(pubKeyHash, s.params)
+	case waddrmgr.WitnessPubKey:
+		addr, err = btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, s.params)
+	case waddrmgr.NestedWitnessPubKey:
+		var witAddr btcutil.Address
+		witAddr, err = btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, s.params)
+		if err != nil {
+			return err
+		}
+		var witnessProgram []byte
+		if witnessProgram, err = txscript.PayToAddrScript(witAddr); err != nil {
+			return err
+		}
+		addr, err = btcutil.NewAddressScriptHash(witnessProgram, s.params)
+	default:
+		return er.Errorf("unsupported address type [%d]", addrType)
+	}
+	if err != nil {
+		return err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+	s.keys[addr.EncodeAddress()] = sweepKey{priv: priv, compressed: compressed}
+	s.scripts[string(pkScript)] = addr
+	return nil
+}
+
+func (s *sweepKeys) addWIF(wif *btcutil.WIF) er.R {
+	if !wif.IsForNet(s.params) {
+		return er.New("private key is not for this network")
+	}
+	if !wif.CompressPubKey {
+		return s.add(wif.PrivKey, false, waddrmgr.PubKeyHash)
+	}
+	for _, t := range []waddrmgr.AddressType{
+		waddrmgr.PubKeyHash, waddrmgr.WitnessPubKey, waddrmgr.NestedWitnessPubKey,
+	} {
+		if err := s.add(wif.PrivKey, true, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addSeed adds the first lookahead keys of each branch of the default account
+// of each of the default scopes.
+func (s *sweepKeys) addSeed(seed *seedwords.Seed, lookahead uint32) er.R {
+	root, err := hdkeychain.NewMaster(seed.Bytes(), s.params)
+	if err != nil {
+		return err
+	}
+	for _, scope := range waddrmgr.DefaultKeyScopes {
+		schema := waddrmgr.ScopeAddrMap[scope]
+		acctKey, err := waddrmgr.DeriveAccountKey(root, scope, waddrmgr.DefaultAccountNum)
+		if err != nil {
+			return err
+		}
+		for _, branch := range []uint32{waddrmgr.ExternalBranch, waddrmgr.InternalBranch} {
+			addrType := schema.ExternalAddrType
+			if branch == waddrmgr.InternalBranch {
+				addrType = schema.InternalAddrType
+			}
+			branchKey, err := acctKey.DeriveNonStandard(branch)
+			if err != nil {
+				return err
+			}
+			for i := uint32(0); i < lookahead; i++ {
+				child, err := branchKey.DeriveNonStandard(i)
+				if hdkeychain.ErrInvalidChild.Is(err) {
+					continue
+				} else if err != nil {
+					return err
+				}
+				priv, err := child.ECPrivKey()
+				if err != nil {
+					return err
+				}
+				if err := s.add(priv, true, addrType); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *sweepKeys) addresses() []btcutil.Address {
+	out := make([]btcutil.Address, 0, len(s.scripts))
+	for _, addr := range s.scripts {
+		out = append(out, addr)
+	}
+	return out
+}
+
+func (s *sweepKeys) GetKey(addr btcutil.Address) (*btcec.PrivateKey, bool, er.R) {
+	k, ok := s.keys[addr.EncodeAddress()]
+	if !ok {
+		return nil, false, er.Errorf("no key for address [%s]", addr.EncodeAddress())
+	}
+	return k.priv, k.compressed, nil
+}
+
+func (s *sweepKeys) GetScript(addr btcutil.Address) ([]byte, er.R) {
+	return nil, er.Errorf("no script for address [%s]", addr.EncodeAddress())
+}
+
+func (s *sweepKeys) ChainParams() *chaincfg.Params {
+	return s.params
+}
+
+// sweepTx updates the unspent coins of the keys with the spends and payments
+// of a transaction.
+func (s *sweepKeys) sweepTx(tx *wire.MsgTx, unspent map[wire.OutPoint]*wire.TxOut) {
+	for _, in := range tx.TxIn {
+		delete(unspent, in.PreviousOutPoint)
+	}
+	var txid *chainhash.Hash
+	for i, out := range tx.TxOut {
+		if _, ok := s.scripts[string(out.PkScript)]; !ok {
+			continue
+		}
+		if txid == nil {
+			h := tx.TxHash()
+			txid = &h
+		}
+		unspent[wire.OutPoint{Hash: *txid, Index: uint32(i)}] = out
+	}
+}
+
+// scan searches the compact filters of the chain from startHeight to the tip
+// for coins of the keys, it returns the ones which are unspent.
+func (s *sweepKeys) scan(chainClient chainiface.Interface, startHeight int32,
+	quit <-chan struct{}) (map[wire.OutPoint]*wire.TxOut, er.R) {
+
+	bs, err := chainClient.BestBlock()
+	if err != nil {
+		return nil, err
+	}
+	addrs := s.addresses()
+	unspent := make(map[wire.OutPoint]*wire.TxOut)
+	log.Infof("Searching blocks [%d] to [%d] for coins of [%d] addresses to sweep",
+		startHeight, bs.Height, len(addrs))
+
+	for height := startHeight; height <= bs.Height; {
+		blocks := make([]wtxmgr.BlockMeta, 0, sweepScanBatch)
+		for h := height; h <= bs.Height && len(blocks) < sweepScanBatch; h++ {
+			hash, err := chainClient.GetBlockHash(int64(h))
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, wtxmgr.BlockMeta{
+				Block: dbstructs.Block{Hash: *hash, Height: h},
+			})
+		}
+		height += int32(len(blocks))
+
+		// FilterBlocks stops at the first block with relevant transactions,
+		// so the rest of the batch is filtered again from the block after it
+		// because coins which were just found need to be watched.
+		for len(blocks) > 0 {
+			select {
+			case <-quit:
+				return nil, ErrWalletShuttingDown.Default()
+			default:
+			}
+			watched := make(map[wire.OutPoint]btcutil.Address, len(unspent))
+			for op, out := range unspent {
+				watched[op] = s.scripts[string(out.PkScript)]
+			}
+			res, err := chainClient.FilterBlocks(&chainiface.FilterBlocksRequest{
+				Blocks:           blocks,
+				ImportedAddrs:    addrs,
+				WatchedOutPoints: watched,
+			})
+			if err != nil {
+				return nil, err
+			} else if res == nil {
+				break
+			}
+			for _, tx := range res.RelevantTxns {
+				s.sweepTx(tx, unspent)
+			}
+			blocks = blocks[res.BatchIndex+1:]
+			log.Debugf("Sweep found [%d] relevant transactions in block [%d], [%d] unspent coins",
+				len(res.RelevantTxns), res.BlockMeta.Height, len(unspent))
+		}
+	}
+	return unspent, nil
+}
+
+// Sweep finds every unspent coin of the keys or seed in the request using the
+// compact block filters and pays them all to the wallet in one transaction.
+// The keys are not imported and the wallet does not need to be unlocked.
+// Unconfirmed coins are not found.
+func (w *Wallet) Sweep(req SweepReq) (*SweepResult, er.R) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return nil, err
+	}
+	if req.FeeSatPerKB == 0 {
+		req.FeeSatPerKB = txrules.DefaultRelayFeePerKb
+	} else if req.FeeSatPerKB < txrules.DefaultRelayFeePerKb {
+		return nil, er.Errorf("fee rate must be at least the relay fee of %d sat per kB",
+			int64(txrules.DefaultRelayFeePerKb))
+	}
+	if len(req.Keys) == 0 && req.Seed == nil {
+		return nil, er.New("a private key or a seed is required")
+	}
+
+	keys := newSweepKeys(w.chainParams)
+	for _, wif := range req.Keys {
+		if err := keys.addWIF(wif); err != nil {
+			return nil, err
+		}
+	}
+	startHeight := req.StartHeight
+	if req.Seed != nil {
+		lookahead := req.Lookahead
+		if lookahead == 0 {
+			lookahead = DefaultSweepLookahead
+		}
+		if err := keys.addSeed(req.Seed, lookahead); err != nil {
+			return nil, err
+		}
+		if startHeight == 0 {
+			bday, err := locateBirthdayBlock(chainClient, req.Seed.Birthday())
+			if err != nil {
+				return nil, err
+			}
+			startHeight = bday.Height
+		}
+	}
+
+	unspent, err := keys.scan(chainClient, startHeight, w.quitChan())
+	if err != nil {
+		return nil, err
+	}
+	if len(unspent) == 0 {
+		return nil, InsufficientFundsError.New("no unspent coins were found for the "+
+			"keys, if they were received before the start height then try an earlier one", nil)
+	}
+
+	res := SweepResult{}
+	for op, out := range unspent {
+		res.Inputs = append(res.Inputs, SweptOutput{
+			OutPoint: op,
+			Address:  keys.scripts[string(out.PkScript)].EncodeAddress(),
+			Amount:   btcutil.Amount(out.Value),
+		})
+	}
+	// Biggest first so that if there are too many, the dust is left.
+	sort.Slice(res.Inputs, func(i, j int) bool {
+		return res.Inputs[i].Amount > res.Inputs[j].Amount
+	})
+
+	var counts inputCounts
+	for _, in := range res.Inputs {
+		counts.add(unspent[in.OutPoint].PkScript, 1)
+	}
+	maxInputs := MaxInputsPerTx
+	if counts.p2pkh > 0 {
+		maxInputs = MaxInputsPerTxLegacy
+	}
+	if len(res.Inputs) > maxInputs {
+		res.Remaining = len(res.Inputs) - maxInputs
+		res.Inputs = res.Inputs[:maxInputs]
+		log.Warnf("Found [%d] coins to sweep but only [%d] fit in one transaction",
+			res.Remaining+maxInputs, maxInputs)
+	}
+
+	to := req.ToAddress
+	if to == nil {
+		if to, err = w.NewAddress(waddrmgr.DefaultAccountNum, waddrmgr.KeyScopeBIP0084); err != nil {
+			return nil, err
+		}
+	}
+	res.ToAddress = to.EncodeAddress()
+	pkScript, err := txscript.PayToAddrScript(to)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(constants.TxVersion)
+	counts = inputCounts{}
+	for _, in := range res.Inputs {
+		prev := unspent[in.OutPoint]
+		v := prev.Value
+		tx.AddTxIn(wire.NewTxIn(&in.OutPoint, nil, nil))
+		tx.Additional = append(tx.Additional, wire.TxInAdditional{
+			PkScript: prev.PkScript,
+			Value:    &v,
+		})
+		counts.add(prev.PkScript, 1)
+		res.Total += in.Amount
+	}
+	out := wire.NewTxOut(0, pkScript)
+	vsize := txsizes.EstimateVirtualSize(
+		counts.p2pkh, counts.p2wpkh, counts.nested, []*wire.TxOut{out}, false)
+	res.Fee = txrules.FeeForSerializeSize(req.FeeSatPerKB, vsize)
+	out.Value = int64(res.Total - res.Fee)
+	if out.Value <= 0 || txrules.IsDustOutput(out, txrules.DefaultRelayFeePerKb) {
+		return nil, InsufficientFundsError.New(
+			fmt.Sprintf("the [%d] coins found are worth [%s] which is not enough to "+
+				"pay a fee of [%s]", len(res.Inputs), res.Total, res.Fee), nil)
+	}
+	tx.AddTxOut(out)
+	if err := txauthor.AddAllInputScripts(tx, keys); err != nil {
+		return nil, err
+	}
+	if err := validateMsgTx1(tx); err != nil {
+		return nil, err
+	}
+	res.Tx = tx
+
+	if req.NoPublish {
+		return &res, nil
+	}
+	txid, err := w.ReliablyPublishTransaction(tx, sweepLabel)
+	if err != nil {
+		return nil, err
+	}
+	res.Published = true
+	log.Infof("Swept [%s] coins worth [%s] to [%s] in transaction [%s]",
+		log.Int(len(res.Inputs)), res.Total, log.Address(res.ToAddress), log.Txid(txid.String()))
+	return &res, nil
+}
@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkt-cash/pktd/btcec"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/seedwords"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+func TestSweepKeys(t *testing.T) {
+	params := &chaincfg.TestNet3Params
+	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{7}, 32))
+	wif, err := btcutil.NewWIF(priv, params, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := newSweepKeys(params)
+	if err := keys.addWIF(wif); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.scripts) != 3 {
+		t.Fatalf("expected p2pkh, p2wpkh and p2sh-p2wpkh, got %d addresses", len(keys.scripts))
+	}
+	mainnet, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.addWIF(mainnet); err == nil {
+		t.Fatal("expected a key for another network to be an error")
+	}
+
+	var pkScript []byte
+	for s, addr := range keys.scripts {
+		if k, compressed, err := keys.GetKey(addr); err != nil || k != priv || !compressed {
+			t.Fatalf("no key for %s", addr)
+		}
+		if txscript.IsPayToWitnessPubKeyHash([]byte(s)) {
+			pkScript = []byte(s)
+		}
+	}
+
+	// A payment to the key is found and a later spend of it removes it.
+	unspent := make(map[wire.OutPoint]*wire.TxOut)
+	pay := wire.NewMsgTx(1)
+	pay.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	pay.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	pay.AddTxOut(wire.NewTxOut(5000, pkScript))
+	keys.sweepTx(pay, unspent)
+	op := wire.OutPoint{Hash: pay.TxHash(), Index: 1}
+	if len(unspent) != 1 || unspent[op] == nil || unspent[op].Value != 5000 {
+		t.Fatalf("unexpected unspent %v", unspent)
+	}
+	spend := wire.NewMsgTx(1)
+	spend.AddTxIn(wire.NewTxIn(&op, nil, nil))
+	spend.AddTxOut(wire.NewTxOut(4000, []byte{0x51}))
+	keys.sweepTx(spend, unspent)
+	if len(unspent) != 0 {
+		t.Fatalf("expected the coin to be spent, got %v", unspent)
+	}
+}
+
+func TestSweepKeysSeed(t *testing.T) {
+	seed, err := seedwords.RandomSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := newSweepKeys(&chaincfg.TestNet3Params)
+	if err := keys.addSeed(seed, 5); err != nil {
+		t.Fatal(err)
+	}
+	// 3 scopes, 2 branches, 5 addresses each.
+	if len(keys.scripts) != 30 {
+		t.Fatalf("expected 30 addresses, got %d", len(keys.scripts))
+	}
+}
@@ -0,0 +1,260 @@
+// Package txexport turns the transaction history of a wallet into statements
+// which can be imported into accounting software.
+package txexport
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkt-cash/pktd/blockchain"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+// The directions of a Row.
+const (
+	DirectionReceive = "receive"
+	DirectionSend    = "send"
+	DirectionSelf    = "self"
+	DirectionMined   = "mined"
+)
+
+// Row is one transaction in a statement.
+type Row struct {
+	Time      time.Time
+	Txid      string
+	Direction string
+
+	// Amount is the change to the balance, for a send it is negative and it
+	// includes the fee.
+	Amount btcutil.Amount
+
+	// Fee is the fee of the transaction, it is only known if the wallet paid
+	// for every input.
+	Fee      btcutil.Amount
+	FeeKnown bool
+
+	// Address is the wallet's address which received or sent the coins, if
+	// there are several then it is the first one.
+	Address string
+
+	// Counterparty is the address which was paid, or for a receive the
+	// address of the first input if it can be found from the signature.
+	Counterparty string
+
+	Label string
+
+	// Height is the block height, or -1 if the transaction is unconfirmed.
+	Height int32
+
+	// Balance is the running balance after this transaction.
+	Balance btcutil.Amount
+}
+
+// Filter limits which transactions are exported.
+type Filter struct {
+	// Start and End limit the rows to transactions which happened at or
+	// after Start and before End, a zero time is no limit.  The running
+	// balance always counts every transaction from the beginning.
+	Start time.Time
+	End   time.Time
+
+	// Addresses, if not empty, limits the statement to these addresses.
+	// Only coins which are received by or spent from them are counted in
+	// the amount and the running balance.
+	Addresses []string
+
+	// IncludeUnconfirmed adds unconfirmed transactions after the confirmed
+	// ones.
+	IncludeUnconfirmed bool
+}
+
+func (f *Filter) inRange(t time.Time) bool {
+	if !f.Start.IsZero() && t.Before(f.Start) {
+		return false
+	}
+	return f.End.IsZero() || t.Before(f.End)
+}
+
+func outputAddress(pkScript []byte, params *chaincfg.Params) string {
+	return txscript.PkScriptToAddress(pkScript, params).EncodeAddress()
+}
+
+// inputAddress finds the address which an input spends from its signature, it
+// returns "" if it can not be found.
+func inputAddress(in *wire.TxIn, params *chaincfg.Params) string {
+	if len(in.Witness) > 0 {
+		if addr := txscript.WitnessToAddress(in.Witness, params); addr != nil {
+			return addr.EncodeAddress()
+		}
+	}
+	if addr := txscript.SigScriptToAddress(in.SignatureScript, params); addr != nil {
+		return addr.EncodeAddress()
+	}
+	return ""
+}
+
+// makeRow makes the row of a transaction, prevAddress finds the address of an
+// output which the wallet spent.  If addrs is not empty then only credits and
+// debits of those addresses are counted.  The returned bool is false if the
+// transaction does not change the balance of the addresses.
+func makeRow(details *wtxmgr.TxDetails, prevAddress func(op *wire.OutPoint) string,
+	addrs map[string]bool, params *chaincfg.Params) (Row, bool) {
+
+	tx := &details.MsgTx
+	row := Row{
+		Txid:   details.Hash.String(),
+		Label:  details.Label,
+		Height: details.Block.Height,
+		Time:   details.Received,
+	}
+	if details.Block.Height >= 0 {
+		row.Time = details.Block.Time
+	}
+
+	counted := false
+	ours := make(map[uint32]bool, len(details.Credits))
+	for _, c := range details.Credits {
+		ours[c.Index] = true
+		addr := outputAddress(tx.TxOut[c.Index].PkScript, params)
+		if len(addrs) > 0 && !addrs[addr] {
+			continue
+		}
+		counted = true
+		row.Amount += c.Amount
+		if row.Address == "" {
+			row.Address = addr
+		}
+	}
+	var debits btcutil.Amount
+	for _, d := range details.Debits {
+		debits += d.Amount
+		addr := prevAddress(&tx.TxIn[d.Index].PreviousOutPoint)
+		if len(addrs) > 0 && !addrs[addr] {
+			continue
+		}
+		counted = true
+		row.Amount -= d.Amount
+		if row.Address == "" {
+			row.Address = addr
+		}
+	}
+	if !counted {
+		return row, false
+	}
+
+	if len(details.Debits) > 0 && len(details.Debits) == len(tx.TxIn) {
+		row.Fee = debits
+		for _, out := range tx.TxOut {
+			row.Fee -= btcutil.Amount(out.Value)
+		}
+		row.FeeKnown = true
+	}
+
+	for i, out := range tx.TxOut {
+		if !ours[uint32(i)] {
+			row.Counterparty = outputAddress(out.PkScript, params)
+			break
+		}
+	}
+	switch {
+	case blockchain.IsCoinBaseTx(tx):
+		row.Direction = DirectionMined
+	case len(details.Debits) == 0:
+		row.Direction = DirectionReceive
+		row.Counterparty = inputAddress(tx.TxIn[0], params)
+	case row.Counterparty == "":
+		row.Direction = DirectionSelf
+	default:
+		row.Direction = DirectionSend
+	}
+	return row, true
+}
+
+// Cursor is how far RowsFrom has read, so that an export can be read in
+// batches.  The zero Cursor starts at the beginning of the history.
+type Cursor struct {
+	height  int32
+	balance btcutil.Amount
+	done    bool
+}
+
+// Done is true once every transaction has been read.
+func (c *Cursor) Done() bool {
+	return c.done
+}
+
+// Rows calls fn with each transaction in the store which passes the filter,
+// oldest first.
+func Rows(ns walletdb.ReadBucket, store *wtxmgr.Store, params *chaincfg.Params,
+	f *Filter, fn func(row *Row) er.R) er.R {
+
+	var c Cursor
+	return RowsFrom(ns, store, params, f, &c, 0, fn)
+}
+
+// RowsFrom is like Rows except that it starts where c is and stops after the
+// block in which limit transactions have been read, c is then updated so that
+// the next call carries on from the next block.  If limit is 0 then every
+// remaining transaction is read.
+func RowsFrom(ns walletdb.ReadBucket, store *wtxmgr.Store, params *chaincfg.Params,
+	f *Filter, c *Cursor, limit int, fn func(row *Row) er.R) er.R {
+
+	if c.done {
+		return nil
+	}
+	addrs := make(map[string]bool, len(f.Addresses))
+	for _, a := range f.Addresses {
+		addrs[a] = true
+	}
+	prevAddress := func(op *wire.OutPoint) string {
+		prev, err := store.TxDetails(ns, &op.Hash)
+		if err != nil || prev == nil || int(op.Index) >= len(prev.MsgTx.TxOut) {
+			return ""
+		}
+		return outputAddress(prev.MsgTx.TxOut[op.Index].PkScript, params)
+	}
+
+	end := int32(-1)
+	if !f.IncludeUnconfirmed {
+		end = math.MaxInt32
+	}
+	read := 0
+	stopped := false
+	if err := store.RangeTransactions(ns, c.height, end, func(details []wtxmgr.TxDetails) (bool, er.R) {
+		for i := range details {
+			row, ok := makeRow(&details[i], prevAddress, addrs, params)
+			if !ok {
+				continue
+			}
+			c.balance += row.Amount
+			row.Balance = c.balance
+			if !f.inRange(row.Time) {
+				continue
+			}
+			if err := fn(&row); err != nil {
+				return true, err
+			}
+		}
+		// Unmined transactions come last and are all given at once
+		if height := details[0].Block.Height; height < 0 {
+			c.done = true
+		} else {
+			c.height = height + 1
+		}
+		read += len(details)
+		stopped = limit > 0 && read >= limit && !c.done
+		return stopped, nil
+	}); err != nil {
+		return err
+	}
+	if !stopped {
+		c.done = true
+	}
+	return nil
+}
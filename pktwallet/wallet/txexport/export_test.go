@@ -0,0 +1,263 @@
+package txexport
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
+	"github.com/pkt-cash/pktd/chaincfg/chainhash"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+	"github.com/pkt-cash/pktd/pktwallet/walletdb"
+	_ "github.com/pkt-cash/pktd/pktwallet/walletdb/bdb"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
+	"github.com/pkt-cash/pktd/pktwallet/wtxmgr/dbstructs"
+	"github.com/pkt-cash/pktd/txscript"
+	"github.com/pkt-cash/pktd/wire"
+)
+
+func TestMain(m *testing.M) {
+	globalcfg.SelectConfig(globalcfg.BitcoinDefaults())
+	os.Exit(m.Run())
+}
+
+func testAddr(t *testing.T, b byte) (string, []byte) {
+	addr, err := btcutil.NewAddressPubKeyHash(bytes.Repeat([]byte{b}, 20), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr.EncodeAddress(), pkScript
+}
+
+func TestMakeRow(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	ours, oursScript := testAddr(t, 1)
+	change, changeScript := testAddr(t, 2)
+	them, themScript := testAddr(t, 3)
+
+	// Spend 1 BTC from ours, pay 0.3 to them and 0.69 back to change.
+	var details wtxmgr.TxDetails
+	details.Block.Height = 100
+	details.Block.Time = time.Unix(1600000000, 0)
+	details.Label = "rent"
+	details.MsgTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 5}, nil, nil))
+	details.MsgTx.AddTxOut(wire.NewTxOut(30000000, themScript))
+	details.MsgTx.AddTxOut(wire.NewTxOut(69000000, changeScript))
+	details.Debits = []wtxmgr.DebitRecord{{Amount: 100000000, Index: 0}}
+	details.Credits = []wtxmgr.CreditRecord{{Amount: 69000000, Index: 1, Change: true}}
+	prevAddress := func(op *wire.OutPoint) string {
+		if op.Index == 5 {
+			return ours
+		}
+		return ""
+	}
+
+	row, ok := makeRow(&details, prevAddress, nil, params)
+	if !ok {
+		t.Fatal("expected a row")
+	}
+	if row.Direction != DirectionSend || row.Amount != -31000000 || !row.FeeKnown ||
+		row.Fee != 1000000 || row.Counterparty != them || row.Address != change ||
+		row.Label != "rent" || row.Height != 100 {
+		t.Fatalf("unexpected row %+v", row)
+	}
+
+	// Limited to the spending address only the debit is counted.
+	row, ok = makeRow(&details, prevAddress, map[string]bool{ours: true}, params)
+	if !ok || row.Amount != -100000000 || row.Address != ours {
+		t.Fatalf("unexpected row for %s: %+v", ours, row)
+	}
+
+	// And an address which is not involved has no row.
+	if _, ok := makeRow(&details, prevAddress, map[string]bool{them: true}, params); ok {
+		t.Fatal("expected no row for an address which is not the wallet's")
+	}
+
+	// Paying only to the wallet is a transfer to self.
+	details.MsgTx.TxOut[0].PkScript = oursScript
+	details.Credits = append(details.Credits, wtxmgr.CreditRecord{Amount: 30000000, Index: 0})
+	if row, _ := makeRow(&details, prevAddress, nil, params); row.Direction != DirectionSelf ||
+		row.Amount != -1000000 {
+		t.Fatalf("unexpected row %+v", row)
+	}
+}
+
+func TestWriters(t *testing.T) {
+	rows := []Row{{
+		Time:      time.Unix(1600000000, 0),
+		Txid:      "aa",
+		Direction: DirectionReceive,
+		Amount:    150000000,
+		Address:   "addr1",
+		Label:     "salary, march",
+		Height:    10,
+		Balance:   150000000,
+	}, {
+		Time:         time.Unix(1600001000, 0),
+		Txid:         "bb",
+		Direction:    DirectionSend,
+		Amount:       -50000000,
+		Fee:          1000,
+		FeeKnown:     true,
+		Address:      "addr1",
+		Counterparty: "addr2",
+		Label:        "<coffee>",
+		Height:       -1,
+		Balance:      100000000,
+	}}
+	write := func(format string) string {
+		var b bytes.Buffer
+		w, err := NewWriter(format, &b, &Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range rows {
+			if err := w.Write(&rows[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	csv := write(FormatCSV)
+	expected := "date,txid,direction,amount,fee,address,counterparty,label,height,balance\n" +
+		"2020-09-13T12:26:40Z,aa,receive,1.5,,addr1,,\"salary, march\",10,1.5\n" +
+		"2020-09-13T12:43:20Z,bb,send,-0.5,0.00001,addr1,addr2,<coffee>,,1\n"
+	if csv != expected {
+		t.Fatalf("unexpected csv:\n%s", csv)
+	}
+
+	jsonl := strings.Split(strings.TrimSpace(write(FormatJSONL)), "\n")
+	if len(jsonl) != 2 || !strings.Contains(jsonl[1], `"samount":-50000000`) ||
+		!strings.Contains(jsonl[1], `"fee":"0.00001"`) {
+		t.Fatalf("unexpected jsonl:\n%s", strings.Join(jsonl, "\n"))
+	}
+
+	ofx := write(FormatOFX)
+	for _, s := range []string{
+		"<DTSTART>20200913122640</DTSTART>",
+		"<TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20200913124320</DTPOSTED><TRNAMT>-0.5</TRNAMT>",
+		"<MEMO>&lt;coffee&gt;</MEMO>",
+		"<BALAMT>1</BALAMT>",
+	} {
+		if !strings.Contains(ofx, s) {
+			t.Fatalf("expected %q in ofx:\n%s", s, ofx)
+		}
+	}
+
+	if _, err := NewWriter("qif", &bytes.Buffer{}, &Filter{}); err == nil {
+		t.Fatal("expected an unknown format to be an error")
+	}
+}
+
+// TestRowsFrom checks that reading the history in batches gives the same rows
+// and running balance as reading it all at once.
+func TestRowsFrom(t *testing.T) {
+	dir := t.TempDir()
+	db, err := walletdb.Create("bdb", filepath.Join(dir, "db"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	params := &chaincfg.MainNetParams
+	_, oursScript := testAddr(t, 1)
+
+	var store *wtxmgr.Store
+	insert := func(ns walletdb.ReadWriteBucket, i int, block *wtxmgr.BlockMeta) er.R {
+		var tx wire.MsgTx
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{byte(i + 1)}}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(int64(i+1)*100000, oursScript))
+		rec, err := wtxmgr.NewTxRecordFromMsgTx(&tx, time.Unix(1600000000+int64(i), 0))
+		if err != nil {
+			return err
+		}
+		if err := store.InsertTx(ns, rec, block); err != nil {
+			return err
+		}
+		return store.AddCredit(ns, rec, block, 0, false)
+	}
+	// Several blocks, some with more than one transaction, and one unmined.
+	heights := []int32{10, 10, 11, 12, 12, 12, 15}
+	if err := walletdb.Update(db, func(tx walletdb.ReadWriteTx) er.R {
+		ns, err := tx.CreateTopLevelBucket([]byte("txstore"))
+		if err != nil {
+			return err
+		}
+		if err := wtxmgr.Create(ns); err != nil {
+			return err
+		}
+		if store, err = wtxmgr.Open(ns, params); err != nil {
+			return err
+		}
+		for i, h := range heights {
+			block := &wtxmgr.BlockMeta{
+				Block: dbstructs.Block{Hash: chainhash.Hash{byte(h)}, Height: h},
+				Time:  time.Unix(1600000000+int64(h)*600, 0),
+			}
+			if err := insert(ns, i, block); err != nil {
+				return err
+			}
+		}
+		return insert(ns, len(heights), nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &Filter{IncludeUnconfirmed: true}
+	var all []Row
+	if err := walletdb.View(db, func(tx walletdb.ReadTx) er.R {
+		return Rows(tx.ReadBucket([]byte("txstore")), store, params, f, func(row *Row) er.R {
+			all = append(all, *row)
+			return nil
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != len(heights)+1 || all[len(all)-1].Height != -1 {
+		t.Fatalf("expected %d rows ending with the unmined one, got %+v", len(heights)+1, all)
+	}
+
+	for _, limit := range []int{1, 2, 3, 100} {
+		var rows []Row
+		var c Cursor
+		batches := 0
+		for !c.Done() {
+			batches++
+			if err := walletdb.View(db, func(tx walletdb.ReadTx) er.R {
+				return RowsFrom(tx.ReadBucket([]byte("txstore")), store, params, f, &c, limit,
+					func(row *Row) er.R {
+						rows = append(rows, *row)
+						return nil
+					})
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if batches > len(all)+1 {
+				t.Fatalf("limit %d: export did not finish", limit)
+			}
+		}
+		if len(rows) != len(all) {
+			t.Fatalf("limit %d: expected %d rows, got %d", limit, len(all), len(rows))
+		}
+		for i := range all {
+			if rows[i] != all[i] {
+				t.Fatalf("limit %d: row %d is %+v, expected %+v", limit, i, rows[i], all[i])
+			}
+		}
+		if limit == 1 && batches < 5 {
+			t.Fatalf("expected a batch per block with limit 1, got %d batches", batches)
+		}
+	}
+}
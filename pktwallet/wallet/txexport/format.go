@@ -0,0 +1,260 @@
+package txexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkt-cash/pktd/btcutil"
+	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg/globalcfg"
+)
+
+// The formats which a statement can be written in.
+const (
+	FormatCSV   = "csv"
+	FormatOFX   = "ofx"
+	FormatJSONL = "jsonl"
+)
+
+// Writer writes the rows of a statement in one of the formats.
+type Writer interface {
+	Write(row *Row) er.R
+
+	// Close writes the end of the statement, it does not close the
+	// underlying io.Writer.
+	Close() er.R
+}
+
+// NewWriter makes a Writer for format, the filter is used for the period of
+// the statement.
+func NewWriter(format string, w io.Writer, f *Filter) (Writer, er.R) {
+	switch format {
+	case FormatCSV, "":
+		return newCsvWriter(w), nil
+	case FormatOFX:
+		return &ofxWriter{w: w, start: f.Start, end: f.End}, nil
+	case FormatJSONL:
+		return &jsonlWriter{w: w}, nil
+	default:
+		return nil, er.Errorf("unknown export format [%s], expected one of %s, %s or %s",
+			format, FormatCSV, FormatOFX, FormatJSONL)
+	}
+}
+
+// formatAmount formats an amount in coins, without a unit.
+func formatAmount(a btcutil.Amount) string {
+	return strconv.FormatFloat(a.ToBTC(), 'f', -1, 64)
+}
+
+func formatHeight(h int32) string {
+	if h < 0 {
+		return ""
+	}
+	return strconv.Itoa(int(h))
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+var csvHeader = []string{
+	"date", "txid", "direction", "amount", "fee", "address", "counterparty",
+	"label", "height", "balance",
+}
+
+func newCsvWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) header() er.R {
+	if c.wroteHeader {
+		return nil
+	}
+	c.wroteHeader = true
+	return er.E(c.w.Write(csvHeader))
+}
+
+func (c *csvWriter) Write(row *Row) er.R {
+	if err := c.header(); err != nil {
+		return err
+	}
+	fee := ""
+	if row.FeeKnown {
+		fee = formatAmount(row.Fee)
+	}
+	return er.E(c.w.Write([]string{
+		row.Time.UTC().Format(time.RFC3339),
+		row.Txid,
+		row.Direction,
+		formatAmount(row.Amount),
+		fee,
+		row.Address,
+		row.Counterparty,
+		row.Label,
+		formatHeight(row.Height),
+		formatAmount(row.Balance),
+	}))
+}
+
+func (c *csvWriter) Close() er.R {
+	if err := c.header(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return er.E(c.w.Error())
+}
+
+type jsonlRow struct {
+	Date         string `json:"date"`
+	Txid         string `json:"txid"`
+	Direction    string `json:"direction"`
+	Amount       string `json:"amount"`
+	Samount      int64  `json:"samount"`
+	Fee          string `json:"fee,omitempty"`
+	Sfee         int64  `json:"sfee,omitempty"`
+	Address      string `json:"address"`
+	Counterparty string `json:"counterparty,omitempty"`
+	Label        string `json:"label,omitempty"`
+	Height       int32  `json:"height"`
+	Balance      string `json:"balance"`
+	Sbalance     int64  `json:"sbalance"`
+}
+
+type jsonlWriter struct {
+	w io.Writer
+}
+
+func (j *jsonlWriter) Write(row *Row) er.R {
+	r := jsonlRow{
+		Date:         row.Time.UTC().Format(time.RFC3339),
+		Txid:         row.Txid,
+		Direction:    row.Direction,
+		Amount:       formatAmount(row.Amount),
+		Samount:      int64(row.Amount),
+		Address:      row.Address,
+		Counterparty: row.Counterparty,
+		Label:        row.Label,
+		Height:       row.Height,
+		Balance:      formatAmount(row.Balance),
+		Sbalance:     int64(row.Balance),
+	}
+	if row.FeeKnown {
+		r.Fee = formatAmount(row.Fee)
+		r.Sfee = int64(row.Fee)
+	}
+	b, err := jsoniter.Marshal(&r)
+	if err != nil {
+		return er.E(err)
+	}
+	_, err = j.w.Write(append(b, '\n'))
+	return er.E(err)
+}
+
+func (j *jsonlWriter) Close() er.R {
+	return nil
+}
+
+// ofxWriter writes an OFX 2 bank statement, most accounting software can
+// import these.  The currency is the coin, which is not an ISO 4217 code so
+// some software may ask which currency to use.
+type ofxWriter struct {
+	w       io.Writer
+	start   time.Time
+	end     time.Time
+	started bool
+	balance btcutil.Amount
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405")
+}
+
+func ofxEscape(s string) string {
+	var b bytes.Buffer
+	if err := xml.EscapeText(&b, []byte(s)); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+func (o *ofxWriter) begin(first time.Time) er.R {
+	if o.started {
+		return nil
+	}
+	o.started = true
+	start := o.start
+	if start.IsZero() {
+		start = first
+	}
+	_, err := fmt.Fprintf(o.w, `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>0</TRNUID>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS>
+<CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>0</BANKID><ACCTID>wallet</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>%s</DTSTART>
+<DTEND>%s</DTEND>
+`, ofxEscape(globalcfg.AmountUnits()[0].Name), ofxTime(start), ofxTime(o.periodEnd()))
+	return er.E(err)
+}
+
+func (o *ofxWriter) periodEnd() time.Time {
+	if o.end.IsZero() {
+		return time.Now()
+	}
+	return o.end
+}
+
+func (o *ofxWriter) Write(row *Row) er.R {
+	if err := o.begin(row.Time); err != nil {
+		return err
+	}
+	trnType := "CREDIT"
+	if row.Amount < 0 {
+		trnType = "DEBIT"
+	}
+	name := row.Counterparty
+	if name == "" {
+		name = row.Direction
+	}
+	// NAME is limited to 32 characters.
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	memo := row.Label
+	if memo == "" {
+		memo = row.Direction
+	}
+	_, err := fmt.Fprintf(o.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED>"+
+		"<TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		trnType, ofxTime(row.Time), formatAmount(row.Amount), row.Txid,
+		ofxEscape(name), ofxEscape(memo))
+	o.balance = row.Balance
+	return er.E(err)
+}
+
+func (o *ofxWriter) Close() er.R {
+	if err := o.begin(o.periodEnd()); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(o.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`, formatAmount(o.balance), ofxTime(o.periodEnd()))
+	return er.E(err)
+}
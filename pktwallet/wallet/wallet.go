@@ -20,6 +20,7 @@ import (
 	"github.com/pkt-cash/pktd/btcutil/event"
 	"github.com/pkt-cash/pktd/btcutil/lock"
 	"github.com/pkt-cash/pktd/btcutil/util"
+	"github.com/pkt-cash/pktd/generated/proto/restrpc_pb/help_pb"
 	"github.com/pkt-cash/pktd/generated/proto/rpc_pb"
 	"github.com/pkt-cash/pktd/lnd/describetxn"
 	"github.com/pkt-cash/pktd/lnd/lnrpc/apiv1"
@@ -40,6 +41,7 @@ import (
 	"github.com/pkt-cash/pktd/pktwallet/waddrmgr"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/seedwords"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/txauthor"
+	"github.com/pkt-cash/pktd/pktwallet/wallet/txexport"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/txrules"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/watcher"
 	"github.com/pkt-cash/pktd/pktwallet/wallet/workqueue"
@@ -112,6 +114,7 @@ type Wallet struct {
 
 	chainClient        chainiface.Interface
 	chainClientLock    sync.Mutex
+	chainClientShared  bool // used by other wallets too, so never stopped
 	chainClientSynced  bool
 	chainClientSyncMtx sync.Mutex
 
@@ -149,10 +152,19 @@ type Wallet struct {
 	rescanJLock sync.Mutex
 	rescanJ     *rescanJob
 
+	// Incremented each time more addresses of a watch-only or multisig
+	// account are derived, so that a resync can scan again with them.
+	gapExtended lock.AtomicInt32
+
 	looseTransactions       lock.GenMutex[[]wire.MsgTx]
 	looseTransactionsStop   event.Emitter[struct{}]
 	looseTransactionsActive lock.AtomicBool
 
+	consolidation lock.GenMutex[consolidationState]
+
+	multisigCache   lock.GenMutex[map[string]*multisigAddrCache]
+	unsignableCache lock.GenMutex[unsignableAddrCache]
+
 	api *apiv1.Apiv1
 }
 
@@ -198,9 +210,10 @@ func (w *Wallet) Start() {
 	}
 	w.quitMu.Unlock()
 
-	w.wg.Add(2)
+	w.wg.Add(3)
 	go w.txCreator()
 	go w.walletLocker()
+	go w.consolidator()
 }
 
 // SynchronizeRPC associates the wallet with the consensus RPC client,
@@ -210,6 +223,16 @@ func (w *Wallet) Start() {
 // This method is unstable and will be removed when all syncing logic is moved
 // outside of the wallet package.
 func (w *Wallet) SynchronizeRPC(chainClient *neutrino.ChainService) {
+	w.synchronizeRPC(chainClient, false)
+}
+
+// SynchronizeSharedRPC is like SynchronizeRPC but the ChainService is also used
+// by other wallets, so it is only detached from this wallet when it stops.
+func (w *Wallet) SynchronizeSharedRPC(chainClient *neutrino.ChainService) {
+	w.synchronizeRPC(chainClient, true)
+}
+
+func (w *Wallet) synchronizeRPC(chainClient *neutrino.ChainService, shared bool) {
 	w.quitMu.Lock()
 	select {
 	case <-w.quit:
@@ -227,6 +250,7 @@ func (w *Wallet) SynchronizeRPC(chainClient *neutrino.ChainService) {
 		return
 	}
 	w.chainClient = chainClient
+	w.chainClientShared = shared
 
 	w.chainClientLock.Unlock()
 
@@ -283,7 +307,9 @@ func (w *Wallet) Stop() {
 		close(quit)
 		w.chainClientLock.Lock()
 		if w.chainClient != nil {
-			w.chainClient.Stop()
+			if !w.chainClientShared {
+				w.chainClient.Stop()
+			}
 			w.chainClient = nil
 		}
 		w.chainClientLock.Unlock()
@@ -660,6 +686,7 @@ type (
 		InputComparator utils.Comparator
 		MaxInputs       int
 		Label           string
+		CoinSelection   CoinSelection
 	}
 	createTxRequest struct {
 		req  CreateTxReq
@@ -675,6 +702,10 @@ const (
 	SendModeUnsigned SendMode = 0
 	SendModeSigned   SendMode = 1
 	SendModeBcasted  SendMode = 2
+
+	// SendModeSimulate selects the coins and makes the unsigned transaction
+	// but nothing is written to the database, see SimulateTx.
+	SendModeSimulate SendMode = 3
 )
 
 // txCreator is responsible for the input selection and creation of
@@ -778,6 +809,9 @@ out:
 				continue
 			}
 			timeout = req.lockAfter
+			if err := w.importMultisigGaps(); err != nil {
+				log.Warnf("Unable to import multisig addresses: %s", err.String())
+			}
 			if timeout == nil {
 				log.Info("The wallet has been unlocked without a time limit")
 			} else {
@@ -1614,6 +1648,27 @@ func (w *Wallet) ListAllTransactions() ([]btcjson.ListTransactionsResult, er.R)
 	return txList, err
 }
 
+// ExportTransactions calls fn with each transaction of the wallet which passes
+// the filter, oldest first, with the running balance.
+func (w *Wallet) ExportTransactions(f *txexport.Filter, fn func(row *txexport.Row) er.R) er.R {
+	return walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		return txexport.Rows(txmgrNs, w.TxStore, w.chainParams, f, fn)
+	})
+}
+
+// ExportTransactionsFrom is like ExportTransactions except that it carries on
+// from c and stops after about limit transactions, so that a long history can
+// be read in batches without holding the database open between them.
+func (w *Wallet) ExportTransactionsFrom(f *txexport.Filter, c *txexport.Cursor,
+	limit int, fn func(row *txexport.Row) er.R) er.R {
+
+	return walletdb.View(w.db, func(tx walletdb.ReadTx) er.R {
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		return txexport.RowsFrom(txmgrNs, w.TxStore, w.chainParams, f, c, limit, fn)
+	})
+}
+
 // BlockIdentifier identifies a block by either a height or a hash.
 type BlockIdentifier struct {
 	height int32
@@ -1649,6 +1704,23 @@ func (w *Wallet) describeTxn(txn []byte, vinDetail bool) (*rpc_pb.TransactionInf
 	return describetxn.Describe(getTxns, mtx, w.chainParams, vinDetail)
 }
 
+// labelTxInfo fills in the address book labels of the payers and outputs of a
+// described transaction.
+func labelTxInfo(tx *rpc_pb.TransactionInfo, addrLabels map[string]string,
+	opLabels map[wire.OutPoint]string) {
+
+	for _, p := range tx.Payers {
+		p.AddressLabel = addrLabels[p.Address]
+	}
+	txid, err := chainhash.NewHashFromStr(tx.Txid)
+	for _, out := range tx.Vout {
+		out.AddressLabel = addrLabels[out.Address]
+		if err == nil {
+			out.Label = opLabels[wire.OutPoint{Hash: *txid, Index: out.N}]
+		}
+	}
+}
+
 func (w *Wallet) GetTransactions1(req *rpc_pb.GetTransactionsRequest) (*rpc_pb.TransactionDetails, er.R) {
 	start := NewBlockIdentifierFromHeight(req.StartHeight)
 	stop := NewBlockIdentifierFromHeight(req.EndHeight)
@@ -1661,6 +1733,28 @@ func (w *Wallet) GetTransactions1(req *rpc_pb.GetTransactionsRequest) (*rpc_pb.T
 		Transactions: make([]*rpc_pb.ContextualTransaction, 0,
 			len(txns.MinedTransactions)+len(txns.UnminedTransactions)),
 	}
+	addrLabels, err := w.AddressLabels()
+	if err != nil {
+		return nil, err
+	}
+	opLabels, err := w.OutpointLabels()
+	if err != nil {
+		return nil, err
+	}
+	var bumps map[string]*FeeBump
+	if err := walletdb.View(w.db, func(dbtx walletdb.ReadTx) er.R {
+		var err er.R
+		bumps, err = feeBumps(dbtx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	feeBump := func(txid string) *rpc_pb.FeeBump {
+		if b := bumps[txid]; b != nil {
+			return b.Rpc()
+		}
+		return nil
+	}
 	bs := w.Manager.SyncedTo()
 	for _, blk := range txns.MinedTransactions {
 		blkHash := blk.Hash.String()
@@ -1669,6 +1763,7 @@ func (w *Wallet) GetTransactions1(req *rpc_pb.GetTransactionsRequest) (*rpc_pb.T
 			if err != nil {
 				return nil, err
 			}
+			labelTxInfo(tx, addrLabels, opLabels)
 			txDetails.Transactions = append(txDetails.Transactions, &rpc_pb.ContextualTransaction{
 				Tx:               tx,
 				TxBin:            util.If(req.TxBin, txn.Transaction, nil),
@@ -1676,6 +1771,8 @@ func (w *Wallet) GetTransactions1(req *rpc_pb.GetTransactionsRequest) (*rpc_pb.T
 				BlockHash:        blkHash,
 				BlockHeight:      blk.Height,
 				Time:             blk.Timestamp,
+				Label:            txn.Label,
+				FeeBump:          feeBump(tx.Txid),
 			})
 		}
 	}
@@ -1684,6 +1781,7 @@ func (w *Wallet) GetTransactions1(req *rpc_pb.GetTransactionsRequest) (*rpc_pb.T
 		if err != nil {
 			return nil, err
 		}
+		labelTxInfo(tx, addrLabels, opLabels)
 		txDetails.Transactions = append(txDetails.Transactions, &rpc_pb.ContextualTransaction{
 			Tx:               tx,
 			TxBin:            util.If(req.TxBin, txn.Transaction, nil),
@@ -1691,6 +1789,8 @@ func (w *Wallet) GetTransactions1(req *rpc_pb.GetTransactionsRequest) (*rpc_pb.T
 			BlockHash:        "",
 			BlockHeight:      0,
 			Time:             txn.Timestamp,
+			Label:            txn.Label,
+			FeeBump:          feeBump(tx.Txid),
 		})
 	}
 
@@ -1713,7 +1813,16 @@ func (w *Wallet) GetTransactions1(req *rpc_pb.GetTransactionsRequest) (*rpc_pb.T
 
 // GetWalletSeed
 func (w *Wallet) registerRpc() {
-	walletLoosetxns := apiv1.DefineCategory(w.api.Category("wallet"), "loosetxns",
+	if w.api == nil {
+		return
+	}
+	w.RegisterRpc(w.api.Category("wallet"))
+}
+
+// RegisterRpc registers the endpoints which belong to the wallet itself under
+// walletCat, this is used for wallets which are loaded after startup.
+func (w *Wallet) RegisterRpc(walletCat *apiv1.Apiv1) {
+	walletLoosetxns := apiv1.DefineCategory(walletCat, "loosetxns",
 		`
 		Loose transactions which have not yet been logged in the blockchain
 
@@ -1732,7 +1841,7 @@ func (w *Wallet) registerRpc() {
 		func(_ *rpc_pb.Null) (*rpc_pb.Null, er.R) {
 			w.WatchLooseTransactions()
 			return nil, nil
-		})
+		}, help_pb.F_PERM_WRITE)
 	apiv1.Endpoint(walletLoosetxns,
 		"stopwatch",
 		`
@@ -1741,7 +1850,7 @@ func (w *Wallet) registerRpc() {
 		func(_ *rpc_pb.Null) (*rpc_pb.Null, er.R) {
 			w.StopWatchLooseTransactions()
 			return nil, nil
-		})
+		}, help_pb.F_PERM_WRITE)
 	apiv1.Endpoint(walletLoosetxns,
 		"",
 		`
@@ -1750,6 +1859,7 @@ func (w *Wallet) registerRpc() {
 		func(_ *rpc_pb.Null) (*rpc_pb.LooseTxnRes, er.R) {
 			return &rpc_pb.LooseTxnRes{IsWatching: w.WatchingLooseTransactions()}, nil
 		},
+		help_pb.F_PERM_READ,
 	)
 }
 
@@ -3079,6 +3189,7 @@ func (w *Wallet) connectBlocks(blks []SyncerResp, isRescan bool) er.R {
 				txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
 				log.Infof("Invalid block detected at [%d] replacing [%s] -> [%s]",
 					b.height, b.rollbackHash, b.header.BlockHash())
+				w.NtfnServer.notifyRollback(dbtx, b.height)
 				if err := w.TxStore.RollbackOne(txmgrNs, b.height); err != nil {
 					return err
 				}
@@ -3345,11 +3456,19 @@ func (w *Wallet) rescan() bool {
 		if limit < top {
 			top = limit
 		}
+		gapExtended := w.gapExtended.Load()
 		if err := w.rescan2(rj.height, top, true); err != nil {
 			log.Warnf("Error while running resync [%s] resync stopped", err.String())
 			w.rescanEnded()
 			return false
 		}
+		if w.gapExtended.Load() != gapExtended {
+			// A watch-only account was paid and more of its addresses
+			// are now watched, blocks in this range may have been
+			// filtered before, so scan it again.
+			log.Debugf("Watch-only addresses were added, rescanning from [%d]", rj.height)
+			continue
+		}
 		rj.height = top
 		w.UpdateStats(func(ws *btcjson.WalletStats) {
 			ws.MaintenanceCycles++
@@ -3575,10 +3694,17 @@ func Open(
 		looseTransactions:       lock.NewGenMutex[[]wire.MsgTx](nil, "looseTransactions"),
 		looseTransactionsStop:   event.NewEmitter[struct{}]("looseTransactionsStop"),
 		looseTransactionsActive: lock.AtomicBool{},
+		consolidation:           lock.NewGenMutex(consolidationState{}, "consolidation"),
+		multisigCache:           lock.NewGenMutex(make(map[string]*multisigAddrCache), "multisigCache"),
+		unsignableCache:         lock.NewGenMutex(unsignableAddrCache{}, "unsignableCache"),
 		api:                     api,
 	}
 
 	w.NtfnServer = newNotificationServer(w)
 
+	if err := w.loadRestoredLocks(); err != nil {
+		log.Warnf("Unable to lock the outpoints of a restored backup: %s", err.String())
+	}
+
 	return w, nil
 }
@@ -2,12 +2,16 @@ package wallet
 
 import (
 	"encoding/hex"
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/pkt-cash/pktd/btcutil"
 	"github.com/pkt-cash/pktd/btcutil/er"
+	"github.com/pkt-cash/pktd/chaincfg"
 	"github.com/pkt-cash/pktd/chaincfg/genesis"
+	"github.com/pkt-cash/pktd/pktwallet/chainiface"
 	"github.com/pkt-cash/pktd/pktwallet/walletdb"
 	"github.com/pkt-cash/pktd/pktwallet/wtxmgr"
 )
@@ -203,3 +207,50 @@ func TestLabelTransaction(t *testing.T) {
 		})
 	}
 }
+
+// stopCountingClient is a chain client which records how often it is stopped.
+type stopCountingClient struct {
+	chainiface.Mock
+	stops int
+}
+
+func (c *stopCountingClient) Stop() {
+	c.stops++
+}
+
+// TestUnloadSharedChainClient checks that unloading a wallet leaves a chain
+// client which is shared with other wallets running, but stops one which the
+// wallet owns.
+func TestUnloadSharedChainClient(t *testing.T) {
+	for _, shared := range []bool{true, false} {
+		dir, errr := ioutil.TempDir("", "test_unload")
+		if errr != nil {
+			t.Fatalf("Failed to create db dir: %v", errr)
+		}
+		defer os.RemoveAll(dir)
+
+		loader := NewLoader(&chaincfg.TestNet3Params, dir, "wallet.db", true, 250)
+		w, err := loader.CreateNewWallet([]byte("hello"), []byte("world"),
+			[]byte(hex.EncodeToString(make([]byte, 32))), time.Now(), nil, nil)
+		if err != nil {
+			t.Fatalf("unable to create wallet: %v", err)
+		}
+		cs := &stopCountingClient{}
+		w.chainClientLock.Lock()
+		w.chainClient = cs
+		w.chainClientShared = shared
+		w.chainClientLock.Unlock()
+
+		if err := loader.UnloadWallet(); err != nil {
+			t.Fatalf("unable to unload wallet: %v", err)
+		}
+		if w.ChainClient() != nil {
+			t.Fatalf("shared=%v: chain client still attached after unload", shared)
+		}
+		if shared && cs.stops != 0 {
+			t.Fatalf("shared chain client was stopped when the wallet was unloaded")
+		} else if !shared && cs.stops != 1 {
+			t.Fatalf("owned chain client was stopped %d times, want 1", cs.stops)
+		}
+	}
+}
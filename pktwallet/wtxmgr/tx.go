@@ -1027,6 +1027,27 @@ func FetchTxLabel(ns walletdb.ReadBucket, txid chainhash.Hash) (string, er.R) {
 	return DeserializeLabel(v)
 }
 
+// ForEachTxLabel calls fn with every transaction label in the store.
+func (s *Store) ForEachTxLabel(ns walletdb.ReadBucket,
+	fn func(txid chainhash.Hash, label string) er.R) er.R {
+
+	labelBucket := ns.NestedReadBucket(bucketTxLabels)
+	if labelBucket == nil {
+		return nil
+	}
+	return labelBucket.ForEach(func(k, v []byte) er.R {
+		txid, err := chainhash.NewHash(k)
+		if err != nil {
+			return err
+		}
+		label, err := DeserializeLabel(v)
+		if err != nil {
+			return err
+		}
+		return fn(*txid, label)
+	})
+}
+
 // DeserializeLabel reads a deserializes a length-value encoded label from the
 // byte array provided.
 func DeserializeLabel(v []byte) (string, er.R) {